@@ -0,0 +1,190 @@
+package accountrouter
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/namespace/reqscope"
+)
+
+type apiKeyResponse struct {
+	Id         int        `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// Only set when the key is created or rotated
+	Key        string     `json:"key,omitempty"`
+}
+
+func newAPIKeyResponse(v account.APIKey, key string) apiKeyResponse {
+	return apiKeyResponse{
+		Id:         v.Id,
+		Name:       v.Name,
+		Scopes:     v.Scopes,
+		CreatedAt:  v.CreatedAt,
+		LastUsedAt: v.LastUsedAt,
+		Key:        key,
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(header, "Bearer "), true
+}
+
+// Reject the request without a valid API key of the scope, the business id is stored in request context
+func (rt *Router) apiKeyAuthenticated(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok || rt.apiKeys == nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			key, err := rt.apiKeys.Authenticate(r.Context(), token)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			// deleted businesses and deactivated children cannot use their keys
+			err = rt.business.CanLogin(r.Context(), key.BusinessId)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			if !key.HasScope(scope) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(reqscope.SetAccountId(r.Context(), key.BusinessId)))
+		})
+	}
+}
+
+// Accept an API key of the scope if the request has a bearer token, otherwise the business session
+func (rt *Router) businessOrKeyAuthenticated(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withKey := rt.apiKeyAuthenticated(scope)(next)
+		withSession := rt.businessAuthenticated(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := bearerToken(r); ok {
+				withKey.ServeHTTP(w, r)
+				return
+			}
+			withSession.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Keys are managed with the business session only
+func (rt *Router) apiKeyHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.apiKeyList()))
+	r.Post("/", errorHandler(rt.apiKeyCreate()))
+	r.Patch("/{id}", errorHandler(rt.apiKeyUpdate()))
+	r.Post("/{id}/rotate", errorHandler(rt.apiKeyRotate()))
+	r.Delete("/{id}", errorHandler(rt.apiKeyRevoke()))
+	return r
+}
+
+func (rt *Router) apiKeyList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		keys, err := rt.apiKeys.List(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		list := make([]apiKeyResponse, len(keys))
+		for i, v := range keys {
+			list[i] = newAPIKeyResponse(v, "")
+		}
+		return writeJSON(w, list)
+	}
+}
+
+// Scopes are sent as repeated scope fields
+func (rt *Router) apiKeyCreate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		serialized, key, err := rt.apiKeys.Create(r.Context(), authenticatedId(r), r.PostForm.Get("name"), r.PostForm["scope"])
+		if err != nil {
+			return err
+		}
+		rt.audit(r, key.BusinessId, account.AuditAPIKeyCreated, map[string]string{"key": strconv.Itoa(key.Id), "scopes": strings.Join(key.Scopes, ",")})
+		return writeJSON(w, newAPIKeyResponse(*key, serialized))
+	}
+}
+
+func (rt *Router) apiKeyUpdate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrAPIKeyNotExist(r.Context())
+		}
+
+		present := make([]string, 0)
+		key, err := rt.apiKeys.Update(r.Context(), authenticatedId(r), id, optionalField(r, "name", &present), r.PostForm["scope"])
+		if err != nil {
+			return err
+		}
+		rt.audit(r, key.BusinessId, account.AuditAPIKeyUpdated, map[string]string{"key": strconv.Itoa(key.Id), "scopes": strings.Join(key.Scopes, ",")})
+		return writeJSON(w, newAPIKeyResponse(*key, ""))
+	}
+}
+
+func (rt *Router) apiKeyRotate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrAPIKeyNotExist(r.Context())
+		}
+
+		serialized, key, err := rt.apiKeys.Rotate(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, key.BusinessId, account.AuditAPIKeyRotated, map[string]string{"key": strconv.Itoa(key.Id)})
+		return writeJSON(w, newAPIKeyResponse(*key, serialized))
+	}
+}
+
+func (rt *Router) apiKeyRevoke() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrAPIKeyNotExist(r.Context())
+		}
+
+		err = rt.apiKeys.Revoke(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditAPIKeyRevoked, map[string]string{"key": strconv.Itoa(id)})
+		return nil
+	}
+}
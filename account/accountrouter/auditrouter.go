@@ -0,0 +1,191 @@
+package accountrouter
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type auditEventResponse struct {
+	Id        int64             `json:"id"`
+	AccountId *int              `json:"accountId"`
+	ActorId   *int              `json:"actorId,omitempty"`
+	Event     string            `json:"event"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"userAgent"`
+	RequestId string            `json:"requestId"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Time      time.Time         `json:"time"`
+}
+
+type auditPageResponse struct {
+	Events []auditEventResponse `json:"events"`
+	// Pass as before to get the next page, 0 if there is no more event
+	Next   int64                `json:"next"`
+}
+
+func newAuditPageResponse(events []account.AuditEvent, limit int) auditPageResponse {
+	res := auditPageResponse{Events: make([]auditEventResponse, len(events))}
+	for i, v := range events {
+		res.Events[i] = auditEventResponse{
+			Id:        v.Id,
+			AccountId: v.AccountId,
+			ActorId:   v.ActorId,
+			Event:     v.Event,
+			IP:        v.IP,
+			UserAgent: v.UserAgent,
+			RequestId: v.RequestId,
+			Metadata:  v.Metadata,
+			Time:      v.Time,
+		}
+	}
+
+	if len(events) > 0 && len(events) == limit {
+		res.Next = events[len(events)-1].Id
+	}
+	return res
+}
+
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Record an event of the request, the action has already happened
+// so a failure is logged instead of returned
+func (rt *Router) recordAudit(r *http.Request, event account.AuditEvent) {
+	if rt.auditLog == nil {
+		return
+	}
+
+	event.IP = requestIP(r)
+	event.UserAgent = r.UserAgent()
+	event.RequestId = middleware.GetReqID(r.Context())
+
+	err := rt.auditLog.Record(r.Context(), event)
+	if err != nil {
+		// ALERT log
+		log.Printf("fail to record audit event %s: %s", event.Event, err)
+	}
+}
+
+func (rt *Router) audit(r *http.Request, accountId int, event string, metadata map[string]string) {
+	rt.recordAudit(r, account.AuditEvent{AccountId: &accountId, Event: event, Metadata: metadata})
+}
+
+// The authenticated account acts on another account, like a parent business on its children
+func (rt *Router) auditOnBehalf(r *http.Request, accountId int, event string, metadata map[string]string) {
+	actorId := authenticatedId(r)
+	rt.recordAudit(r, account.AuditEvent{AccountId: &accountId, ActorId: &actorId, Event: event, Metadata: metadata})
+}
+
+// The failure is recorded for the account of the username if it exists
+func (rt *Router) auditLoginFailed(r *http.Request, accountId func() (int, error), method string) {
+	if rt.auditLog == nil {
+		return
+	}
+
+	event := account.AuditEvent{Event: account.AuditLoginFailed, Metadata: map[string]string{"method": method}}
+	if id, err := accountId(); err == nil {
+		event.AccountId = &id
+	}
+	rt.recordAudit(r, event)
+}
+
+func (rt *Router) userIdByName(r *http.Request) func() (int, error) {
+	return func() (int, error) {
+		acc, err := rt.user.UserRepo.FetchByUsername(r.Context(), r.PostForm.Get("username"))
+		if err != nil {
+			return 0, err
+		}
+		return *acc.Id, nil
+	}
+}
+
+func (rt *Router) businessIdByName(r *http.Request) func() (int, error) {
+	return func() (int, error) {
+		acc, err := rt.business.BusinessRepo.FetchByUsername(r.Context(), r.PostForm.Get("username"))
+		if err != nil {
+			return 0, err
+		}
+		return *acc.Id, nil
+	}
+}
+
+func auditPage(r *http.Request) (before int64, limit int) {
+	before, _ = strconv.ParseInt(r.URL.Query().Get("before"), 10, 64)
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	return before, limit
+}
+
+// Security history of the authenticated account
+func (rt *Router) auditHistory() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		before, limit := auditPage(r)
+		if limit <= 0 || limit > rt.auditLog.MaxLimit {
+			limit = rt.auditLog.MaxLimit
+		}
+
+		events, err := rt.auditLog.History(r.Context(), authenticatedId(r), before, limit)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newAuditPageResponse(events, limit))
+	}
+}
+
+// Filter by accountId, event, ip, since and until in RFC 3339
+func (rt *Router) staffAuditSearch() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+
+		before, limit := auditPage(r)
+		if limit <= 0 || limit > rt.auditLog.MaxLimit {
+			limit = rt.auditLog.MaxLimit
+		}
+
+		filter := account.AuditFilter{
+			Event:  query.Get("event"),
+			IP:     query.Get("ip"),
+			Before: before,
+			Limit:  limit,
+		}
+
+		if v := query.Get("accountId"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return nil
+			}
+			filter.AccountId = &id
+		}
+
+		for key, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+			v := query.Get(key)
+			if v == "" {
+				continue
+			}
+
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return nil
+			}
+			*t = parsed
+		}
+
+		events, err := rt.auditLog.Search(r.Context(), filter)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newAuditPageResponse(events, limit))
+	}
+}
@@ -0,0 +1,56 @@
+package accountrouter
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type walletBalanceResponse struct {
+	Chain    string            `json:"chain"`
+	Address  string            `json:"address"`
+	Balances map[string]string `json:"balances"`
+}
+
+type currencyBalanceResponse struct {
+	Chain    string `json:"chain"`
+	Currency string `json:"currency"`
+	Amount   string `json:"amount"`
+}
+
+type accountBalanceResponse struct {
+	Wallets   []walletBalanceResponse   `json:"wallets"`
+	Totals    []currencyBalanceResponse `json:"totals"`
+	QueriedAt time.Time                 `json:"queriedAt"`
+}
+
+func newAccountBalanceResponse(v *account.AccountBalance) accountBalanceResponse {
+	res := accountBalanceResponse{
+		Wallets:   make([]walletBalanceResponse, len(v.Wallets)),
+		Totals:    make([]currencyBalanceResponse, len(v.Totals)),
+		QueriedAt: v.QueriedAt,
+	}
+	for i, w := range v.Wallets {
+		balances := make(map[string]string, len(w.Balances))
+		for currency, amount := range w.Balances {
+			balances[currency] = amount.String()
+		}
+		res.Wallets[i] = walletBalanceResponse{Chain: w.Chain, Address: w.Hex, Balances: balances}
+	}
+	for i, t := range v.Totals {
+		res.Totals[i] = currencyBalanceResponse{Chain: t.Chain, Currency: t.Currency, Amount: t.Amount.String()}
+	}
+	return res
+}
+
+// Balances of the wallets of the authenticated account and their totals per currency
+func (rt *Router) accountBalance() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		balance, err := rt.balances.Account(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newAccountBalanceResponse(balance))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/h2non/filetype"
@@ -58,6 +59,7 @@ func (rt *Router) businessRegister() errorFunc {
 		if err != nil {
 			return err
 		}
+		rt.audit(r, id, account.AuditRegistered, nil)
 
 		shared, err := rt.businessProvider.Init(r.Context(), id)
 		if err != nil {
@@ -73,6 +75,39 @@ type AccountForm struct {
 
 }
 
+// Images or pdf in the document field of the parsed multipart form
+func readDocuments(r *http.Request) ([][]byte, error) {
+	files := make([][]byte, 0)
+	fhs := r.MultipartForm.File["document"]
+	if len(fhs) > account.MaxBusinessDocuments {
+		return nil, account.ErrTooManyFiles(r.Context())
+	}
+	for _, v := range fhs {
+		if v.Size > account.MaxBusinessDocumentSize {
+			return nil, account.ErrFileTooLarge(r.Context())
+		}
+
+		f, err := v.Open()
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if !(filetype.IsImage(buf.Bytes()) || filetype.Is(buf.Bytes(), "pdf")) {
+			return nil, account.ErrInvalidFileType(r.Context())
+		}
+
+		files = append(files, buf.Bytes())
+	}
+	return files, nil
+}
+
 func (rt *Router) businessRegisterWithIdentity() errorFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		r.Body = http.MaxBytesReader(w, r.Body, int64(math.Pow10(7)))
@@ -82,32 +117,9 @@ func (rt *Router) businessRegisterWithIdentity() errorFunc {
 			return nil
 		}
 
-		files := make([][]byte, 0)
-		fhs := r.MultipartForm.File["document"]
-		if len(fhs) > account.MaxBusinessDocuments {
-			return account.ErrTooManyFiles(r.Context())
-		}
-		for _, v := range fhs {
-			if v.Size > account.MaxBusinessDocumentSize {
-				return account.ErrFileTooLarge(r.Context())
-			}
-
-			f, err := v.Open()
-			if err != nil {
-				return err
-			}
-
-			var buf bytes.Buffer
-			_, err = io.Copy(&buf, f)
-			if err != nil {
-				return err
-			}
-
-			if !(filetype.IsImage(buf.Bytes()) || filetype.Is(buf.Bytes(), "pdf")) {
-				return account.ErrInvalidFileType(r.Context())
-			}
-
-			files = append(files, buf.Bytes())
+		files, err := readDocuments(r)
+		if err != nil {
+			return err
 		}
 
 		id, err := rt.business.CreateAccountWithIdentity(r.Context(), account.BusinessRegistrationFormWithIdentity{
@@ -125,6 +137,8 @@ func (rt *Router) businessRegisterWithIdentity() errorFunc {
 		if err != nil {
 			return err
 		}
+		rt.audit(r, id, account.AuditRegistered, nil)
+		rt.audit(r, id, account.AuditDocumentsChanged, map[string]string{"count": strconv.Itoa(len(files))})
 
 		shared, err := rt.businessProvider.Init(r.Context(), id)
 		if err != nil {
@@ -146,9 +160,17 @@ func (rt *Router) businessLogin() errorFunc {
 
 		id, err := rt.business.Login(r.Context(), r.PostForm.Get("username"), r.PostForm.Get("password"))
 		if err != nil {
+			rt.auditLoginFailed(r, rt.businessIdByName(r), "password")
+			return err
+		}
+
+		pending, err := rt.beginPendingLogin(w, r, cookiens.BusinessPendingLogin, pendingBusinessKey, id)
+		if err != nil || pending {
 			return err
 		}
 
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "password"})
+
 		shared, err := rt.businessProvider.Init(r.Context(), id)
 		if err != nil {
 			return err
@@ -190,7 +212,12 @@ func (rt *Router) businessResetPassword() errorFunc {
 			return nil
 		}
 
-		return rt.businessRecovery.ResetPassword(r.Context(), r.PostForm.Get("token"), r.PostForm.Get("password"))
+		id, err := rt.businessRecovery.ResetPassword(r.Context(), r.PostForm.Get("token"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditPasswordReset, nil)
+		return nil
 	}
 }
 
@@ -201,6 +228,11 @@ func (rt *Router) businessLogout() errorFunc {
 			return err
 		}
 
+		shared, err := rt.businessProvider.Read(r.Context(), cookie.Value)
+		if err == nil {
+			rt.audit(r, shared.Id, account.AuditSessionDestroyed, map[string]string{"reason": "logout"})
+		}
+
 		err = rt.businessProvider.Destroy(r.Context(), cookie.Value)
 		if err != nil {
 			return err
@@ -0,0 +1,289 @@
+package accountrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type childResponse struct {
+	Id          int    `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"displayName"`
+	Active      bool   `json:"active"`
+}
+
+type accountWalletResponse struct {
+	AccountId int `json:"accountId"`
+	walletResponse
+}
+
+// A transfer of a transaction, celo transaction has one row per transfer event
+type transferResponse struct {
+	AccountId     int       `json:"accountId"`
+	Chain         string    `json:"chain"`
+	Version       uint64    `json:"version"`
+	Index         int       `json:"index"`
+	LogIndex      int       `json:"logIndex"`
+	Hash          string    `json:"hash"`
+	Time          time.Time `json:"time"`
+	Status        string    `json:"status"`
+	Currency      string    `json:"currency"`
+	Amount        string    `json:"amount"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	SenderMessage string    `json:"senderMessage"`
+	Refund        bool      `json:"refund"`
+	Message       string    `json:"message"`
+	// Set when refunds are enabled
+	RefundOf      *transferIdResponse  `json:"refundOf,omitempty"`
+	RefundedBy    []transferIdResponse `json:"refundedBy,omitempty"`
+}
+
+func newTransferResponses(txs account.AccountTransactions) []transferResponse {
+	list := make([]transferResponse, 0)
+	for _, v := range txs.Diem {
+		list = append(list, transferResponse{
+			AccountId:     txs.AccountId,
+			Chain:         v.Chain,
+			Version:       v.Version,
+			Hash:          v.Hash,
+			Time:          v.Time,
+			Status:        v.Status,
+			Currency:      v.Currency,
+			Amount:        v.Amount.String(),
+			From:          v.From,
+			To:            v.To,
+			SenderMessage: v.TransactionSenderRemark.Message,
+			Refund:        v.IsRefund,
+			Message:       v.TransactionAccountRemark.Message,
+		})
+	}
+
+	for _, byIndex := range txs.Celo {
+		for _, v := range byIndex {
+			for logIndex, transfer := range v.TransferEvents {
+				list = append(list, transferResponse{
+					AccountId:     txs.AccountId,
+					Chain:         v.Chain,
+					Version:       v.Version,
+					Index:         v.Index,
+					LogIndex:      logIndex,
+					Hash:          v.Hash,
+					Time:          v.Time,
+					Status:        v.Status,
+					Currency:      transfer.Currency,
+					Amount:        transfer.Amount.String(),
+					From:          transfer.From,
+					To:            transfer.To,
+					SenderMessage: v.TransactionSenderRemark.Message,
+					Refund:        v.IsRefund,
+					Message:       v.TransactionAccountRemark.Message,
+				})
+			}
+		}
+	}
+
+	// latest first
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Time.After(list[j].Time) })
+	return list
+}
+
+func newAccountWalletResponses(accountId int, wallets []wallet.Wallet) []accountWalletResponse {
+	list := make([]accountWalletResponse, len(wallets))
+	for i, v := range wallets {
+		list[i] = accountWalletResponse{
+			AccountId: accountId,
+			walletResponse: walletResponse{
+				Chain:     v.Chain,
+				Address:   v.Hex,
+				PublicKey: v.PublicKey,
+			},
+		}
+	}
+	return list
+}
+
+// Routes for a parent business to manage its children
+func (rt *Router) childHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.childList()))
+	r.Post("/", errorHandler(rt.childCreate()))
+	r.Get("/wallets", errorHandler(rt.childAllWallets()))
+	r.Get("/transactions", errorHandler(rt.childAllTransactions()))
+	r.Post("/{id}/activate", errorHandler(rt.childSetActive(true)))
+	r.Post("/{id}/deactivate", errorHandler(rt.childSetActive(false)))
+	r.Get("/{id}/wallets", errorHandler(rt.childWallets()))
+	r.Get("/{id}/transactions", errorHandler(rt.childTransactions()))
+	return r
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) error {
+	res, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+	return nil
+}
+
+// Start versions of each chain, default to 0
+func transactionStart(r *http.Request) (diemStart, celoStart uint64) {
+	diemStart, _ = strconv.ParseUint(r.URL.Query().Get("diemStart"), 10, 64)
+	celoStart, _ = strconv.ParseUint(r.URL.Query().Get("celoStart"), 10, 64)
+	return diemStart, celoStart
+}
+
+func (rt *Router) childList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		children, err := rt.hierarchy.Children(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		list := make([]childResponse, len(children))
+		for i, v := range children {
+			list[i] = childResponse{
+				Id:          v.Id,
+				Username:    v.Username,
+				DisplayName: v.DisplayName,
+				Active:      v.Active,
+			}
+		}
+		return writeJSON(w, list)
+	}
+}
+
+func (rt *Router) childCreate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.business.CreateChildAccount(r.Context(), authenticatedId(r), account.BusinessRegistrationForm{
+			Username:    r.PostForm.Get("username"),
+			DisplayName: r.PostForm.Get("displayName"),
+			Password:    r.PostForm.Get("password"),
+			Email:       r.PostForm.Get("email"),
+		})
+		if err != nil {
+			return err
+		}
+		rt.auditOnBehalf(r, id, account.AuditRegistered, nil)
+		return writeJSON(w, id)
+	}
+}
+
+func (rt *Router) childSetActive(active bool) errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrChildNotExist(r.Context())
+		}
+
+		err = rt.hierarchy.SetActive(r.Context(), authenticatedId(r), id, active)
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			err = rt.businessProvider.DestroyAll(r.Context(), id)
+			if err != nil {
+				return err
+			}
+			rt.auditOnBehalf(r, id, account.AuditSessionDestroyed, map[string]string{"reason": "deactivated"})
+		}
+		return nil
+	}
+}
+
+func (rt *Router) childWallets() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrChildNotExist(r.Context())
+		}
+
+		wallets, err := rt.hierarchy.Wallets(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newAccountWalletResponses(id, wallets))
+	}
+}
+
+func (rt *Router) childAllWallets() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		wallets, err := rt.hierarchy.AllWallets(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int, 0, len(wallets))
+		for id := range wallets {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+
+		list := make([]accountWalletResponse, 0)
+		for _, id := range ids {
+			list = append(list, newAccountWalletResponses(id, wallets[id])...)
+		}
+		return writeJSON(w, list)
+	}
+}
+
+func (rt *Router) childTransactions() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrChildNotExist(r.Context())
+		}
+
+		diemStart, celoStart := transactionStart(r)
+		txs, err := rt.hierarchy.Transactions(r.Context(), authenticatedId(r), id, diemStart, celoStart)
+		if err != nil {
+			return err
+		}
+
+		list := newTransferResponses(*txs)
+		err = rt.linkRefunds(r.Context(), list)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, list)
+	}
+}
+
+func (rt *Router) childAllTransactions() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		diemStart, celoStart := transactionStart(r)
+		all, err := rt.hierarchy.AllTransactions(r.Context(), authenticatedId(r), diemStart, celoStart)
+		if err != nil {
+			return err
+		}
+
+		list := make([]transferResponse, 0)
+		for _, v := range all {
+			list = append(list, newTransferResponses(v)...)
+		}
+
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Time.After(list[j].Time) })
+		err = rt.linkRefunds(r.Context(), list)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, list)
+	}
+}
@@ -0,0 +1,118 @@
+package accountrouter
+
+import (
+	"net/http"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+)
+
+func (rt *Router) userDelete() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id := authenticatedId(r)
+		err = rt.user.DeleteAccount(r.Context(), id, r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditDeleted, nil)
+
+		err = rt.userProvider.DestroyAll(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		clearCookie(w, cookiens.UserSession)
+		return nil
+	}
+}
+
+func (rt *Router) userRestore() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.user.RestoreAccount(r.Context(), r.PostForm.Get("username"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditRestored, nil)
+
+		// the restored account logs in like with a password
+		pending, err := rt.beginPendingLogin(w, r, cookiens.UserPendingLogin, pendingUserKey, id)
+		if err != nil || pending {
+			return err
+		}
+
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "password"})
+
+		shared, err := rt.userProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.userSession(shared).Attach(w)
+		return nil
+	}
+}
+
+func (rt *Router) businessDelete() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id := authenticatedId(r)
+		err = rt.business.DeleteAccount(r.Context(), id, r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditDeleted, nil)
+
+		err = rt.businessProvider.DestroyAll(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		clearCookie(w, cookiens.BusinessSession)
+		return nil
+	}
+}
+
+func (rt *Router) businessRestore() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.business.RestoreAccount(r.Context(), r.PostForm.Get("username"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditRestored, nil)
+
+		// the restored account logs in like with a password
+		pending, err := rt.beginPendingLogin(w, r, cookiens.BusinessPendingLogin, pendingBusinessKey, id)
+		if err != nil || pending {
+			return err
+		}
+
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "password"})
+
+		shared, err := rt.businessProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.businessSession(shared).Attach(w)
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+package accountrouter
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+// Change the email of the authenticated account
+func (rt *Router) userEmailHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.user.RequestEmailChange(r.Context(), authenticatedId(r), r.PostForm.Get("password"), r.PostForm.Get("email"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditEmailRequested, map[string]string{"email": r.PostForm.Get("email")})
+		return nil
+	}))
+
+	r.Post("/resend", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		return rt.user.ResendEmailVerification(r.Context(), authenticatedId(r))
+	}))
+
+	r.Post("/verify", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.user.VerifyEmail(r.Context(), authenticatedId(r), r.PostForm.Get("email"), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditEmailVerified, map[string]string{"email": r.PostForm.Get("email")})
+		return nil
+	}))
+	return r
+}
+
+func (rt *Router) businessEmailHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.business.RequestEmailChange(r.Context(), authenticatedId(r), r.PostForm.Get("password"), r.PostForm.Get("email"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditEmailRequested, map[string]string{"email": r.PostForm.Get("email")})
+		return nil
+	}))
+
+	r.Post("/resend", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		return rt.business.ResendEmailVerification(r.Context(), authenticatedId(r))
+	}))
+
+	r.Post("/verify", errorHandler(func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.business.VerifyEmail(r.Context(), authenticatedId(r), r.PostForm.Get("email"), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditEmailVerified, map[string]string{"email": r.PostForm.Get("email")})
+		return nil
+	}))
+	return r
+}
+
+var revertEmailPage = template.Must(template.New("revert").Parse(`<!DOCTYPE html>
+<html>
+<body>
+	<p>Undo the change of your email? All devices will be signed out.</p>
+	<form method="post">
+		<input type="hidden" name="token" value="{{ . }}">
+		<button type="submit">Undo</button>
+	</form>
+</body>
+</html>`))
+
+// Opened from the link in the email, the revert is only done when the form is posted
+// so link previews can't undo the change
+func revertEmailConfirm() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		return revertEmailPage.Execute(w, token)
+	}
+}
+
+// Signed out everywhere since the change might be made by someone else
+func (rt *Router) userRevertEmail() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.user.RevertEmail(r.Context(), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditEmailReverted, nil)
+
+		err = rt.userProvider.DestroyAll(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditSessionDestroyed, map[string]string{"reason": "email reverted"})
+		return nil
+	}
+}
+
+func (rt *Router) businessRevertEmail() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.business.RevertEmail(r.Context(), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditEmailReverted, nil)
+
+		err = rt.businessProvider.DestroyAll(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditSessionDestroyed, map[string]string{"reason": "email reverted"})
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package accountrouter
+
+import (
+	"net/http"
+	"time"
+)
+
+type syncLagResponse struct {
+	Chain      string     `json:"chain"`
+	Wallets    int        `json:"wallets"`
+	Pending    int        `json:"pending"`
+	OldestSync *time.Time `json:"oldestSync"`
+	// Seconds since the least recently indexed wallet
+	Lag        float64    `json:"lag"`
+}
+
+// Sync lag of the wallet indexer per chain
+func (rt *Router) staffIndexerLag() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		lags, err := rt.indexer.Lag(r.Context())
+		if err != nil {
+			return err
+		}
+
+		res := make([]syncLagResponse, len(lags))
+		for i, v := range lags {
+			res[i] = syncLagResponse{
+				Chain:   v.Chain,
+				Wallets: v.Wallets,
+				Pending: v.Pending,
+				Lag:     v.Lag.Seconds(),
+			}
+			if !v.OldestSync.IsZero() {
+				oldest := v.OldestSync
+				res[i].OldestSync = &oldest
+			}
+		}
+		return writeJSON(w, res)
+	}
+}
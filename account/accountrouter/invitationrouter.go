@@ -0,0 +1,191 @@
+package accountrouter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+const (
+	defaultReferralDepth = 3
+	maxReferralDepth     = 10
+)
+
+type invitationResponse struct {
+	Id        int       `json:"id"`
+	Email     string    `json:"email"`
+	Accepted  bool      `json:"accepted"`
+	Revoked   bool      `json:"revoked"`
+	CreatedAt time.Time `json:"createdAt"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+type invitationListResponse struct {
+	Remaining   int                  `json:"remaining"`
+	Invitations []invitationResponse `json:"invitations"`
+}
+
+type referralResponse struct {
+	Id        int                `json:"id"`
+	Username  string             `json:"username"`
+	Email     string             `json:"email,omitempty"`
+	InvitedAt *time.Time         `json:"invitedAt,omitempty"`
+	Invitees  []referralResponse `json:"invitees"`
+}
+
+func newInvitationResponse(v account.Invitation) invitationResponse {
+	return invitationResponse{
+		Id:        v.Id,
+		Email:     v.Email,
+		Accepted:  v.InviteeId != nil,
+		Revoked:   v.Revoked,
+		CreatedAt: v.CreatedAt,
+		SentAt:    v.SentAt,
+	}
+}
+
+func newReferralResponse(node *account.ReferralNode) referralResponse {
+	res := referralResponse{
+		Id:       node.InviteeId,
+		Username: node.Username,
+		Email:    node.Email,
+		Invitees: make([]referralResponse, len(node.Invitees)),
+	}
+	if !node.CreatedAt.IsZero() {
+		res.InvitedAt = &node.CreatedAt
+	}
+
+	for i, v := range node.Invitees {
+		res.Invitees[i] = newReferralResponse(v)
+	}
+	return res
+}
+
+// Invitations issued by the authenticated user
+func (rt *Router) invitationHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.invitationList()))
+	r.Post("/", errorHandler(rt.invitationCreate()))
+	r.Post("/{id}/resend", errorHandler(rt.invitationResend()))
+	r.Delete("/{id}", errorHandler(rt.invitationRevoke()))
+	return r
+}
+
+func (rt *Router) invitationList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id := authenticatedId(r)
+
+		invitations, err := rt.user.ListInvitations(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		remaining, err := rt.user.Invitations.Remaining(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		res := invitationListResponse{Remaining: remaining, Invitations: make([]invitationResponse, len(invitations))}
+		for i, v := range invitations {
+			res.Invitations[i] = newInvitationResponse(v)
+		}
+		return writeJSON(w, res)
+	}
+}
+
+func (rt *Router) invitationCreate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		invitation, err := rt.user.CreateInvitation(r.Context(), authenticatedId(r), r.PostForm.Get("email"))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newInvitationResponse(*invitation))
+	}
+}
+
+func (rt *Router) invitationResend() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrInvitationNotExist(r.Context())
+		}
+
+		return rt.user.ResendInvitation(r.Context(), authenticatedId(r), id)
+	}
+}
+
+func (rt *Router) invitationRevoke() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrInvitationNotExist(r.Context())
+		}
+
+		return rt.user.RevokeInvitation(r.Context(), authenticatedId(r), id)
+	}
+}
+
+// Users invited by the user and their invitees, down to the depth query
+func (rt *Router) staffReferralTree() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		depth, err := strconv.Atoi(r.URL.Query().Get("depth"))
+		if err != nil || depth <= 0 {
+			depth = defaultReferralDepth
+		} else if depth > maxReferralDepth {
+			depth = maxReferralDepth
+		}
+
+		acc, err := rt.user.UserRepo.FetchById(r.Context(), id)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		tree, err := rt.user.Invitations.ReferralTree(r.Context(), id, depth)
+		if err != nil {
+			return err
+		}
+		tree.Username = acc.Username
+		return writeJSON(w, newReferralResponse(tree))
+	}
+}
+
+func (rt *Router) staffSetInvitationQuota() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		quota, err := strconv.Atoi(r.PostForm.Get("quota"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		return rt.user.Invitations.SetQuota(r.Context(), id, quota)
+	}
+}
@@ -0,0 +1,154 @@
+package accountrouter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type invoiceResponse struct {
+	Id         int        `json:"id"`
+	Reference  string     `json:"reference"`
+	Chain      string     `json:"chain"`
+	Address    string     `json:"address"`
+	Currency   string     `json:"currency"`
+	// Amounts are decimal strings in the smallest unit of the currency
+	Amount     string     `json:"amount"`
+	Received   string     `json:"received"`
+	Memo       string     `json:"memo"`
+	Status     string     `json:"status"`
+	PaymentURI string     `json:"paymentUri"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	PaidAt     *time.Time `json:"paidAt"`
+}
+
+func newInvoiceResponse(v account.Invoice) invoiceResponse {
+	return invoiceResponse{
+		Id:         v.Id,
+		Reference:  v.Reference,
+		Chain:      v.Chain,
+		Address:    v.Address,
+		Currency:   v.Currency,
+		Amount:     v.Amount.String(),
+		Received:   v.Received.String(),
+		Memo:       v.Memo,
+		Status:     v.Status,
+		PaymentURI: v.PaymentURI(),
+		ExpiresAt:  v.ExpiresAt,
+		CreatedAt:  v.CreatedAt,
+		PaidAt:     v.PaidAt,
+	}
+}
+
+type invoicePaymentResponse struct {
+	Chain    string    `json:"chain"`
+	Version  uint64    `json:"version"`
+	Index    int       `json:"index"`
+	Transfer int       `json:"transfer"`
+	From     string    `json:"from"`
+	Amount   string    `json:"amount"`
+	Time     time.Time `json:"time"`
+}
+
+type invoiceDetailResponse struct {
+	invoiceResponse
+	Payments []invoicePaymentResponse `json:"payments"`
+}
+
+type invoicePageResponse struct {
+	Invoices []invoiceResponse `json:"invoices"`
+	// Pass as before to get the next page, 0 if the page is empty
+	Next     int               `json:"next"`
+}
+
+// Invoices can be managed with the business session or an API key
+func (rt *Router) invoiceHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/", errorHandler(rt.invoiceList()))
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeInvoices)).Post("/", errorHandler(rt.invoiceCreate()))
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/{id}", errorHandler(rt.invoiceGet()))
+	return r
+}
+
+func (rt *Router) invoiceList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+		before, _ := strconv.Atoi(query.Get("before"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		invoices, err := rt.invoices.List(r.Context(), authenticatedId(r), query.Get("status"), before, limit)
+		if err != nil {
+			return err
+		}
+
+		res := invoicePageResponse{Invoices: make([]invoiceResponse, len(invoices))}
+		for i, v := range invoices {
+			res.Invoices[i] = newInvoiceResponse(v)
+		}
+		if len(invoices) > 0 {
+			res.Next = invoices[len(invoices)-1].Id
+		}
+		return writeJSON(w, res)
+	}
+}
+
+// Expiry is sent as expiresIn in seconds
+func (rt *Router) invoiceCreate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		expiresIn, _ := strconv.Atoi(r.PostForm.Get("expiresIn"))
+		invoice, err := rt.invoices.Create(r.Context(), authenticatedId(r), account.InvoiceForm{
+			Chain:     r.PostForm.Get("chain"),
+			Address:   r.PostForm.Get("address"),
+			Currency:  r.PostForm.Get("currency"),
+			Amount:    r.PostForm.Get("amount"),
+			Memo:      r.PostForm.Get("memo"),
+			ExpiresIn: time.Duration(expiresIn) * time.Second,
+		})
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newInvoiceResponse(*invoice))
+	}
+}
+
+func (rt *Router) invoiceGet() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrInvoiceNotExist(r.Context())
+		}
+
+		invoice, payments, err := rt.invoices.Get(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+
+		res := invoiceDetailResponse{
+			invoiceResponse: newInvoiceResponse(*invoice),
+			Payments:        make([]invoicePaymentResponse, len(payments)),
+		}
+		for i, v := range payments {
+			res.Payments[i] = invoicePaymentResponse{
+				Chain:    v.Chain,
+				Version:  v.Version,
+				Index:    v.Index,
+				Transfer: v.Transfer,
+				From:     v.From,
+				Amount:   v.Amount.String(),
+				Time:     v.Time,
+			}
+		}
+		return writeJSON(w, res)
+	}
+}
@@ -0,0 +1,316 @@
+package accountrouter
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+	"github.com/stevealexrs/Go-Libra/webauthn"
+)
+
+const passkeyTimeout = 5 * time.Minute
+
+type passkeyRelyingParty struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type passkeyUser struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type passkeyParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type passkeyDescriptor struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type passkeySelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// PublicKeyCredentialCreationOptions, binary values are base64url encoded
+type passkeyCreationOptions struct {
+	Challenge              string              `json:"challenge"`
+	RelyingParty           passkeyRelyingParty `json:"rp"`
+	User                   passkeyUser         `json:"user"`
+	PubKeyCredParams       []passkeyParam      `json:"pubKeyCredParams"`
+	Timeout                int64               `json:"timeout"`
+	ExcludeCredentials     []passkeyDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection passkeySelection    `json:"authenticatorSelection"`
+	Attestation            string              `json:"attestation"`
+}
+
+// PublicKeyCredentialRequestOptions, the token must be sent back with the assertion
+type passkeyRequestOptions struct {
+	Token            string `json:"token"`
+	Challenge        string `json:"challenge"`
+	RelyingPartyId   string `json:"rpId"`
+	Timeout          int64  `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+type passkeyResponse struct {
+	Id        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (rt *Router) userVerification() string {
+	if rt.passkeys.RelyingParty.RequireUserVerification {
+		return "required"
+	}
+	return "preferred"
+}
+
+// Registration and management of passkeys, accountName is shown by the authenticator
+func (rt *Router) passkeyHandler(accountName func(ctx context.Context, id int) (string, error)) chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.passkeyList()))
+	r.Post("/register/begin", errorHandler(rt.passkeyBeginRegistration(accountName)))
+	r.Post("/register/finish", errorHandler(rt.passkeyFinishRegistration()))
+	r.Delete("/{id}", errorHandler(rt.passkeyDelete()))
+	return r
+}
+
+func (rt *Router) passkeyList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		passkeys, err := rt.passkeys.List(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		list := make([]passkeyResponse, len(passkeys))
+		for i, v := range passkeys {
+			list[i] = passkeyResponse{
+				Id:        webauthn.Encoding.EncodeToString(v.ID),
+				CreatedAt: v.CreatedAt,
+			}
+		}
+		return writeJSON(w, list)
+	}
+}
+
+func (rt *Router) passkeyBeginRegistration(accountName func(ctx context.Context, id int) (string, error)) errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id := authenticatedId(r)
+
+		name, err := accountName(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		challenge, existing, err := rt.passkeys.BeginRegistration(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		exclude := make([]passkeyDescriptor, len(existing))
+		for i, v := range existing {
+			exclude[i] = passkeyDescriptor{Type: "public-key", Id: webauthn.Encoding.EncodeToString(v.ID)}
+		}
+
+		return writeJSON(w, passkeyCreationOptions{
+			Challenge: webauthn.Encoding.EncodeToString(challenge),
+			RelyingParty: passkeyRelyingParty{
+				Id:   rt.passkeys.RelyingParty.ID,
+				Name: rt.passkeys.RelyingParty.Name,
+			},
+			User: passkeyUser{
+				Id:          webauthn.Encoding.EncodeToString([]byte(strconv.Itoa(id))),
+				Name:        name,
+				DisplayName: name,
+			},
+			PubKeyCredParams: []passkeyParam{
+				{Type: "public-key", Alg: webauthn.AlgES256},
+				{Type: "public-key", Alg: webauthn.AlgRS256},
+			},
+			Timeout:            passkeyTimeout.Milliseconds(),
+			ExcludeCredentials: exclude,
+			AuthenticatorSelection: passkeySelection{
+				ResidentKey:      "required",
+				UserVerification: rt.userVerification(),
+			},
+			Attestation: "none",
+		})
+	}
+}
+
+func (rt *Router) passkeyFinishRegistration() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		clientData, err := webauthn.Encoding.DecodeString(r.PostForm.Get("clientDataJSON"))
+		if err != nil {
+			return account.ErrPasskeyInvalid(r.Context())
+		}
+
+		attestation, err := webauthn.Encoding.DecodeString(r.PostForm.Get("attestationObject"))
+		if err != nil {
+			return account.ErrPasskeyInvalid(r.Context())
+		}
+
+		err = rt.passkeys.FinishRegistration(r.Context(), authenticatedId(r), clientData, attestation)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditPasskeyAdded, nil)
+		return nil
+	}
+}
+
+func (rt *Router) passkeyDelete() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := webauthn.Encoding.DecodeString(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrPasskeyNotExist(r.Context())
+		}
+
+		err = rt.passkeys.Delete(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditPasskeyRemoved, map[string]string{"passkey": chi.URLParam(r, "id")})
+		return nil
+	}
+}
+
+func (rt *Router) passkeyBeginLogin() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		token, challenge, err := rt.passkeys.BeginLogin(r.Context())
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(w, passkeyRequestOptions{
+			Token:            token,
+			Challenge:        webauthn.Encoding.EncodeToString(challenge),
+			RelyingPartyId:   rt.passkeys.RelyingParty.ID,
+			Timeout:          passkeyTimeout.Milliseconds(),
+			UserVerification: rt.userVerification(),
+		})
+	}
+}
+
+// Returns the owner of the passkey
+func (rt *Router) finishPasskeyLogin(r *http.Request) (int, error) {
+	fields := []string{"id", "clientDataJSON", "authenticatorData", "signature"}
+	decoded := make([][]byte, len(fields))
+	for i, v := range fields {
+		b, err := webauthn.Encoding.DecodeString(r.PostForm.Get(v))
+		if err != nil {
+			return 0, account.ErrInvalidCredentials(r.Context())
+		}
+		decoded[i] = b
+	}
+
+	return rt.passkeys.FinishLogin(r.Context(), r.PostForm.Get("token"), account.PasskeyAssertion{
+		CredentialId:      decoded[0],
+		ClientDataJSON:    decoded[1],
+		AuthenticatorData: decoded[2],
+		Signature:         decoded[3],
+	})
+}
+
+// Owner of the passkey of a failed login, the failure is recorded for it
+func (rt *Router) passkeyOwner(r *http.Request) func() (int, error) {
+	return func() (int, error) {
+		credentialId, err := webauthn.Encoding.DecodeString(r.PostForm.Get("id"))
+		if err != nil {
+			return 0, err
+		}
+
+		passkey, err := rt.passkeys.PasskeyRepo.FetchById(r.Context(), credentialId)
+		if err != nil {
+			return 0, err
+		}
+		return passkey.AccountId, nil
+	}
+}
+
+func (rt *Router) userLoginPasskey() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.finishPasskeyLogin(r)
+		if err != nil {
+			rt.auditLoginFailed(r, rt.passkeyOwner(r), "passkey")
+			return err
+		}
+
+		err = rt.user.CanLogin(r.Context(), id)
+		if err != nil {
+			rt.auditLoginFailed(r, func() (int, error) { return id, nil }, "passkey")
+			return err
+		}
+
+		// a passkey replaces the password, not the second factor
+		pending, err := rt.beginPendingLogin(w, r, cookiens.UserPendingLogin, pendingUserKey, id)
+		if err != nil || pending {
+			return err
+		}
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "passkey"})
+
+		shared, err := rt.userProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.userSession(shared).Attach(w)
+		return nil
+	}
+}
+
+func (rt *Router) businessLoginPasskey() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.finishPasskeyLogin(r)
+		if err != nil {
+			rt.auditLoginFailed(r, rt.passkeyOwner(r), "passkey")
+			return err
+		}
+
+		err = rt.business.CanLogin(r.Context(), id)
+		if err != nil {
+			rt.auditLoginFailed(r, func() (int, error) { return id, nil }, "passkey")
+			return err
+		}
+
+		// a passkey replaces the password, not the second factor
+		pending, err := rt.beginPendingLogin(w, r, cookiens.BusinessPendingLogin, pendingBusinessKey, id)
+		if err != nil || pending {
+			return err
+		}
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "passkey"})
+
+		shared, err := rt.businessProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.businessSession(shared).Attach(w)
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package accountrouter
+
+import (
+	"net/http"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+)
+
+// Other devices are signed out when signOutOthers is true
+func (rt *Router) userChangePassword() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.user.ChangePassword(r.Context(), authenticatedId(r), r.PostForm.Get("currentPassword"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditPasswordChanged, nil)
+
+		if r.PostForm.Get("signOutOthers") != "true" {
+			return nil
+		}
+
+		cookie, err := r.Cookie(cookiens.UserSession)
+		if err != nil {
+			return err
+		}
+
+		err = rt.userProvider.DestroyOther(r.Context(), cookie.Value)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditSessionDestroyed, map[string]string{"reason": "password changed"})
+		return nil
+	}
+}
+
+func (rt *Router) businessChangePassword() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.business.ChangePassword(r.Context(), authenticatedId(r), r.PostForm.Get("currentPassword"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditPasswordChanged, nil)
+
+		if r.PostForm.Get("signOutOthers") != "true" {
+			return nil
+		}
+
+		cookie, err := r.Cookie(cookiens.BusinessSession)
+		if err != nil {
+			return err
+		}
+
+		err = rt.businessProvider.DestroyOther(r.Context(), cookie.Value)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditSessionDestroyed, map[string]string{"reason": "password changed"})
+		return nil
+	}
+}
+
+func (rt *Router) userUnlock() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.user.Unlock(r.Context(), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditUnlocked, nil)
+		return nil
+	}
+}
+
+func (rt *Router) businessUnlock() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.business.Unlock(r.Context(), r.PostForm.Get("token"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditUnlocked, nil)
+		return nil
+	}
+}
@@ -0,0 +1,158 @@
+package accountrouter
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type userProfileResponse struct {
+	Id              int    `json:"id"`
+	Username        string `json:"username"`
+	DisplayName     string `json:"displayName"`
+	Email           string `json:"email"`
+	UnverifiedEmail string `json:"unverifiedEmail"`
+}
+
+type businessProfileResponse struct {
+	Id                  int      `json:"id"`
+	Username            string   `json:"username"`
+	DisplayName         string   `json:"displayName"`
+	DisplayNameVerified bool     `json:"displayNameVerified"`
+	Email               string   `json:"email"`
+	UnverifiedEmail     string   `json:"unverifiedEmail"`
+	OfficialName        string   `json:"officialName"`
+	RegistrationNumber  string   `json:"registrationNumber"`
+	Address             string   `json:"address"`
+	Documents           []string `json:"documents"`
+	IdentityVerified    bool     `json:"identityVerified"`
+	ParentId            *int     `json:"parentId,omitempty"`
+}
+
+func newUserProfileResponse(acc *account.User) userProfileResponse {
+	return userProfileResponse{
+		Id:              *acc.Id,
+		Username:        acc.Username,
+		DisplayName:     acc.DisplayName,
+		Email:           acc.Email,
+		UnverifiedEmail: acc.UnverifiedEmail,
+	}
+}
+
+func newBusinessProfileResponse(acc *account.Business) businessProfileResponse {
+	return businessProfileResponse{
+		Id:                  *acc.Id,
+		Username:            acc.Username,
+		DisplayName:         acc.BusinessName.DisplayName,
+		DisplayNameVerified: acc.BusinessName.Verified,
+		Email:               acc.Email,
+		UnverifiedEmail:     acc.UnverifiedEmail,
+		OfficialName:        acc.BusinessIdentity.Name,
+		RegistrationNumber:  acc.BusinessIdentity.RegistrationNumber,
+		Address:             acc.BusinessIdentity.Address,
+		Documents:           acc.BusinessIdentity.Documents,
+		IdentityVerified:    acc.BusinessIdentity.Verified,
+		ParentId:            acc.ChildOf,
+	}
+}
+
+// Nil if the field is not in the form, so it can be told apart from an empty value
+func optionalField(r *http.Request, key string, present *[]string) *string {
+	v, ok := r.PostForm[key]
+	if !ok {
+		return nil
+	}
+	*present = append(*present, key)
+
+	value := strings.TrimSpace(v[0])
+	return &value
+}
+
+func (rt *Router) userProfile() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		acc, err := rt.user.UserRepo.FetchById(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newUserProfileResponse(acc))
+	}
+}
+
+// Only the fields in the form are changed
+func (rt *Router) userUpdateProfile() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		present := make([]string, 0)
+		acc, err := rt.user.UpdateProfile(r.Context(), authenticatedId(r), account.UserProfileForm{
+			DisplayName: optionalField(r, "displayName", &present),
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(present) > 0 {
+			rt.audit(r, *acc.Id, account.AuditProfileUpdated, map[string]string{"fields": strings.Join(present, ",")})
+		}
+		return writeJSON(w, newUserProfileResponse(acc))
+	}
+}
+
+func (rt *Router) businessProfile() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		acc, err := rt.business.BusinessRepo.FetchById(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newBusinessProfileResponse(acc))
+	}
+}
+
+// Only the fields in the form are changed, documents are sent as multipart form
+// and replace all existing documents
+func (rt *Router) businessUpdateProfile() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(math.Pow10(7)))
+		err := r.ParseMultipartForm(32 << 20)
+		if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		var files [][]byte
+		if r.MultipartForm != nil && len(r.MultipartForm.File["document"]) > 0 {
+			files, err = readDocuments(r)
+			if err != nil {
+				return err
+			}
+		}
+
+		present := make([]string, 0)
+		acc, err := rt.business.UpdateProfile(r.Context(), authenticatedId(r), account.BusinessProfileForm{
+			DisplayName:        optionalField(r, "displayName", &present),
+			OfficialName:       optionalField(r, "businessName", &present),
+			RegistrationNumber: optionalField(r, "registrationNumber", &present),
+			Address:            optionalField(r, "address", &present),
+			Documents:          files,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(present) > 0 {
+			rt.audit(r, *acc.Id, account.AuditProfileUpdated, map[string]string{"fields": strings.Join(present, ",")})
+		}
+		if files != nil {
+			rt.audit(r, *acc.Id, account.AuditDocumentsChanged, map[string]string{"count": strconv.Itoa(len(files))})
+		}
+		return writeJSON(w, newBusinessProfileResponse(acc))
+	}
+}
@@ -0,0 +1,207 @@
+package accountrouter
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type transferIdResponse struct {
+	Chain    string `json:"chain"`
+	Version  uint64 `json:"version"`
+	Index    int    `json:"index"`
+	Transfer int    `json:"transfer"`
+}
+
+func newTransferIdResponse(v account.TransferId) transferIdResponse {
+	return transferIdResponse{
+		Chain:    v.Chain,
+		Version:  v.Version,
+		Index:    v.Index,
+		Transfer: v.Transfer,
+	}
+}
+
+type refundResponse struct {
+	transferIdResponse
+	Original   transferIdResponse `json:"original"`
+	BusinessId int                `json:"businessId"`
+	Recipient  string             `json:"recipient"`
+	Currency   string             `json:"currency"`
+	Amount     string             `json:"amount"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+func newRefundResponses(refunds []account.Refund) []refundResponse {
+	list := make([]refundResponse, len(refunds))
+	for i, v := range refunds {
+		list[i] = refundResponse{
+			transferIdResponse: newTransferIdResponse(v.TransferId),
+			Original:           newTransferIdResponse(v.Original),
+			BusinessId:         v.BusinessId,
+			Recipient:          v.Recipient,
+			Currency:           v.Currency,
+			Amount:             v.Amount.String(),
+			CreatedAt:          v.CreatedAt,
+		}
+	}
+	return list
+}
+
+type refundSummaryResponse struct {
+	Original transferIdResponse `json:"original"`
+	Currency string             `json:"currency"`
+	Amount   string             `json:"amount"`
+	Refunded string             `json:"refunded"`
+	Full     bool               `json:"full"`
+	Refunds  []refundResponse   `json:"refunds"`
+}
+
+func newRefundSummaryResponse(v *account.RefundSummary) refundSummaryResponse {
+	return refundSummaryResponse{
+		Original: newTransferIdResponse(v.Original),
+		Currency: v.Currency,
+		Amount:   v.Amount.String(),
+		Refunded: v.Refunded.String(),
+		Full:     v.Full(),
+		Refunds:  newRefundResponses(v.Refunds),
+	}
+}
+
+// Read the transfer from the fields with the prefix, e.g. originalVersion
+func transferIdField(values url.Values, chain, prefix string) (account.TransferId, bool) {
+	get := func(key string) string {
+		if prefix != "" {
+			key = prefix + strings.ToUpper(key[:1]) + key[1:]
+		}
+		return values.Get(key)
+	}
+
+	version, err := strconv.ParseUint(get("version"), 10, 64)
+	if err != nil {
+		return account.TransferId{}, false
+	}
+	// index and transfer are 0 for Diem
+	index, _ := strconv.Atoi(get("index"))
+	transfer, _ := strconv.Atoi(get("transfer"))
+
+	return account.TransferId{
+		TransactionId: wallet.TransactionId{Version: version, Chain: chain, Index: index},
+		Transfer:      transfer,
+	}, true
+}
+
+// Routes for businesses to mark and list their refunds
+func (rt *Router) refundHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/", errorHandler(rt.refundIssued()))
+	r.With(rt.businessOrKeyAuthenticated(account.ScopePayouts)).Post("/", errorHandler(rt.refundMark()))
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/summary", errorHandler(rt.refundSummary()))
+	return r
+}
+
+func (rt *Router) refundIssued() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		refunds, err := rt.refunds.Issued(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newRefundResponses(refunds))
+	}
+}
+
+// The refund is sent as version, index and transfer, the payment as originalVersion, originalIndex and originalTransfer
+func (rt *Router) refundMark() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		chain := r.PostForm.Get("chain")
+		refundId, ok := transferIdField(r.PostForm, chain, "")
+		if !ok {
+			return account.ErrRefundTransaction(r.Context())
+		}
+		originalId, ok := transferIdField(r.PostForm, chain, "original")
+		if !ok {
+			return account.ErrRefundTransaction(r.Context())
+		}
+
+		summary, err := rt.refunds.Mark(r.Context(), authenticatedId(r), refundId, originalId)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newRefundSummaryResponse(summary))
+	}
+}
+
+// The payment is sent as chain, version, index and transfer in the query
+func (rt *Router) refundSummary() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+		originalId, ok := transferIdField(query, query.Get("chain"), "")
+		if !ok {
+			return account.ErrRefundTransaction(r.Context())
+		}
+
+		summary, err := rt.refunds.Summary(r.Context(), authenticatedId(r), originalId)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newRefundSummaryResponse(summary))
+	}
+}
+
+// Refunds sent to the wallets of the user
+func (rt *Router) refundReceived() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		refunds, err := rt.refunds.Received(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newRefundResponses(refunds))
+	}
+}
+
+// Fill the refund links of the transfers
+func (rt *Router) linkRefunds(ctx context.Context, list []transferResponse) error {
+	if rt.refunds == nil {
+		return nil
+	}
+
+	ids := make([]account.TransferId, len(list))
+	for i, v := range list {
+		ids[i] = account.TransferId{
+			TransactionId: wallet.TransactionId{Version: v.Version, Chain: v.Chain, Index: v.Index},
+			Transfer:      v.LogIndex,
+		}
+	}
+
+	refunds, err := rt.refunds.Linked(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	for _, refund := range refunds {
+		for i, id := range ids {
+			if id == refund.TransferId {
+				original := newTransferIdResponse(refund.Original)
+				list[i].RefundOf = &original
+			}
+			if id == refund.Original {
+				list[i].RefundedBy = append(list[i].RefundedBy, newTransferIdResponse(refund.TransferId))
+			}
+		}
+	}
+	return nil
+}
@@ -18,6 +18,23 @@ type Router struct {
 	business 		 account.BusinessCreator
 	businessProvider session.SharedProvider
 	businessRecovery account.BusinessAccountRecoveryHelper
+	wallets			 *account.WalletLinker
+	staff			 account.StaffRepository
+	review			 *account.BusinessReviewer
+	hierarchy		 *account.BusinessHierarchy
+	twoFactor		 *account.TwoFactor
+	pendingLogin	 session.UniqueProvider
+	passkeys		 *account.PasskeyAuthenticator
+	auditLog		 *account.AuditLog
+	apiKeys			 *account.APIKeys
+	webhooks		 *account.Webhooks
+	invoices		 *account.Invoices
+	refunds			 *account.Refunds
+	history			 *account.TransactionHistory
+	export			 *account.TransactionExport
+	balances		 *account.Balances
+	indexer			 *account.Indexer
+	openSignup		 bool
 }
 
 func New(
@@ -38,6 +55,100 @@ func New(
 	}
 }
 
+// Enable the wallet routes for users and businesses
+func (rt *Router) WithWallets(linker account.WalletLinker) *Router {
+	rt.wallets = &linker
+	return rt
+}
+
+// Enable the business review routes in StaffHandler
+func (rt *Router) WithBusinessReview(staff account.StaffRepository, reviewer account.BusinessReviewer) *Router {
+	rt.staff = staff
+	rt.review = &reviewer
+	return rt
+}
+
+// Enable the child account routes for businesses
+func (rt *Router) WithBusinessHierarchy(hierarchy account.BusinessHierarchy) *Router {
+	rt.hierarchy = &hierarchy
+	return rt
+}
+
+// Enable two-factor authentication, login of enrolled accounts is completed
+// with a code after the pending login session is created
+func (rt *Router) WithTwoFactor(twoFactor account.TwoFactor, pendingLogin session.UniqueProvider) *Router {
+	rt.twoFactor = &twoFactor
+	rt.pendingLogin = pendingLogin
+	return rt
+}
+
+// Enable passwordless login with passkeys for users and businesses
+func (rt *Router) WithPasskeys(passkeys account.PasskeyAuthenticator) *Router {
+	rt.passkeys = &passkeys
+	return rt
+}
+
+// Record security events and enable the audit history routes
+func (rt *Router) WithAudit(log account.AuditLog) *Router {
+	rt.auditLog = &log
+	return rt
+}
+
+// Enable API keys for businesses, routes that accept them are
+// guarded by businessOrKeyAuthenticated
+func (rt *Router) WithAPIKeys(keys account.APIKeys) *Router {
+	rt.apiKeys = &keys
+	return rt
+}
+
+// Enable the webhook routes for businesses
+func (rt *Router) WithWebhooks(webhooks account.Webhooks) *Router {
+	rt.webhooks = &webhooks
+	return rt
+}
+
+// Enable the invoice routes for businesses, they accept API keys
+func (rt *Router) WithInvoices(invoices account.Invoices) *Router {
+	rt.invoices = &invoices
+	return rt
+}
+
+// Enable refund routes for businesses and users, transaction lists show the refund links
+func (rt *Router) WithRefunds(refunds account.Refunds) *Router {
+	rt.refunds = &refunds
+	return rt
+}
+
+// Enable the transaction feed of users and businesses
+func (rt *Router) WithTransactionHistory(history account.TransactionHistory) *Router {
+	rt.history = &history
+	return rt
+}
+
+// Enable the CSV, OFX and QIF statements of users and businesses
+func (rt *Router) WithTransactionExport(export account.TransactionExport) *Router {
+	rt.export = &export
+	return rt
+}
+
+// Enable the wallet balances of users and businesses
+func (rt *Router) WithBalances(balances account.Balances) *Router {
+	rt.balances = &balances
+	return rt
+}
+
+// Enable the sync lag of the wallet indexer in StaffHandler
+func (rt *Router) WithIndexer(indexer account.Indexer) *Router {
+	rt.indexer = &indexer
+	return rt
+}
+
+// Enable user signup without an invitation, users can only sign up with an invitation otherwise
+func (rt *Router) WithOpenSignup() *Router {
+	rt.openSignup = true
+	return rt
+}
+
 func (rt *Router) useDefaultMiddlewares(r *chi.Mux) {
 	r.Use(
 		mware.Localization,
@@ -60,9 +171,14 @@ func (rt *Router) UserHandler() chi.Router {
 		r.Use(httprate.LimitByIP(10, 30*time.Minute))
 
 		r.Get("/exist", errorHandler(rt.userExists()))
-		r.Post("/register", errorHandler(rt.userRegister()))
+		if rt.openSignup {
+			r.Post("/register", errorHandler(rt.userRegister()))
+		}
 		r.Post("/register-with-invitation", errorHandler(rt.userRegisterWithInvitation()))
 		r.Post("/reset-password", errorHandler(rt.userResetPassword()))
+		r.Get("/email/revert", errorHandler(revertEmailConfirm()))
+		r.Post("/email/revert", errorHandler(rt.userRevertEmail()))
+		r.Post("/unlock", errorHandler(rt.userUnlock()))
 	})
 
 	// Email rate limit
@@ -80,7 +196,6 @@ func (rt *Router) UserHandler() chi.Router {
 			}),
 		))
 
-		r.Post("/invitation", errorHandler(rt.userInvitation()))
 		r.Post("/forget-username", errorHandler(rt.userForgetUsername()))
 		r.Post("/forget-password", errorHandler(rt.userForgetPassword()))
 	})
@@ -101,9 +216,55 @@ func (rt *Router) UserHandler() chi.Router {
 		))
 
 		r.Post("/login", errorHandler(rt.userLogin()))
+		r.Post("/restore", errorHandler(rt.userRestore()))
+		if rt.twoFactor != nil {
+			r.Post("/login/totp", errorHandler(rt.userLoginTOTP()))
+		}
+		if rt.passkeys != nil {
+			r.Post("/login/passkey/begin", errorHandler(rt.passkeyBeginLogin()))
+			r.Post("/login/passkey/finish", errorHandler(rt.userLoginPasskey()))
+		}
 	})
 	
 	r.Get("/logout", errorHandler(rt.userLogout()))
+	r.With(rt.userAuthenticated).Get("/me", errorHandler(rt.userProfile()))
+	r.With(rt.userAuthenticated).Patch("/me", errorHandler(rt.userUpdateProfile()))
+	r.With(rt.userAuthenticated).Post("/delete", errorHandler(rt.userDelete()))
+	r.With(rt.userAuthenticated).Mount("/email", rt.userEmailHandler())
+	r.With(rt.userAuthenticated).Post("/change-password", errorHandler(rt.userChangePassword()))
+	r.With(rt.userAuthenticated).Mount("/invitations", rt.invitationHandler())
+
+	if rt.wallets != nil {
+		r.With(rt.userAuthenticated).Mount("/wallets", rt.walletHandler())
+	}
+
+	if rt.twoFactor != nil {
+		r.With(rt.userAuthenticated).Mount("/totp", rt.totpHandler(rt.userAccountName))
+	}
+
+	if rt.passkeys != nil {
+		r.With(rt.userAuthenticated).Mount("/passkeys", rt.passkeyHandler(rt.userAccountName))
+	}
+
+	if rt.auditLog != nil {
+		r.With(rt.userAuthenticated).Get("/audit", errorHandler(rt.auditHistory()))
+	}
+
+	if rt.refunds != nil {
+		r.With(rt.userAuthenticated).Get("/refunds", errorHandler(rt.refundReceived()))
+	}
+
+	if rt.history != nil {
+		r.With(rt.userAuthenticated).Get("/transactions", errorHandler(rt.transactionHistory()))
+	}
+
+	if rt.export != nil {
+		r.With(rt.userAuthenticated).Get("/transactions/export", errorHandler(rt.transactionExport()))
+	}
+
+	if rt.balances != nil {
+		r.With(rt.userAuthenticated).Get("/balances", errorHandler(rt.accountBalance()))
+	}
 	return r
 }
 
@@ -120,6 +281,9 @@ func (rt *Router) BusinessHandler() chi.Router {
 		r.Post("/register", errorHandler(rt.businessRegister()))
 		r.Post("/register-with-identity", errorHandler(rt.businessRegisterWithIdentity()))
 		r.Post("/reset-password", errorHandler(rt.businessResetPassword()))
+		r.Get("/email/revert", errorHandler(revertEmailConfirm()))
+		r.Post("/email/revert", errorHandler(rt.businessRevertEmail()))
+		r.Post("/unlock", errorHandler(rt.businessUnlock()))
 	})
 
 	// Email rate limit
@@ -157,8 +321,69 @@ func (rt *Router) BusinessHandler() chi.Router {
 		))
 
 		r.Post("/login", errorHandler(rt.businessLogin()))
+		r.Post("/restore", errorHandler(rt.businessRestore()))
+		if rt.twoFactor != nil {
+			r.Post("/login/totp", errorHandler(rt.businessLoginTOTP()))
+		}
+		if rt.passkeys != nil {
+			r.Post("/login/passkey/begin", errorHandler(rt.passkeyBeginLogin()))
+			r.Post("/login/passkey/finish", errorHandler(rt.businessLoginPasskey()))
+		}
 	})
 
 	r.Get("/logout", errorHandler(rt.businessLogout()))
+	r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/me", errorHandler(rt.businessProfile()))
+	r.With(rt.businessAuthenticated).Patch("/me", errorHandler(rt.businessUpdateProfile()))
+	r.With(rt.businessAuthenticated).Post("/delete", errorHandler(rt.businessDelete()))
+	r.With(rt.businessAuthenticated).Mount("/email", rt.businessEmailHandler())
+	r.With(rt.businessAuthenticated).Post("/change-password", errorHandler(rt.businessChangePassword()))
+
+	if rt.wallets != nil {
+		r.With(rt.businessAuthenticated).Mount("/wallets", rt.walletHandler())
+	}
+
+	if rt.hierarchy != nil {
+		r.With(rt.businessAuthenticated).Mount("/children", rt.childHandler())
+	}
+
+	if rt.twoFactor != nil {
+		r.With(rt.businessAuthenticated).Mount("/totp", rt.totpHandler(rt.businessAccountName))
+	}
+
+	if rt.passkeys != nil {
+		r.With(rt.businessAuthenticated).Mount("/passkeys", rt.passkeyHandler(rt.businessAccountName))
+	}
+
+	if rt.auditLog != nil {
+		r.With(rt.businessAuthenticated).Get("/audit", errorHandler(rt.auditHistory()))
+	}
+
+	if rt.apiKeys != nil {
+		r.With(rt.businessAuthenticated).Mount("/api-keys", rt.apiKeyHandler())
+	}
+
+	if rt.webhooks != nil {
+		r.With(rt.businessAuthenticated).Mount("/webhooks", rt.webhookHandler())
+	}
+
+	if rt.invoices != nil {
+		r.Mount("/invoices", rt.invoiceHandler())
+	}
+
+	if rt.refunds != nil {
+		r.Mount("/refunds", rt.refundHandler())
+	}
+
+	if rt.history != nil {
+		r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/transactions", errorHandler(rt.transactionHistory()))
+	}
+
+	if rt.export != nil {
+		r.With(rt.businessOrKeyAuthenticated(account.ScopeRead)).Get("/transactions/export", errorHandler(rt.transactionExport()))
+	}
+
+	if rt.balances != nil {
+		r.With(rt.businessAuthenticated).Get("/balances", errorHandler(rt.accountBalance()))
+	}
 	return r
 }
\ No newline at end of file
@@ -0,0 +1,30 @@
+package accountrouter_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/account/accountrouter"
+	"github.com/stevealexrs/Go-Libra/namespace/redisns"
+	"github.com/stevealexrs/Go-Libra/session"
+)
+
+func TestUserHandlerOpenSignup(t *testing.T) {
+	store := newMemoryZHStore()
+	rt := accountrouter.New(
+		account.UserCreator{},
+		session.NewDefSharedProvider(store, redisns.UserSession),
+		account.UserAccountRecoveryHelper{},
+		account.BusinessCreator{},
+		session.NewDefSharedProvider(store, redisns.BusinessSession),
+		account.BusinessAccountRecoveryHelper{},
+	)
+
+	res := httptest.NewRecorder()
+	rt.UserHandler().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/register", nil))
+	if res.Code != http.StatusNotFound && res.Code != http.StatusMethodNotAllowed {
+		t.Errorf("signup without invitation is mounted by default, got %d", res.Code)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+	"github.com/stevealexrs/Go-Libra/namespace/reqscope"
 	"github.com/stevealexrs/Go-Libra/session"
 )
 
@@ -49,3 +50,54 @@ func (s *businessSession) Attach(w http.ResponseWriter) {
 	})
 }
 
+func (rt *Router) readBusinessSession(ctx context.Context, r *http.Request) (*businessSession, error) {
+	cookie, err := r.Cookie(cookiens.BusinessSession)
+	if errors.Is(err, http.ErrNoCookie) {
+		return nil, errors.New("business session cookie is not set")
+	} else if err != nil {
+		return nil, err
+	}
+
+	shared, err := rt.businessProvider.Read(ctx, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return rt.businessSession(shared), nil
+}
+
+// Reject the request without a valid user session, the user id is stored in request context
+func (rt *Router) userAuthenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := rt.readUserSession(r.Context(), r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(reqscope.SetAccountId(r.Context(), s.Id)))
+	})
+}
+
+// Reject the request without a valid business session, the business id is stored in request context
+func (rt *Router) businessAuthenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := rt.readBusinessSession(r.Context(), r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(reqscope.SetAccountId(r.Context(), s.Id)))
+	})
+}
+
+// Only use in handlers behind userAuthenticated or businessAuthenticated
+func authenticatedId(r *http.Request) int {
+	id, ok := reqscope.AccountId(r.Context())
+	if !ok {
+		panic("account id is not set in request context")
+	}
+	return id
+}
+
@@ -0,0 +1,116 @@
+package accountrouter_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/account/accountrouter"
+	"github.com/stevealexrs/Go-Libra/database/kv"
+	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+	"github.com/stevealexrs/Go-Libra/namespace/redisns"
+	"github.com/stevealexrs/Go-Libra/session"
+)
+
+// Sorted sets keep the order members are added in, every test session is added once
+type memoryZHStore struct {
+	sets   map[string][]kv.ZItem
+	hashes map[string]map[string]string
+}
+
+func newMemoryZHStore() *memoryZHStore {
+	return &memoryZHStore{sets: make(map[string][]kv.ZItem), hashes: make(map[string]map[string]string)}
+}
+
+func (s *memoryZHStore) ZAdd(ctx context.Context, key string, values ...kv.ZItem) error {
+	for _, v := range values {
+		s.ZRem(ctx, key, v.Member)
+		s.sets[key] = append(s.sets[key], v)
+	}
+	return nil
+}
+
+func (s *memoryZHStore) ZRangeWithScores(ctx context.Context, key string, start int, stop int) ([]kv.ZItem, error) {
+	return append([]kv.ZItem{}, s.sets[key]...), nil
+}
+
+func (s *memoryZHStore) ZRem(ctx context.Context, key string, members ...string) (int, error) {
+	removed := 0
+	for _, m := range members {
+		for i, v := range s.sets[key] {
+			if v.Member == m {
+				s.sets[key] = append(s.sets[key][:i], s.sets[key][i+1:]...)
+				removed++
+				break
+			}
+		}
+	}
+	return removed, nil
+}
+
+func (s *memoryZHStore) Delete(ctx context.Context, keys ...string) (int, error) {
+	for _, v := range keys {
+		delete(s.sets, v)
+		delete(s.hashes, v)
+	}
+	return len(keys), nil
+}
+
+func (s *memoryZHStore) HSet(ctx context.Context, key string, values ...kv.HItem) (int, error) {
+	if s.hashes[key] == nil {
+		s.hashes[key] = make(map[string]string)
+	}
+	for _, v := range values {
+		s.hashes[key][v.Key] = v.Value
+	}
+	return len(values), nil
+}
+
+func (s *memoryZHStore) HGet(ctx context.Context, key, field string) (string, error) {
+	return s.hashes[key][field], nil
+}
+
+func (s *memoryZHStore) HKeys(ctx context.Context, key string) ([]string, error) {
+	keys := make([]string, 0)
+	for k := range s.hashes[key] {
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (s *memoryZHStore) HDel(ctx context.Context, key string, fields ...string) (int, error) {
+	for _, v := range fields {
+		delete(s.hashes[key], v)
+	}
+	return len(fields), nil
+}
+
+func TestBusinessAuthenticatedUserSession(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryZHStore()
+	userProvider := session.NewDefSharedProvider(store, redisns.UserSession)
+	businessProvider := session.NewDefSharedProvider(store, redisns.BusinessSession)
+	rt := accountrouter.New(
+		account.UserCreator{},
+		userProvider,
+		account.UserAccountRecoveryHelper{},
+		account.BusinessCreator{},
+		businessProvider,
+		account.BusinessAccountRecoveryHelper{},
+	)
+
+	userSession, err := userProvider.Init(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/change-password", nil)
+	req.AddCookie(&http.Cookie{Name: cookiens.BusinessSession, Value: userSession.SessionId()})
+	res := httptest.NewRecorder()
+	rt.BusinessHandler().ServeHTTP(res, req)
+	if res.Code != http.StatusUnauthorized {
+		t.Errorf("user session on a business route got %d", res.Code)
+	}
+}
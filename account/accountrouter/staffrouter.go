@@ -0,0 +1,221 @@
+package accountrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const defaultReviewPageSize = 50
+
+type pendingReviewResponse struct {
+	BusinessId  int       `json:"businessId"`
+	Item        string    `json:"item"`
+	SubmittedAt time.Time `json:"submittedAt"`
+}
+
+type reviewDecisionResponse struct {
+	Item       string    `json:"item"`
+	Approved   bool      `json:"approved"`
+	Reason     string    `json:"reason"`
+	ReviewerId int       `json:"reviewerId"`
+	Time       time.Time `json:"time"`
+}
+
+type reviewBusinessResponse struct {
+	Id                  int                      `json:"id"`
+	Username            string                   `json:"username"`
+	DisplayName         string                   `json:"displayName"`
+	DisplayNameVerified bool                     `json:"displayNameVerified"`
+	OfficialName        string                   `json:"officialName"`
+	RegistrationNumber  string                   `json:"registrationNumber"`
+	Address             string                   `json:"address"`
+	Documents           []string                 `json:"documents"`
+	IdentityVerified    bool                     `json:"identityVerified"`
+	Decisions           []reviewDecisionResponse `json:"decisions"`
+}
+
+// Reject the request if the user is not a staff, the staff id is stored in request context
+func (rt *Router) staffAuthenticated(next http.Handler) http.Handler {
+	return rt.userAuthenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isStaff, err := rt.staff.IsStaff(r.Context(), authenticatedId(r))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if !isStaff {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// Staff log in through the user routes
+func (rt *Router) StaffHandler() chi.Router {
+	r := chi.NewRouter()
+
+	rt.useDefaultMiddlewares(r)
+	r.Use(rt.staffAuthenticated)
+
+	if rt.review != nil {
+		r.Get("/reviews", errorHandler(rt.staffPendingReviews()))
+		r.Get("/reviews/businesses/{id}", errorHandler(rt.staffReviewBusiness()))
+		r.Get("/reviews/businesses/{id}/documents/{fid}", errorHandler(rt.staffReviewDocument()))
+		r.Post("/reviews/businesses/{id}/{item}", errorHandler(rt.staffReviewDecide()))
+	}
+
+	r.Get("/referrals/{id}", errorHandler(rt.staffReferralTree()))
+	r.Put("/referrals/{id}/quota", errorHandler(rt.staffSetInvitationQuota()))
+
+	if rt.auditLog != nil {
+		r.Get("/audit", errorHandler(rt.staffAuditSearch()))
+	}
+
+	if rt.indexer != nil {
+		r.Get("/indexer", errorHandler(rt.staffIndexerLag()))
+	}
+	return r
+}
+
+func (rt *Router) staffPendingReviews() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit <= 0 || limit > defaultReviewPageSize {
+			limit = defaultReviewPageSize
+		}
+
+		offset, err := strconv.Atoi(r.URL.Query().Get("offset"))
+		if err != nil || offset < 0 {
+			offset = 0
+		}
+
+		pending, err := rt.review.Pending(r.Context(), limit, offset)
+		if err != nil {
+			return err
+		}
+
+		list := make([]pendingReviewResponse, len(pending))
+		for i, v := range pending {
+			list[i] = pendingReviewResponse{
+				BusinessId:  v.BusinessId,
+				Item:        v.Item,
+				SubmittedAt: v.SubmittedAt,
+			}
+		}
+
+		res, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+		return nil
+	}
+}
+
+func (rt *Router) staffReviewBusiness() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		acc, decisions, err := rt.review.Business(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		fids, err := rt.review.ObjStore.FormatFID(acc.Documents...)
+		if err != nil {
+			return err
+		}
+
+		list := make([]reviewDecisionResponse, len(decisions))
+		for i, v := range decisions {
+			list[i] = reviewDecisionResponse{
+				Item:       v.Item,
+				Approved:   v.Approved,
+				Reason:     v.Reason,
+				ReviewerId: v.ReviewerId,
+				Time:       v.Time,
+			}
+		}
+
+		res, err := json.Marshal(reviewBusinessResponse{
+			Id:                  *acc.Id,
+			Username:            acc.Username,
+			DisplayName:         acc.DisplayName,
+			DisplayNameVerified: acc.BusinessName.Verified,
+			OfficialName:        acc.BusinessIdentity.Name,
+			RegistrationNumber:  acc.RegistrationNumber,
+			Address:             acc.Address,
+			Documents:           fids,
+			IdentityVerified:    acc.BusinessIdentity.Verified,
+			Decisions:           list,
+		})
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+		return nil
+	}
+}
+
+func (rt *Router) staffReviewDocument() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		document, err := rt.review.Document(r.Context(), id, chi.URLParam(r, "fid"))
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", http.DetectContentType(document))
+		w.Write(document)
+		return nil
+	}
+}
+
+func (rt *Router) staffReviewDecide() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return nil
+		}
+
+		err = r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		approved, err := strconv.ParseBool(r.PostForm.Get("approved"))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		return rt.review.Decide(
+			r.Context(),
+			authenticatedId(r),
+			id,
+			chi.URLParam(r, "item"),
+			approved,
+			r.PostForm.Get("reason"),
+		)
+	}
+}
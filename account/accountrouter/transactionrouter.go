@@ -0,0 +1,145 @@
+package accountrouter
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type transactionPageResponse struct {
+	Transactions []transferResponse `json:"transactions"`
+	// Pass as cursor to get the next page, empty if there is no more transaction
+	Next         string             `json:"next"`
+}
+
+func newTransactionEntryResponse(v account.TransactionEntry) transferResponse {
+	return transferResponse{
+		AccountId:     v.AccountId,
+		Chain:         v.Chain,
+		Version:       v.Version,
+		Index:         v.Index,
+		LogIndex:      v.TransferId.Transfer,
+		Hash:          v.Hash,
+		Time:          v.Time,
+		Status:        v.Status,
+		Currency:      v.Currency,
+		Amount:        v.Amount.String(),
+		From:          v.From,
+		To:            v.To,
+		SenderMessage: v.TransactionSenderRemark.Message,
+		Refund:        v.IsRefund,
+		Message:       v.TransactionAccountRemark.Message,
+	}
+}
+
+// Amounts are decimal integers and times are RFC 3339, refund is true or false
+func transactionFilter(query url.Values) (account.TransactionFilter, bool) {
+	filter := account.TransactionFilter{
+		Chain:        query.Get("chain"),
+		Currency:     query.Get("currency"),
+		Counterparty: query.Get("counterparty"),
+		Status:       query.Get("status"),
+		Remark:       query.Get("remark"),
+	}
+
+	amounts := map[string]**big.Int{"minAmount": &filter.MinAmount, "maxAmount": &filter.MaxAmount}
+	for key, dest := range amounts {
+		if v := query.Get(key); v != "" {
+			amount, ok := new(big.Int).SetString(v, 10)
+			if !ok {
+				return filter, false
+			}
+			*dest = amount
+		}
+	}
+
+	times := map[string]**time.Time{"since": &filter.Since, "until": &filter.Until}
+	for key, dest := range times {
+		if v := query.Get(key); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, false
+			}
+			*dest = &t
+		}
+	}
+
+	if v := query.Get("refund"); v != "" {
+		refund, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, false
+		}
+		filter.Refund = &refund
+	}
+	return filter, true
+}
+
+// Merged feed of the wallets of the authenticated account, paged with cursor and limit
+func (rt *Router) transactionHistory() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		filter, ok := transactionFilter(query)
+		if !ok {
+			return account.ErrTransactionFilter(r.Context())
+		}
+
+		entries, next, err := rt.history.Page(r.Context(), authenticatedId(r), filter, query.Get("cursor"), limit)
+		if err != nil {
+			return err
+		}
+
+		res := transactionPageResponse{Transactions: make([]transferResponse, len(entries)), Next: next}
+		for i, v := range entries {
+			res.Transactions[i] = newTransactionEntryResponse(v)
+		}
+
+		err = rt.linkRefunds(r.Context(), res.Transactions)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, res)
+	}
+}
+
+// Statement of the authenticated account from since and before until, both RFC 3339
+func (rt *Router) transactionExport() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		query := r.URL.Query()
+		since, err := time.Parse(time.RFC3339, query.Get("since"))
+		if err != nil {
+			return account.ErrExportRange(r.Context())
+		}
+		until, err := time.Parse(time.RFC3339, query.Get("until"))
+		if err != nil {
+			return account.ErrExportRange(r.Context())
+		}
+
+		statement, err := rt.export.Statement(r.Context(), authenticatedId(r), account.ExportForm{
+			Format: query.Get("format"),
+			Since:  since,
+			Until:  until,
+		})
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", statement.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statement.FileName()))
+
+		// the status is already sent, a failed statement is cut short so the client doesn't keep a partial file
+		err = statement.Write(r.Context(), w)
+		if err != nil {
+			log.Printf("transaction export failed: %s\n", err)
+			panic(http.ErrAbortHandler)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,258 @@
+package accountrouter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/namespace/cookiens"
+)
+
+const (
+	pendingLoginExpiration = 5 * time.Minute
+	maxTOTPAttempts = 5
+	// Keys of pending login session, user and business use different keys
+	// so the session of one cannot be completed as the other
+	pendingUserKey = "user"
+	pendingBusinessKey = "business"
+	pendingAttemptsKey = "attempts"
+)
+
+type totpEnrollResponse struct {
+	URI    string `json:"uri"`
+	Secret string `json:"secret"`
+}
+
+type loginResponse struct {
+	TOTPRequired bool `json:"totpRequired"`
+}
+
+// Start the second stage of login if the account has enabled two-factor authentication,
+// returns false if the account can be logged in directly
+func (rt *Router) beginPendingLogin(w http.ResponseWriter, r *http.Request, cookieName, key string, id int) (bool, error) {
+	if rt.twoFactor == nil {
+		return false, nil
+	}
+
+	enabled, err := rt.twoFactor.Enabled(r.Context(), id)
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	pending, err := rt.pendingLogin.Init(r.Context(), pendingLoginExpiration)
+	if err != nil {
+		return false, err
+	}
+
+	err = rt.pendingLogin.Set(r.Context(), pending.SessionId(), key, strconv.Itoa(id))
+	if err != nil {
+		return false, err
+	}
+
+	err = rt.pendingLogin.Set(r.Context(), pending.SessionId(), pendingAttemptsKey, "0")
+	if err != nil {
+		return false, err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name: cookieName,
+		Value: pending.SessionId(),
+		Expires: time.Now().Add(pendingLoginExpiration),
+		HttpOnly: true,
+	})
+	return true, writeJSON(w, loginResponse{TOTPRequired: true})
+}
+
+// Check the code of pending login, returns the account id after the pending session is destroyed,
+// the id is also returned with the error of a wrong code
+func (rt *Router) completePendingLogin(ctx context.Context, r *http.Request, cookieName, key string) (int, error) {
+	cookie, err := r.Cookie(cookieName)
+	if errors.Is(err, http.ErrNoCookie) {
+		return 0, account.ErrLoginExpired(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	pending, err := rt.pendingLogin.Read(ctx, cookie.Value, pendingLoginExpiration)
+	if err != nil {
+		return 0, account.ErrLoginExpired(ctx)
+	}
+
+	value, err := rt.pendingLogin.Get(ctx, pending.SessionId(), key)
+	if err != nil {
+		return 0, account.ErrLoginExpired(ctx)
+	}
+
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, account.ErrLoginExpired(ctx)
+	}
+
+	err = rt.twoFactor.Verify(ctx, id, r.PostForm.Get("code"))
+	var printable *account.PrintableError
+	if errors.As(err, &printable) {
+		value, err := rt.pendingLogin.Get(ctx, pending.SessionId(), pendingAttemptsKey)
+		if err != nil {
+			return 0, err
+		}
+
+		attempts, _ := strconv.Atoi(value)
+		attempts++
+		if attempts >= maxTOTPAttempts {
+			err = rt.pendingLogin.Destroy(ctx, pending.SessionId())
+		} else {
+			err = rt.pendingLogin.Set(ctx, pending.SessionId(), pendingAttemptsKey, strconv.Itoa(attempts))
+		}
+		if err != nil {
+			return 0, err
+		}
+		return id, printable
+	} else if err != nil {
+		return 0, err
+	}
+
+	return id, rt.pendingLogin.Destroy(ctx, pending.SessionId())
+}
+
+func clearCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name: name,
+		Expires: time.Unix(0, 0),
+		MaxAge: -1,
+	})
+}
+
+func (rt *Router) userLoginTOTP() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.completePendingLogin(r.Context(), r, cookiens.UserPendingLogin, pendingUserKey)
+		if err != nil {
+			if id != 0 {
+				rt.audit(r, id, account.AuditLoginFailed, map[string]string{"method": "totp"})
+			}
+			return err
+		}
+		clearCookie(w, cookiens.UserPendingLogin)
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "totp"})
+
+		shared, err := rt.userProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.userSession(shared).Attach(w)
+		return nil
+	}
+}
+
+func (rt *Router) businessLoginTOTP() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		id, err := rt.completePendingLogin(r.Context(), r, cookiens.BusinessPendingLogin, pendingBusinessKey)
+		if err != nil {
+			if id != 0 {
+				rt.audit(r, id, account.AuditLoginFailed, map[string]string{"method": "totp"})
+			}
+			return err
+		}
+		clearCookie(w, cookiens.BusinessPendingLogin)
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "totp"})
+
+		shared, err := rt.businessProvider.Init(r.Context(), id)
+		if err != nil {
+			return err
+		}
+		rt.businessSession(shared).Attach(w)
+		return nil
+	}
+}
+
+// Enrollment routes, accountName is shown in authenticator apps
+func (rt *Router) totpHandler(accountName func(ctx context.Context, id int) (string, error)) chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/enroll", errorHandler(rt.totpEnroll(accountName)))
+	r.Post("/confirm", errorHandler(rt.totpConfirm()))
+	r.Post("/disable", errorHandler(rt.totpDisable()))
+	return r
+}
+
+func (rt *Router) userAccountName(ctx context.Context, id int) (string, error) {
+	acc, err := rt.user.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return acc.Username, nil
+}
+
+func (rt *Router) businessAccountName(ctx context.Context, id int) (string, error) {
+	acc, err := rt.business.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return acc.Username, nil
+}
+
+func (rt *Router) totpEnroll(accountName func(ctx context.Context, id int) (string, error)) errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id := authenticatedId(r)
+
+		name, err := accountName(r.Context(), id)
+		if err != nil {
+			return err
+		}
+
+		uri, secret, err := rt.twoFactor.Enroll(r.Context(), id, name)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, totpEnrollResponse{URI: uri, Secret: secret})
+	}
+}
+
+func (rt *Router) totpConfirm() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.twoFactor.ConfirmEnrollment(r.Context(), authenticatedId(r), r.PostForm.Get("code"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditTOTPEnabled, nil)
+		return nil
+	}
+}
+
+func (rt *Router) totpDisable() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		err = rt.twoFactor.Disable(r.Context(), authenticatedId(r), r.PostForm.Get("code"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditTOTPDisabled, nil)
+		return nil
+	}
+}
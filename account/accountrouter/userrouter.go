@@ -55,6 +55,7 @@ func (rt *Router) userRegister() errorFunc {
 		if err != nil {
 			return err
 		}
+		rt.audit(r, id, account.AuditRegistered, nil)
 
 		shared, err := rt.userProvider.Init(r.Context(), id)
 		if err != nil {
@@ -66,18 +67,6 @@ func (rt *Router) userRegister() errorFunc {
 	}
 }
 
-func (rt *Router) userInvitation() errorFunc {
-	return func(w http.ResponseWriter, r *http.Request) error {
-		err := r.ParseForm()
-		if err != nil {
-			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-			return nil
-		}
-
-		return rt.user.CreateInvitation(r.Context(), r.PostForm.Get("email"))
-	}
-}
-
 func (rt *Router) userRegisterWithInvitation() errorFunc {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		err := r.ParseForm()
@@ -99,6 +88,7 @@ func (rt *Router) userRegisterWithInvitation() errorFunc {
 		if err != nil {
 			return err
 		}
+		rt.audit(r, id, account.AuditRegistered, nil)
 
 		shared, err := rt.userProvider.Init(r.Context(), id)
 		if err != nil {
@@ -120,9 +110,17 @@ func (rt *Router) userLogin() errorFunc {
 
 		id, err := rt.user.Login(r.Context(), r.PostForm.Get("username"), r.PostForm.Get("password"))
 		if err != nil {
+			rt.auditLoginFailed(r, rt.userIdByName(r), "password")
 			return err
 		}
 
+		pending, err := rt.beginPendingLogin(w, r, cookiens.UserPendingLogin, pendingUserKey, id)
+		if err != nil || pending {
+			return err
+		}
+
+		rt.audit(r, id, account.AuditLoginSucceeded, map[string]string{"method": "password"})
+
 		shared, err := rt.userProvider.Init(r.Context(), id)
 		if err != nil {
 			return err
@@ -164,7 +162,12 @@ func (rt *Router) userResetPassword() errorFunc {
 			return nil
 		}
 
-		return rt.userRecovery.ResetPassword(r.Context(), r.PostForm.Get("token"), r.PostForm.Get("password"))
+		id, err := rt.userRecovery.ResetPassword(r.Context(), r.PostForm.Get("token"), r.PostForm.Get("password"))
+		if err != nil {
+			return err
+		}
+		rt.audit(r, id, account.AuditPasswordReset, nil)
+		return nil
 	}
 }
 
@@ -175,6 +178,11 @@ func (rt *Router) userLogout() errorFunc {
 			return err
 		}
 
+		shared, err := rt.userProvider.Read(r.Context(), cookie.Value)
+		if err == nil {
+			rt.audit(r, shared.Id, account.AuditSessionDestroyed, map[string]string{"reason": "logout"})
+		}
+
 		err = rt.userProvider.Destroy(r.Context(), cookie.Value)
 		if err != nil {
 			return err
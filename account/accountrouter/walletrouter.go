@@ -0,0 +1,119 @@
+package accountrouter
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type walletResponse struct {
+	Chain     string `json:"chain"`
+	Address   string `json:"address"`
+	PublicKey string `json:"publicKey"`
+}
+
+// The wallet routes are shared by user and business since they have the same id space
+func (rt *Router) walletHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.walletList()))
+	r.Post("/", errorHandler(rt.walletLink()))
+	r.Post("/challenge", errorHandler(rt.walletChallenge()))
+	r.Delete("/{chain}/{address}", errorHandler(rt.walletUnlink()))
+	return r
+}
+
+func (rt *Router) walletList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		wallets, err := rt.wallets.List(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		list := make([]walletResponse, len(wallets))
+		for i, v := range wallets {
+			list[i] = walletResponse{
+				Chain:     v.Chain,
+				Address:   v.Hex,
+				PublicKey: v.PublicKey,
+			}
+		}
+
+		res, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+		return nil
+	}
+}
+
+func (rt *Router) walletChallenge() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		message, err := rt.wallets.RequestChallenge(
+			r.Context(),
+			authenticatedId(r),
+			r.PostForm.Get("chain"),
+			r.PostForm.Get("address"),
+		)
+		if err != nil {
+			return err
+		}
+
+		res, err := json.Marshal(message)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+		return nil
+	}
+}
+
+func (rt *Router) walletLink() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		signature, err := hex.DecodeString(strings.TrimPrefix(r.PostForm.Get("signature"), "0x"))
+		if err != nil {
+			return account.ErrWalletSignature(r.Context())
+		}
+
+		return rt.wallets.Link(
+			r.Context(),
+			authenticatedId(r),
+			r.PostForm.Get("chain"),
+			r.PostForm.Get("address"),
+			r.PostForm.Get("publicKey"),
+			signature,
+		)
+	}
+}
+
+func (rt *Router) walletUnlink() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		return rt.wallets.Unlink(
+			r.Context(),
+			authenticatedId(r),
+			chi.URLParam(r, "chain"),
+			chi.URLParam(r, "address"),
+		)
+	}
+}
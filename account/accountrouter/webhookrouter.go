@@ -0,0 +1,169 @@
+package accountrouter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type webhookResponse struct {
+	Id        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Only set when the endpoint is created
+	Secret    string    `json:"secret,omitempty"`
+}
+
+func newWebhookResponse(v account.WebhookEndpoint, secret string) webhookResponse {
+	return webhookResponse{
+		Id:        v.Id,
+		URL:       v.URL,
+		Events:    v.Events,
+		CreatedAt: v.CreatedAt,
+		Secret:    secret,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	Id            int64           `json:"id"`
+	EndpointId    int             `json:"endpointId"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	ResponseCode  int             `json:"responseCode"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	DeliveredAt   *time.Time      `json:"deliveredAt"`
+}
+
+func newWebhookDeliveryResponse(v account.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		Id:            v.Id,
+		EndpointId:    v.EndpointId,
+		Event:         v.Event,
+		Payload:       v.Payload,
+		Status:        v.Status,
+		Attempts:      v.Attempts,
+		ResponseCode:  v.ResponseCode,
+		NextAttemptAt: v.NextAttemptAt,
+		CreatedAt:     v.CreatedAt,
+		DeliveredAt:   v.DeliveredAt,
+	}
+}
+
+type webhookDeliveryPageResponse struct {
+	Deliveries []webhookDeliveryResponse `json:"deliveries"`
+	// Pass as before to get the next page, 0 if the page is empty
+	Next       int64                     `json:"next"`
+}
+
+// Endpoints are managed with the business session only
+func (rt *Router) webhookHandler() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", errorHandler(rt.webhookList()))
+	r.Post("/", errorHandler(rt.webhookCreate()))
+	r.Delete("/{id}", errorHandler(rt.webhookDelete()))
+	r.Get("/{id}/deliveries", errorHandler(rt.webhookDeliveries()))
+	r.Post("/deliveries/{id}/redeliver", errorHandler(rt.webhookRedeliver()))
+	return r
+}
+
+func (rt *Router) webhookList() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		endpoints, err := rt.webhooks.Endpoints(r.Context(), authenticatedId(r))
+		if err != nil {
+			return err
+		}
+
+		list := make([]webhookResponse, len(endpoints))
+		for i, v := range endpoints {
+			list[i] = newWebhookResponse(v, "")
+		}
+		return writeJSON(w, list)
+	}
+}
+
+// Events are sent as repeated event fields
+func (rt *Router) webhookCreate() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		err := r.ParseForm()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return nil
+		}
+
+		endpoint, err := rt.webhooks.CreateEndpoint(r.Context(), authenticatedId(r), r.PostForm.Get("url"), r.PostForm["event"])
+		if err != nil {
+			return err
+		}
+		rt.audit(r, endpoint.BusinessId, account.AuditWebhookCreated, map[string]string{
+			"webhook": strconv.Itoa(endpoint.Id),
+			"url":     endpoint.URL,
+			"events":  strings.Join(endpoint.Events, ","),
+		})
+		return writeJSON(w, newWebhookResponse(*endpoint, endpoint.Secret))
+	}
+}
+
+func (rt *Router) webhookDelete() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrWebhookNotExist(r.Context())
+		}
+
+		err = rt.webhooks.DeleteEndpoint(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		rt.audit(r, authenticatedId(r), account.AuditWebhookDeleted, map[string]string{"webhook": strconv.Itoa(id)})
+		return nil
+	}
+}
+
+func (rt *Router) webhookDeliveries() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			return account.ErrWebhookNotExist(r.Context())
+		}
+
+		before, limit := auditPage(r)
+		deliveries, err := rt.webhooks.Deliveries(r.Context(), authenticatedId(r), id, before, limit)
+		if err != nil {
+			return err
+		}
+
+		res := webhookDeliveryPageResponse{Deliveries: make([]webhookDeliveryResponse, len(deliveries))}
+		for i, v := range deliveries {
+			res.Deliveries[i] = newWebhookDeliveryResponse(v)
+		}
+		if len(deliveries) > 0 {
+			res.Next = deliveries[len(deliveries)-1].Id
+		}
+		return writeJSON(w, res)
+	}
+}
+
+func (rt *Router) webhookRedeliver() errorFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			return account.ErrWebhookNotExist(r.Context())
+		}
+
+		delivery, err := rt.webhooks.Redeliver(r.Context(), authenticatedId(r), id)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, newWebhookDeliveryResponse(*delivery))
+	}
+}
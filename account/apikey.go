@@ -0,0 +1,250 @@
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/random"
+)
+
+// Scopes of API keys, read is implied by the others
+const (
+	ScopeRead     = "read"
+	ScopeInvoices = "invoices"
+	ScopePayouts  = "payouts"
+)
+
+var apiKeyScopes = []string{ScopeRead, ScopeInvoices, ScopePayouts}
+
+// Keys are shown once as apiKeyPrefix + lookup id + separator + secret
+const (
+	apiKeyPrefix    = "lbk_"
+	apiKeySeparator = "_"
+	// Last use is only written again after the interval to save writes
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKey struct {
+	Id         int
+	BusinessId int
+	Name       string
+	// Public part of the key used to look it up
+	LookupId   string
+	// SHA-256 of the secret part, the key is random so a slow hash is not needed
+	SecretHash []byte
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	if scope == ScopeRead {
+		return true
+	}
+
+	for _, v := range k.Scopes {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyRepository interface {
+	// Id of the key is set after it is stored
+	Store(ctx context.Context, key *APIKey) error
+	FetchByLookupId(ctx context.Context, lookupId string) (*APIKey, error)
+	// Keys that are not revoked, latest first
+	FetchByBusiness(ctx context.Context, businessId int) ([]APIKey, error)
+	Update(ctx context.Context, key *APIKey) error
+	TouchLastUsed(ctx context.Context, id int, at time.Time) error
+}
+
+// Server-to-server access of businesses with bearer keys
+type APIKeys struct {
+	APIKeyRepo APIKeyRepository
+	// Keys a business can have at once
+	MaxKeys    int
+}
+
+func hashAPIKeySecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+func newAPIKeySecret() (lookupId, secret, serialized string, err error) {
+	lookupId, err = random.Token16Byte()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	secret, err = random.Token20Byte()
+	if err != nil {
+		return "", "", "", err
+	}
+	return lookupId, secret, apiKeyPrefix + lookupId + apiKeySeparator + secret, nil
+}
+
+// Unknown and duplicate scopes are rejected
+func (a *APIKeys) checkScopes(ctx context.Context, scopes []string) error {
+	if len(scopes) == 0 {
+		return ErrAPIKeyScope(ctx)
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range scopes {
+		valid := false
+		for _, s := range apiKeyScopes {
+			if v == s {
+				valid = true
+			}
+		}
+		if !valid || seen[v] {
+			return ErrAPIKeyScope(ctx)
+		}
+		seen[v] = true
+	}
+	return nil
+}
+
+// Returns the key that is only shown once
+func (a *APIKeys) Create(ctx context.Context, businessId int, name string, scopes []string) (string, *APIKey, error) {
+	err := a.checkScopes(ctx, scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys, err := a.APIKeyRepo.FetchByBusiness(ctx, businessId)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) >= a.MaxKeys {
+		return "", nil, ErrAPIKeyLimit(ctx, a.MaxKeys)
+	}
+
+	lookupId, secret, serialized, err := newAPIKeySecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key := &APIKey{
+		BusinessId: businessId,
+		Name:       name,
+		LookupId:   lookupId,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     scopes,
+		CreatedAt:  time.Now(),
+	}
+	err = a.APIKeyRepo.Store(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return serialized, key, nil
+}
+
+func (a *APIKeys) List(ctx context.Context, businessId int) ([]APIKey, error) {
+	return a.APIKeyRepo.FetchByBusiness(ctx, businessId)
+}
+
+// Only the business that owns the key can manage it
+func (a *APIKeys) fetchOwned(ctx context.Context, businessId, id int) (*APIKey, error) {
+	keys, err := a.APIKeyRepo.FetchByBusiness(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range keys {
+		if v.Id == id {
+			return &v, nil
+		}
+	}
+	return nil, ErrAPIKeyNotExist(ctx)
+}
+
+// Nil scopes are left unchanged
+func (a *APIKeys) Update(ctx context.Context, businessId, id int, name *string, scopes []string) (*APIKey, error) {
+	key, err := a.fetchOwned(ctx, businessId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		key.Name = *name
+	}
+	if scopes != nil {
+		err = a.checkScopes(ctx, scopes)
+		if err != nil {
+			return nil, err
+		}
+		key.Scopes = scopes
+	}
+	return key, a.APIKeyRepo.Update(ctx, key)
+}
+
+// Replace the key while keeping its name and scopes, the old key stops working immediately
+func (a *APIKeys) Rotate(ctx context.Context, businessId, id int) (string, *APIKey, error) {
+	key, err := a.fetchOwned(ctx, businessId, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lookupId, secret, serialized, err := newAPIKeySecret()
+	if err != nil {
+		return "", nil, err
+	}
+
+	key.LookupId = lookupId
+	key.SecretHash = hashAPIKeySecret(secret)
+	key.LastUsedAt = nil
+	return serialized, key, a.APIKeyRepo.Update(ctx, key)
+}
+
+func (a *APIKeys) Revoke(ctx context.Context, businessId, id int) error {
+	key, err := a.fetchOwned(ctx, businessId, id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	return a.APIKeyRepo.Update(ctx, key)
+}
+
+// Resolve the bearer key, the business must be checked by the caller
+func (a *APIKeys) Authenticate(ctx context.Context, serialized string) (*APIKey, error) {
+	str := strings.TrimPrefix(serialized, apiKeyPrefix)
+	parts := strings.Split(str, apiKeySeparator)
+	if len(str) == len(serialized) || len(parts) != 2 {
+		return nil, ErrAPIKeyInvalid(ctx)
+	}
+
+	key, err := a.APIKeyRepo.FetchByLookupId(ctx, parts[0])
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrAPIKeyInvalid(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if key.Revoked() || subtle.ConstantTimeCompare(key.SecretHash, hashAPIKeySecret(parts[1])) != 1 {
+		return nil, ErrAPIKeyInvalid(ctx)
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		err = a.APIKeyRepo.TouchLastUsed(ctx, key.Id, now)
+		if err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
@@ -0,0 +1,100 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type memoryAPIKeyRepo struct {
+	keys []account.APIKey
+}
+
+func (r *memoryAPIKeyRepo) Store(ctx context.Context, key *account.APIKey) error {
+	key.Id = len(r.keys) + 1
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *memoryAPIKeyRepo) FetchByLookupId(ctx context.Context, lookupId string) (*account.APIKey, error) {
+	for _, v := range r.keys {
+		if v.LookupId == lookupId {
+			return &v, nil
+		}
+	}
+	return nil, account.ErrAPIKeyNotExist(ctx)
+}
+
+func (r *memoryAPIKeyRepo) FetchByBusiness(ctx context.Context, businessId int) ([]account.APIKey, error) {
+	keys := make([]account.APIKey, 0)
+	for _, v := range r.keys {
+		if v.BusinessId == businessId && !v.Revoked() {
+			keys = append(keys, v)
+		}
+	}
+	return keys, nil
+}
+
+func (r *memoryAPIKeyRepo) Update(ctx context.Context, key *account.APIKey) error {
+	r.keys[key.Id-1] = *key
+	return nil
+}
+
+func (r *memoryAPIKeyRepo) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	r.keys[id-1].LastUsedAt = &at
+	return nil
+}
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	keys := account.APIKeys{APIKeyRepo: &memoryAPIKeyRepo{}, MaxKeys: 2}
+
+	_, _, err := keys.Create(ctx, 1, "bad", []string{"admin"})
+	if err == nil {
+		t.Error("unknown scope is accepted")
+	}
+
+	serialized, key, err := keys.Create(ctx, 1, "shop", []string{account.ScopeInvoices})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := keys.Authenticate(ctx, serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.BusinessId != 1 || !auth.HasScope(account.ScopeRead) || auth.HasScope(account.ScopePayouts) {
+		t.Errorf("unexpected key %+v", auth)
+	}
+
+	_, err = keys.Authenticate(ctx, serialized+"0")
+	if err == nil {
+		t.Error("key with wrong secret is accepted")
+	}
+
+	_, _, err = keys.Rotate(ctx, 2, key.Id)
+	if err == nil {
+		t.Error("key of another business is rotated")
+	}
+
+	rotated, _, err := keys.Rotate(ctx, 1, key.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = keys.Authenticate(ctx, serialized); err == nil {
+		t.Error("key still works after it is rotated")
+	}
+	if _, err = keys.Authenticate(ctx, rotated); err != nil {
+		t.Errorf("rotated key does not work: %s", err)
+	}
+
+	err = keys.Revoke(ctx, 1, key.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = keys.Authenticate(ctx, rotated); err == nil {
+		t.Error("key still works after it is revoked")
+	}
+}
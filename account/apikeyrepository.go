@@ -0,0 +1,110 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type APIKeyRepo struct {
+	DB *sql.DB
+}
+
+const apiKeyColumns = "Id, BusinessId, Name, LookupId, SecretHash, Scopes, CreatedAt, LastUsedAt, RevokedAt"
+
+func scanAPIKey(scan func(dest ...interface{}) error) (*APIKey, error) {
+	var v APIKey
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := scan(&v.Id, &v.BusinessId, &v.Name, &v.LookupId, &v.SecretHash, &scopes, &v.CreatedAt, &lastUsedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Scopes = strings.Split(scopes, ",")
+	if lastUsedAt.Valid {
+		v.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		v.RevokedAt = &revokedAt.Time
+	}
+	return &v, nil
+}
+
+func (r *APIKeyRepo) Store(ctx context.Context, key *APIKey) error {
+	res, err := r.DB.ExecContext(
+		ctx,
+		"INSERT INTO api_key (BusinessId, Name, LookupId, SecretHash, Scopes, CreatedAt) VALUES(?, ?, ?, ?, ?, ?);",
+		key.BusinessId,
+		key.Name,
+		key.LookupId,
+		key.SecretHash,
+		strings.Join(key.Scopes, ","),
+		key.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	key.Id = int(lastId)
+	return nil
+}
+
+func (r *APIKeyRepo) FetchByLookupId(ctx context.Context, lookupId string) (*APIKey, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_key WHERE LookupId = ? LIMIT 1;", lookupId)
+
+	key, err := scanAPIKey(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return key, err
+}
+
+func (r *APIKeyRepo) FetchByBusiness(ctx context.Context, businessId int) ([]APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_key WHERE BusinessId = ? AND RevokedAt IS NULL ORDER BY Id DESC;"
+
+	rows, err := r.DB.QueryContext(ctx, query, businessId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		v, err := scanAPIKey(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *v)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) Update(ctx context.Context, key *APIKey) error {
+	query := "UPDATE api_key SET Name = ?, LookupId = ?, SecretHash = ?, Scopes = ?, LastUsedAt = ?, RevokedAt = ? WHERE Id = ?;"
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		key.Name,
+		key.LookupId,
+		key.SecretHash,
+		strings.Join(key.Scopes, ","),
+		key.LastUsedAt,
+		key.RevokedAt,
+		key.Id,
+	)
+	return err
+}
+
+func (r *APIKeyRepo) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE api_key SET LastUsedAt = ? WHERE Id = ?;", at, id)
+	return err
+}
@@ -0,0 +1,101 @@
+package account
+
+import (
+	"context"
+	"time"
+)
+
+// Security events of accounts
+const (
+	AuditRegistered       = "account.registered"
+	AuditDeleted          = "account.deleted"
+	AuditRestored         = "account.restored"
+	AuditUnlocked         = "account.unlocked"
+	AuditLoginSucceeded   = "login.succeeded"
+	AuditLoginFailed      = "login.failed"
+	AuditPasswordReset    = "password.reset"
+	AuditPasswordChanged  = "password.changed"
+	AuditEmailRequested   = "email.requested"
+	AuditEmailVerified    = "email.verified"
+	AuditEmailReverted    = "email.reverted"
+	AuditSessionDestroyed = "session.destroyed"
+	AuditTOTPEnabled      = "totp.enabled"
+	AuditTOTPDisabled     = "totp.disabled"
+	AuditPasskeyAdded     = "passkey.added"
+	AuditPasskeyRemoved   = "passkey.removed"
+	AuditDocumentsChanged = "business.documents.changed"
+	AuditProfileUpdated   = "profile.updated"
+	AuditAPIKeyCreated    = "apikey.created"
+	AuditAPIKeyUpdated    = "apikey.updated"
+	AuditAPIKeyRotated    = "apikey.rotated"
+	AuditAPIKeyRevoked    = "apikey.revoked"
+	AuditWebhookCreated   = "webhook.created"
+	AuditWebhookDeleted   = "webhook.deleted"
+)
+
+type AuditEvent struct {
+	Id        int64
+	// Nil if the event is not tied to a known account, e.g. login of a username that does not exist
+	AccountId *int
+	// Account that caused the event if it is not the owner, e.g. the parent of a child business
+	ActorId   *int
+	Event     string
+	IP        string
+	UserAgent string
+	RequestId string
+	Metadata  map[string]string
+	Time      time.Time
+}
+
+// Events are returned from the latest, zero values are ignored
+type AuditFilter struct {
+	AccountId *int
+	Event     string
+	IP        string
+	Since     time.Time
+	Until     time.Time
+	// Id of the last event of the previous page
+	Before    int64
+	Limit     int
+}
+
+// Events can only be appended, the table rejects updates and deletes
+type AuditRepository interface {
+	Append(ctx context.Context, event AuditEvent) error
+	Fetch(ctx context.Context, filter AuditFilter) ([]AuditEvent, error)
+}
+
+type AuditLog struct {
+	AuditRepo AuditRepository
+	// Largest page of a query
+	MaxLimit  int
+}
+
+func (l *AuditLog) Record(ctx context.Context, event AuditEvent) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	return l.AuditRepo.Append(ctx, event)
+}
+
+func (l *AuditLog) limit(limit int) int {
+	if limit <= 0 || limit > l.MaxLimit {
+		return l.MaxLimit
+	}
+	return limit
+}
+
+// Events of the account owner
+func (l *AuditLog) History(ctx context.Context, accountId int, before int64, limit int) ([]AuditEvent, error) {
+	return l.AuditRepo.Fetch(ctx, AuditFilter{
+		AccountId: &accountId,
+		Before:    before,
+		Limit:     l.limit(limit),
+	})
+}
+
+// Search events of any account for staff
+func (l *AuditLog) Search(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	filter.Limit = l.limit(filter.Limit)
+	return l.AuditRepo.Fetch(ctx, filter)
+}
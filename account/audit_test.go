@@ -0,0 +1,55 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type memoryAuditRepo struct {
+	events  []account.AuditEvent
+	filters []account.AuditFilter
+}
+
+func (r *memoryAuditRepo) Append(ctx context.Context, event account.AuditEvent) error {
+	event.Id = int64(len(r.events) + 1)
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *memoryAuditRepo) Fetch(ctx context.Context, filter account.AuditFilter) ([]account.AuditEvent, error) {
+	r.filters = append(r.filters, filter)
+	return nil, nil
+}
+
+func TestAuditLog(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryAuditRepo{}
+	log := account.AuditLog{AuditRepo: repo, MaxLimit: 50}
+
+	id := 7
+	err := log.Record(ctx, account.AuditEvent{AccountId: &id, Event: account.AuditLoginSucceeded})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.events[0].Time.IsZero() {
+		t.Error("time of the event is not set")
+	}
+
+	_, err = log.History(ctx, id, 0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := repo.filters[0]; f.Limit != 50 || f.AccountId == nil || *f.AccountId != id {
+		t.Errorf("unexpected history filter %+v", f)
+	}
+
+	_, err = log.Search(ctx, account.AuditFilter{Event: account.AuditLoginFailed, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := repo.filters[1]; f.Limit != 10 || f.AccountId != nil || f.Event != account.AuditLoginFailed {
+		t.Errorf("unexpected search filter %+v", f)
+	}
+}
@@ -0,0 +1,114 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const maxUserAgentLength = 255
+
+type AuditRepo struct {
+	DB *sql.DB
+}
+
+func (r *AuditRepo) Append(ctx context.Context, event AuditEvent) error {
+	var metadata []byte
+	if len(event.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(event.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	userAgent := []rune(event.UserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	query := "INSERT INTO audit_log (AccountId, ActorId, Event, IP, UserAgent, RequestId, Metadata, CreatedAt) " +
+			 "VALUES(?, ?, ?, ?, ?, ?, ?, ?);"
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		event.AccountId,
+		event.ActorId,
+		event.Event,
+		event.IP,
+		string(userAgent),
+		event.RequestId,
+		metadata,
+		event.Time,
+	)
+	return err
+}
+
+func (r *AuditRepo) Fetch(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	query := "SELECT Id, AccountId, ActorId, Event, IP, UserAgent, RequestId, Metadata, CreatedAt FROM audit_log WHERE TRUE"
+	vars := []interface{}{}
+
+	if filter.AccountId != nil {
+		query += " AND AccountId = ?"
+		vars = append(vars, *filter.AccountId)
+	}
+	if filter.Event != "" {
+		query += " AND Event = ?"
+		vars = append(vars, filter.Event)
+	}
+	if filter.IP != "" {
+		query += " AND IP = ?"
+		vars = append(vars, filter.IP)
+	}
+	if !filter.Since.IsZero() {
+		query += " AND CreatedAt >= ?"
+		vars = append(vars, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += " AND CreatedAt < ?"
+		vars = append(vars, filter.Until)
+	}
+	if filter.Before > 0 {
+		query += " AND Id < ?"
+		vars = append(vars, filter.Before)
+	}
+
+	query += " ORDER BY Id DESC LIMIT ?;"
+	vars = append(vars, filter.Limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]AuditEvent, 0)
+	for rows.Next() {
+		var v AuditEvent
+		var accountId, actorId sql.NullInt64
+		var metadata []byte
+
+		err = rows.Scan(&v.Id, &accountId, &actorId, &v.Event, &v.IP, &v.UserAgent, &v.RequestId, &metadata, &v.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		if accountId.Valid {
+			id := int(accountId.Int64)
+			v.AccountId = &id
+		}
+		if actorId.Valid {
+			id := int(actorId.Int64)
+			v.ActorId = &id
+		}
+		if len(metadata) > 0 {
+			err = json.Unmarshal(metadata, &v.Metadata)
+			if err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, v)
+	}
+	return events, rows.Err()
+}
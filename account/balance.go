@@ -0,0 +1,168 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+	"golang.org/x/sync/errgroup"
+)
+
+// Amounts are in the smallest unit, Celo currencies are token addresses
+type WalletBalance struct {
+	wallet.Address
+	Balances map[string]*big.Int
+}
+
+type CurrencyBalance struct {
+	Chain    string
+	Currency string
+	Amount   *big.Int
+}
+
+type AccountBalance struct {
+	Wallets   []WalletBalance
+	// Sum of the wallets per chain and currency
+	Totals    []CurrencyBalance
+	QueriedAt time.Time
+}
+
+// Satisfied by redisdb.RedisCacheHandler
+type BalanceCache interface {
+	Set(ctx context.Context, key string, item interface{}, exp time.Duration) error
+	Get(ctx context.Context, key string) (interface{}, error)
+}
+
+type balanceWalletRepository interface {
+	FetchByAccount(ctx context.Context, accountId int) ([]wallet.Wallet, error)
+}
+
+// Balances of all the wallets of an account, queried from the chains and cached briefly
+type Balances struct {
+	WalletRepo balanceWalletRepository
+	DiemQuery  wallet.DiemQuery
+	CeloQuery  wallet.CeloQuery
+	// Celo tokens that are queried for every Celo wallet
+	CeloTokens []string
+	Cache      BalanceCache
+	Namespace  string
+	TTL        time.Duration
+}
+
+func (b *Balances) key(accountId int) string {
+	return fmt.Sprintf("%s:%d", b.Namespace, accountId)
+}
+
+// Balances are cached as JSON since the cache decodes items without their type
+func (b *Balances) cached(ctx context.Context, accountId int) *AccountBalance {
+	item, err := b.Cache.Get(ctx, b.key(accountId))
+	if err != nil {
+		return nil
+	}
+	s, ok := item.(string)
+	if !ok {
+		return nil
+	}
+
+	var balance AccountBalance
+	err = json.Unmarshal([]byte(s), &balance)
+	if err != nil {
+		return nil
+	}
+	return &balance
+}
+
+func (b *Balances) Account(ctx context.Context, accountId int) (*AccountBalance, error) {
+	if balance := b.cached(ctx, accountId); balance != nil {
+		return balance, nil
+	}
+
+	wallets, err := b.WalletRepo.FetchByAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	// every wallet is queried at the same time, the results keep the order of the wallets
+	balances := make([]WalletBalance, len(wallets))
+	errs, errCtx := errgroup.WithContext(ctx)
+	for i, v := range wallets {
+		i, address := i, v.Address
+		errs.Go(func() error {
+			var amounts map[string]*big.Int
+			var err error
+			switch address.Chain {
+			case blockchain.DiemChain:
+				amounts, err = b.DiemQuery.Balance(errCtx, address.Hex)
+			case blockchain.CeloChain:
+				amounts, err = b.CeloQuery.Balance(errCtx, address.Hex, b.CeloTokens...)
+			default:
+				amounts = make(map[string]*big.Int)
+			}
+			if err != nil {
+				return err
+			}
+
+			balances[i] = WalletBalance{Address: address, Balances: amounts}
+			return nil
+		})
+	}
+	err = errs.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &AccountBalance{Wallets: balances, Totals: balanceTotals(balances), QueriedAt: time.Now()}
+
+	b.store(ctx, accountId, balance)
+	return balance, nil
+}
+
+// A failed cache only costs another query of the chains
+func (b *Balances) store(ctx context.Context, accountId int, balance *AccountBalance) {
+	item, err := json.Marshal(balance)
+	if err != nil {
+		log.Printf("balance encoding failed: %s\n", err)
+		return
+	}
+
+	err = b.Cache.Set(ctx, b.key(accountId), string(item), b.TTL)
+	if err != nil {
+		log.Printf("balance caching failed: %s\n", err)
+	}
+}
+
+// Sorted by chain and currency
+func balanceTotals(wallets []WalletBalance) []CurrencyBalance {
+	type currencyKey struct {
+		chain, currency string
+	}
+
+	sums := make(map[currencyKey]*big.Int)
+	for _, w := range wallets {
+		for currency, amount := range w.Balances {
+			key := currencyKey{w.Chain, currency}
+			if _, ok := sums[key]; !ok {
+				sums[key] = new(big.Int)
+			}
+			sums[key].Add(sums[key], amount)
+		}
+	}
+
+	totals := make([]CurrencyBalance, 0, len(sums))
+	for k, v := range sums {
+		totals = append(totals, CurrencyBalance{Chain: k.chain, Currency: k.currency, Amount: v})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Chain != totals[j].Chain {
+			return totals[i].Chain < totals[j].Chain
+		}
+		return totals[i].Currency < totals[j].Currency
+	})
+	return totals
+}
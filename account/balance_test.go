@@ -0,0 +1,88 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type memoryBalanceCache map[string]interface{}
+
+func (c memoryBalanceCache) Set(ctx context.Context, key string, item interface{}, exp time.Duration) error {
+	c[key] = item
+	return nil
+}
+
+func (c memoryBalanceCache) Get(ctx context.Context, key string) (interface{}, error) {
+	item, ok := c[key]
+	if !ok {
+		return nil, errors.New("cache miss")
+	}
+	return item, nil
+}
+
+type fixedDiemQuery struct {
+	calls int32
+}
+
+func (q *fixedDiemQuery) Balance(ctx context.Context, address string) (map[string]*big.Int, error) {
+	atomic.AddInt32(&q.calls, 1)
+	return map[string]*big.Int{"XUS": big.NewInt(100)}, nil
+}
+
+type fixedCeloQuery struct{}
+
+func (q fixedCeloQuery) Balance(ctx context.Context, address string, tokenAddresses ...string) (map[string]*big.Int, error) {
+	balances := make(map[string]*big.Int)
+	for _, v := range tokenAddresses {
+		balances[v] = big.NewInt(7)
+	}
+	return balances, nil
+}
+
+func TestBalances(t *testing.T) {
+	ctx := context.Background()
+	diemQuery := &fixedDiemQuery{}
+	balances := account.Balances{
+		WalletRepo: accountWalletRepo{
+			{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "a"}},
+			{Address: wallet.Address{Chain: blockchain.CeloChain, Hex: "0xb"}},
+			{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "c"}},
+		},
+		DiemQuery:  diemQuery,
+		CeloQuery:  fixedCeloQuery{},
+		CeloTokens: []string{"0xcusd"},
+		Cache:      memoryBalanceCache{},
+		Namespace:  "balance",
+		TTL:        time.Minute,
+	}
+
+	balance, err := balances.Account(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balance.Wallets) != 3 || balance.Wallets[1].Hex != "0xb" || balance.Wallets[1].Balances["0xcusd"].Int64() != 7 {
+		t.Errorf("unexpected wallets %v", balance.Wallets)
+	}
+	if len(balance.Totals) != 2 || balance.Totals[0].Chain != blockchain.CeloChain || balance.Totals[1].Amount.Int64() != 200 {
+		t.Errorf("unexpected totals %v", balance.Totals)
+	}
+
+	cached, err := balances.Account(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diemQuery.calls != 2 {
+		t.Errorf("cached balance is queried again, %d calls", diemQuery.calls)
+	}
+	if len(cached.Totals) != 2 || cached.Totals[1].Amount.Int64() != 200 {
+		t.Errorf("unexpected cached totals %v", cached.Totals)
+	}
+}
@@ -1,6 +1,7 @@
 package account
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -26,7 +27,17 @@ func (base *Base) ComparePassword(password string) (bool, error) {
 	return true, nil
 }
 
-func (base *Base) UpdatePassword(password string) error {
+// The password must satisfy the policy
+func (base *Base) UpdatePassword(ctx context.Context, policy PasswordPolicy, password string) error {
+	err := policy.Check(ctx, PasswordCandidate{Password: password, Username: base.Username, Email: base.Email})
+	if err != nil {
+		return err
+	}
+	return base.setPassword(password)
+}
+
+// Without the policy, used to rehash a password that is already in use
+func (base *Base) setPassword(password string) error {
 	hash, err := random.GenerateHash(password)
 	if err != nil {
 		return fmt.Errorf("fail to generate hash: %v", err)
@@ -35,6 +46,11 @@ func (base *Base) UpdatePassword(password string) error {
 	return nil
 }
 
+// The hash uses an old algorithm or parameters, it should be regenerated after login
+func (base *Base) NeedsRehash() bool {
+	return random.NeedsRehash(base.PasswordHash)
+}
+
 // Email must be verified after changing
 func (base *Base) UpdateEmail(email string) {
 	base.UnverifiedEmail = email
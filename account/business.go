@@ -37,7 +37,13 @@ type BusinessAccountRepository interface {
 	HasUsername(ctx context.Context, name string) (bool, error)
 }
 
-func NewBusinessAccountWithPassword(username, displayName, password, email string, identity *BusinessIdentity) (*Business, error) {
+// The password must satisfy the policy
+func NewBusinessAccountWithPassword(ctx context.Context, policy PasswordPolicy, username, displayName, password, email string, identity *BusinessIdentity) (*Business, error) {
+	err := policy.Check(ctx, PasswordCandidate{Password: password, Username: username, Email: email})
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := random.GenerateHash(password)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,144 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type BusinessChild struct {
+	Id          int
+	Username    string
+	DisplayName string
+	Active      bool
+}
+
+type BusinessChildRepository interface {
+	FetchChildren(ctx context.Context, parentId int) ([]BusinessChild, error)
+	// Business that is not a child is always active
+	IsActive(ctx context.Context, id int) (bool, error)
+	SetActive(ctx context.Context, childId int, active bool) error
+}
+
+type AccountTransactions struct {
+	AccountId int
+	Diem      map[uint64]DiemTransaction
+	Celo      map[uint64]map[int]CeloTransaction
+}
+
+// A parent business manages its direct children, children cannot have children of their own.
+// Every method checks the child belongs to the parent so a child cannot reach its siblings or parent
+type BusinessHierarchy struct {
+	BusinessRepo BusinessAccountRepository
+	ChildRepo    BusinessChildRepository
+	WalletRepo   WalletRepository
+	TxRepo       TransactionRepository
+}
+
+func (h *BusinessHierarchy) child(ctx context.Context, parentId, childId int) (*Business, error) {
+	acc, err := h.BusinessRepo.FetchById(ctx, childId)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrChildNotExist(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if acc.ChildOf == nil || *acc.ChildOf != parentId {
+		return nil, ErrChildNotExist(ctx)
+	}
+	return acc, nil
+}
+
+func (h *BusinessHierarchy) Children(ctx context.Context, parentId int) ([]BusinessChild, error) {
+	return h.ChildRepo.FetchChildren(ctx, parentId)
+}
+
+// The sessions of the child should be destroyed by the caller
+func (h *BusinessHierarchy) SetActive(ctx context.Context, parentId, childId int, active bool) error {
+	_, err := h.child(ctx, parentId, childId)
+	if err != nil {
+		return err
+	}
+	return h.ChildRepo.SetActive(ctx, childId, active)
+}
+
+func (h *BusinessHierarchy) Wallets(ctx context.Context, parentId, childId int) ([]wallet.Wallet, error) {
+	_, err := h.child(ctx, parentId, childId)
+	if err != nil {
+		return nil, err
+	}
+	return h.WalletRepo.FetchByAccount(ctx, childId)
+}
+
+// Wallets of the parent and every child, keyed by account id
+func (h *BusinessHierarchy) AllWallets(ctx context.Context, parentId int) (map[int][]wallet.Wallet, error) {
+	children, err := h.ChildRepo.FetchChildren(ctx, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{parentId}
+	for _, v := range children {
+		ids = append(ids, v.Id)
+	}
+
+	wallets := make(map[int][]wallet.Wallet)
+	for _, id := range ids {
+		list, err := h.WalletRepo.FetchByAccount(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		wallets[id] = list
+	}
+	return wallets, nil
+}
+
+func (h *BusinessHierarchy) accountTransactions(ctx context.Context, accountId int, diemStart, celoStart uint64) (*AccountTransactions, error) {
+	diemTx, _, err := h.TxRepo.FetchDiemByAccount(ctx, accountId, diemStart)
+	if err != nil {
+		return nil, err
+	}
+
+	celoTx, _, err := h.TxRepo.FetchCeloByAccount(ctx, accountId, celoStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountTransactions{
+		AccountId: accountId,
+		Diem:      diemTx,
+		Celo:      celoTx,
+	}, nil
+}
+
+func (h *BusinessHierarchy) Transactions(ctx context.Context, parentId, childId int, diemStart, celoStart uint64) (*AccountTransactions, error) {
+	_, err := h.child(ctx, parentId, childId)
+	if err != nil {
+		return nil, err
+	}
+	return h.accountTransactions(ctx, childId, diemStart, celoStart)
+}
+
+// Transactions of the parent and every child
+func (h *BusinessHierarchy) AllTransactions(ctx context.Context, parentId int, diemStart, celoStart uint64) ([]AccountTransactions, error) {
+	children, err := h.ChildRepo.FetchChildren(ctx, parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{parentId}
+	for _, v := range children {
+		ids = append(ids, v.Id)
+	}
+
+	list := make([]AccountTransactions, 0, len(ids))
+	for _, id := range ids {
+		txs, err := h.accountTransactions(ctx, id, diemStart, celoStart)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *txs)
+	}
+	return list, nil
+}
@@ -0,0 +1,57 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+type BusinessChildRepo struct {
+	DB *sql.DB
+}
+
+func (r *BusinessChildRepo) FetchChildren(ctx context.Context, parentId int) ([]BusinessChild, error) {
+	query := "SELECT pc.ChildId, acc.Username, b.DisplayName, pc.Active " +
+			 "FROM business_parent_child AS pc " +
+			 "INNER JOIN account AS acc ON acc.Id = pc.ChildId " +
+			 "INNER JOIN business AS b ON b.Id = pc.ChildId " +
+			 "WHERE pc.ParentId = ? ORDER BY pc.ChildId;"
+
+	rows, err := r.DB.QueryContext(ctx, query, parentId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := make([]BusinessChild, 0)
+	for rows.Next() {
+		var v BusinessChild
+		var active sqltype.MyBool
+
+		err = rows.Scan(&v.Id, &v.Username, &v.DisplayName, &active)
+		if err != nil {
+			return nil, err
+		}
+		v.Active = bool(active)
+		children = append(children, v)
+	}
+	return children, rows.Err()
+}
+
+func (r *BusinessChildRepo) IsActive(ctx context.Context, id int) (bool, error) {
+	var active sqltype.MyBool
+	err := r.DB.QueryRowContext(ctx, "SELECT Active FROM business_parent_child WHERE ChildId = ? LIMIT 1;", id).Scan(&active)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return bool(active), nil
+}
+
+func (r *BusinessChildRepo) SetActive(ctx context.Context, childId int, active bool) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE business_parent_child SET Active = ? WHERE ChildId = ?;", sqltype.MyBool(active), childId)
+	return err
+}
@@ -11,9 +11,16 @@ const MaxBusinessDocuments = 5
 const MaxBusinessDocumentSize = 1000000 // 1MB
 
 type BusinessCreator struct {
-	BusinessRepo BusinessAccountRepository
-	EmailRepo    RecoveryEmailVerificationRepository
-	Ext          ExternalComm
+	BusinessRepo   BusinessAccountRepository
+	EmailRepo      RecoveryEmailVerificationRepository
+	ReviewRepo     ReviewRepository
+	ChildRepo      BusinessChildRepository
+	Deletion       AccountDeletion
+	EmailChange    EmailChange
+	Throttle       LoginThrottle
+	Policy         PasswordPolicy
+	UsernamePolicy UsernamePolicy
+	Ext            ExternalComm
 }
 
 type BusinessRegistrationForm struct {
@@ -44,7 +51,14 @@ func (c *BusinessCreator) CreateAccount(ctx context.Context, form BusinessRegist
 		return 0, ErrUsernameTaken(ctx)
 	}
 
+	err = c.UsernamePolicy.Check(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+
 	acc, err := NewBusinessAccountWithPassword(
+		ctx,
+		c.Policy,
 		form.Username,
 		form.DisplayName,
 		form.Password,
@@ -60,6 +74,11 @@ func (c *BusinessCreator) CreateAccount(ctx context.Context, form BusinessRegist
 		return 0, err
 	}
 
+	err = c.ReviewRepo.Enqueue(ctx, id, ReviewDisplayName)
+	if err != nil {
+		return 0, err
+	}
+
 	return id, c.RequestEmailVerification(ctx, *acc.Id)
 }
 
@@ -81,7 +100,14 @@ func (c *BusinessCreator) CreateAccountWithIdentity(ctx context.Context, form Bu
 		return 0, err
 	}
 
+	err = c.UsernamePolicy.Check(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+
 	acc, err := NewBusinessAccountWithPassword(
+		ctx,
+		c.Policy,
 		form.Username,
 		form.DisplayName,
 		form.Password,
@@ -97,9 +123,64 @@ func (c *BusinessCreator) CreateAccountWithIdentity(ctx context.Context, form Bu
 		return 0, err
 	}
 
+	err = c.ReviewRepo.Enqueue(ctx, id, ReviewDisplayName, ReviewIdentity)
+	if err != nil {
+		return 0, err
+	}
+
 	return id, c.RequestEmailVerification(ctx, *acc.Id)
 }
 
+// Child logs in with its own username and password, only direct children are allowed
+func (c *BusinessCreator) CreateChildAccount(ctx context.Context, parentId int, form BusinessRegistrationForm) (int, error) {
+	parent, err := c.BusinessRepo.FetchById(ctx, parentId)
+	if err != nil {
+		return 0, err
+	}
+	if parent.ChildOf != nil {
+		return 0, ErrNestedChild(ctx)
+	}
+
+	exist, err := c.UsernameExist(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+	if exist {
+		return 0, ErrUsernameTaken(ctx)
+	}
+
+	err = c.UsernamePolicy.Check(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+
+	acc, err := NewBusinessAccountWithPassword(
+		ctx,
+		c.Policy,
+		form.Username,
+		form.DisplayName,
+		form.Password,
+		form.Email,
+		nil,
+	)
+	if err != nil {
+		return 0, err
+	}
+	acc.ChildOf = &parentId
+
+	id, err := c.BusinessRepo.Store(ctx, acc, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.ReviewRepo.Enqueue(ctx, id, ReviewDisplayName)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, c.RequestEmailVerification(ctx, id)
+}
+
 func (c *BusinessCreator) RequestEmailVerification(ctx context.Context, id int) error {
 	acc, err := c.BusinessRepo.FetchById(ctx, id)
 	if err != nil {
@@ -151,34 +232,316 @@ func (c BusinessCreator) VerifyEmail(ctx context.Context, userId int, email, tok
 		return err
 	}
 
+	previous := acc.Email
 	err = acc.VerifyEmail(email)
 	if err != nil {
 		return err
 	}
 
-	return c.BusinessRepo.Update(ctx, acc, nil)
+	err = c.BusinessRepo.Update(ctx, acc, nil)
+	if err != nil {
+		return err
+	}
+
+	err = c.EmailRepo.Delete(ctx, userId, email)
+	if err != nil {
+		return err
+	}
+
+	return c.EmailChange.NotifyChanged(ctx, &acc.Base, previous)
+}
+
+// The account owner is notified, other sessions are left to the caller
+func (c *BusinessCreator) ChangePassword(ctx context.Context, id int, current, password string) error {
+	acc, err := c.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &acc.Base, current)
+	if err != nil {
+		return err
+	}
+
+	err = acc.UpdatePassword(ctx, c.Policy, password)
+	if err != nil {
+		return err
+	}
+
+	err = c.BusinessRepo.Update(ctx, acc, nil)
+	if err != nil {
+		return err
+	}
+
+	if acc.Email == "" {
+		return nil
+	}
+	return c.Ext.NotifyPasswordChanged(ctx, acc.Email, acc.Username)
+}
+
+// The new email replaces the current one after it is verified
+func (c *BusinessCreator) RequestEmailChange(ctx context.Context, id int, password, email string) error {
+	acc, err := c.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &acc.Base, password)
+	if err != nil {
+		return err
+	}
+
+	err = c.EmailChange.Request(ctx, &acc.Base, email)
+	if err != nil {
+		return err
+	}
+
+	err = c.BusinessRepo.Update(ctx, acc, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.RequestEmailVerification(ctx, id)
+}
+
+func (c *BusinessCreator) ResendEmailVerification(ctx context.Context, id int) error {
+	acc, err := c.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if acc.UnverifiedEmail == "" {
+		return ErrNoPendingEmail(ctx)
+	}
+	return c.RequestEmailVerification(ctx, id)
+}
+
+// Undo an email change with the token sent to the previous email,
+// the sessions of the account must be destroyed by the caller
+func (c *BusinessCreator) RevertEmail(ctx context.Context, serializedToken string) (int, error) {
+	id, token, err := c.EmailChange.ParseRevertToken(ctx, serializedToken)
+	if err != nil {
+		return 0, err
+	}
+
+	acc, err := c.BusinessRepo.FetchById(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrEmailRevertToken(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.EmailChange.Revert(ctx, &acc.Base, token)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, c.BusinessRepo.Update(ctx, acc, nil)
 }
 
 func (c *BusinessCreator) Login(ctx context.Context, username, password string) (int, error) {
 	business, err := c.BusinessRepo.FetchByUsername(ctx, username)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.checkPassword(ctx, &business.Base, password)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.checkActive(ctx, business)
 	if err != nil {
 		return 0, err
 	}
 
-	success, err := business.ComparePassword(password)
+	err = c.rehash(ctx, business, password)
 	if err != nil {
 		return 0, err
 	}
+	return *business.Id, nil
+}
+
+// Failed attempts are counted for the lockout
+func (c *BusinessCreator) checkPassword(ctx context.Context, acc *Base, password string) error {
+	err := c.Throttle.CheckAccount(ctx, *acc.Id)
+	if err != nil {
+		return err
+	}
 
-	if !success {
+	success, err := acc.ComparePassword(password)
+	if err != nil || !success {
+		err = c.Throttle.FailAccount(ctx, acc)
+		if err != nil {
+			return err
+		}
+		return ErrInvalidCredentials(ctx)
+	}
+	return c.Throttle.ResetAccount(ctx, *acc.Id)
+}
+
+// Upgrade the hash of the password that is just checked
+func (c *BusinessCreator) rehash(ctx context.Context, business *Business, password string) error {
+	if !business.NeedsRehash() {
+		return nil
+	}
+
+	err := business.setPassword(password)
+	if err != nil {
+		return err
+	}
+	return c.BusinessRepo.Update(ctx, business, nil)
+}
+
+// Unlock the account with the link sent after it is locked, returns the account id
+func (c *BusinessCreator) Unlock(ctx context.Context, token string) (int, error) {
+	return c.Throttle.Unlock(ctx, token)
+}
+
+func (c *BusinessCreator) checkActive(ctx context.Context, business *Business) error {
+	if business.Deleted {
+		return ErrAccountDeleted(ctx)
+	}
+
+	if business.ChildOf == nil {
+		return nil
+	}
+
+	active, err := c.ChildRepo.IsActive(ctx, *business.Id)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrAccountDeactivated(ctx)
+	}
+	return nil
+}
+
+// The sessions of the business must be destroyed by the caller
+func (c *BusinessCreator) DeleteAccount(ctx context.Context, id int, password string) error {
+	business, err := c.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &business.Base, password)
+	if err != nil {
+		return err
+	}
+	return c.Deletion.Delete(ctx, &business.Base)
+}
+
+// Restore the account deleted within the grace period, the caller must complete
+// the login like after Login
+func (c *BusinessCreator) RestoreAccount(ctx context.Context, username, password string) (int, error) {
+	business, err := c.BusinessRepo.FetchByUsername(ctx, username)
+	if errors.Is(err, errDoesNotExist) {
 		return 0, ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.checkPassword(ctx, &business.Base, password)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.Deletion.Restore(ctx, &business.Base)
+	if err != nil {
+		return 0, err
+	}
+
+	// a deactivated child stays deactivated after it is restored
+	err = c.checkActive(ctx, business)
+	if err != nil {
+		return 0, err
 	}
 	return *business.Id, nil
 }
 
+// Check the id belongs to a business that can log in, used after passwordless login
+func (c *BusinessCreator) CanLogin(ctx context.Context, id int) error {
+	business, err := c.BusinessRepo.FetchById(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return err
+	}
+	return c.checkActive(ctx, business)
+}
+
+// Nil fields are left unchanged, documents replace the existing ones when not nil
+type BusinessProfileForm struct {
+	DisplayName        *string
+	OfficialName       *string
+	RegistrationNumber *string
+	Address            *string
+	Documents          [][]byte
+}
+
+// Changed display name or identity is unverified until it is reviewed again
+func (c *BusinessCreator) UpdateProfile(ctx context.Context, id int, form BusinessProfileForm) (*Business, error) {
+	acc, err := c.BusinessRepo.FetchById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]string, 0)
+	if form.DisplayName != nil && *form.DisplayName != acc.BusinessName.DisplayName {
+		acc.BusinessName.DisplayName = *form.DisplayName
+		acc.BusinessName.Verified = false
+		items = append(items, ReviewDisplayName)
+	}
+
+	identity := &acc.BusinessIdentity
+	identityChanged := form.Documents != nil
+	for _, v := range []struct{
+		value *string
+		field *string
+	}{
+		{form.OfficialName, &identity.Name},
+		{form.RegistrationNumber, &identity.RegistrationNumber},
+		{form.Address, &identity.Address},
+	} {
+		if v.value != nil && *v.value != *v.field {
+			*v.field = *v.value
+			identityChanged = true
+		}
+	}
+	if identityChanged {
+		identity.Verified = false
+		items = append(items, ReviewIdentity)
+	}
+
+	if len(items) == 0 {
+		return acc, nil
+	}
+
+	if form.Documents != nil {
+		if len(form.Documents) > MaxBusinessDocuments {
+			return nil, ErrTooManyFiles(ctx)
+		}
+		identity.Documents = nil
+	}
+
+	err = c.BusinessRepo.Update(ctx, acc, form.Documents)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.ReviewRepo.Enqueue(ctx, id, items...)
+	if err != nil {
+		return nil, err
+	}
+	return c.BusinessRepo.FetchById(ctx, id)
+}
+
 type BusinessAccountRecoveryHelper struct {
 	BusinessRepo BusinessAccountRepository
 	RecoveryRepo RecoveryRepository
+	Policy		 PasswordPolicy
 	Ext 		 ExternalComm
 }
 
@@ -257,30 +620,30 @@ func (helper *BusinessAccountRecoveryHelper) RequestPasswordReset(ctx context.Co
 	return helper.Ext.ResetPassword(ctx, email, acc.Username, helper.serializeToken(*acc.Id, recovery.Token))
 }
 
-func (helper *BusinessAccountRecoveryHelper) ResetPassword(ctx context.Context, serializedToken, password string) error {
+func (helper *BusinessAccountRecoveryHelper) ResetPassword(ctx context.Context, serializedToken, password string) (int, error) {
 	businessId, token, err := helper.unserializeToken(ctx, serializedToken)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	
 	storedToken, err := helper.RecoveryRepo.Fetch(ctx, businessId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if storedToken != token {
-		return ErrPasswordResetToken(ctx)
+		return 0, ErrPasswordResetToken(ctx)
 	}
 
 	acc, err := helper.BusinessRepo.FetchById(ctx, businessId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	err = acc.UpdatePassword(password)
+	err = acc.UpdatePassword(ctx, helper.Policy, password)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return helper.BusinessRepo.Update(ctx, acc, nil)
+	return businessId, helper.BusinessRepo.Update(ctx, acc, nil)
 }
\ No newline at end of file
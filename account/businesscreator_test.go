@@ -0,0 +1,79 @@
+package account_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type memoryBusinessRepo struct {
+	account.BusinessAccountRepository
+	business account.Business
+}
+
+func (r *memoryBusinessRepo) FetchById(ctx context.Context, id int) (*account.Business, error) {
+	acc := r.business
+	return &acc, nil
+}
+
+func (r *memoryBusinessRepo) Update(ctx context.Context, acc *account.Business, documents [][]byte) error {
+	r.business = *acc
+	for range documents {
+		r.business.Documents = append(r.business.Documents, "doc")
+	}
+	return nil
+}
+
+type memoryReviewRepo struct {
+	account.ReviewRepository
+	queued []string
+}
+
+func (r *memoryReviewRepo) Enqueue(ctx context.Context, businessId int, items ...string) error {
+	r.queued = append(r.queued, items...)
+	return nil
+}
+
+func TestBusinessUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		form       account.BusinessProfileForm
+		queued     []string
+		nameOk     bool
+		identityOk bool
+	}{
+		{"unchanged", account.BusinessProfileForm{DisplayName: str("Shop"), Address: str("1 Road")}, nil, true, true},
+		{"display name", account.BusinessProfileForm{DisplayName: str("New Shop")}, []string{account.ReviewDisplayName}, false, true},
+		{"identity", account.BusinessProfileForm{Address: str("2 Road")}, []string{account.ReviewIdentity}, true, false},
+		{"documents", account.BusinessProfileForm{Documents: [][]byte{{1}}}, []string{account.ReviewIdentity}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := 1
+			businessRepo := &memoryBusinessRepo{business: account.Business{
+				Base:             account.Base{Id: &id, Username: "shop"},
+				BusinessName:     account.BusinessName{DisplayName: "Shop", Verified: true},
+				BusinessIdentity: account.BusinessIdentity{Address: "1 Road", Documents: []string{"old"}, Verified: true},
+			}}
+			reviewRepo := &memoryReviewRepo{}
+			creator := account.BusinessCreator{BusinessRepo: businessRepo, ReviewRepo: reviewRepo}
+
+			acc, err := creator.UpdateProfile(ctx, id, tt.form)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(reviewRepo.queued, tt.queued) {
+				t.Errorf("queued %v, want %v", reviewRepo.queued, tt.queued)
+			}
+			if acc.BusinessName.Verified != tt.nameOk || acc.BusinessIdentity.Verified != tt.identityOk {
+				t.Errorf("verified name %t identity %t", acc.BusinessName.Verified, acc.BusinessIdentity.Verified)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/object"
+)
+
+type DeletionRepository interface {
+	MarkDeleted(ctx context.Context, id int, at time.Time) error
+	Restore(ctx context.Context, id int) error
+	// Zero time if the account is not deleted
+	FetchDeletedAt(ctx context.Context, id int) (time.Time, error)
+	// Deleted accounts that are not purged yet and deleted before the time
+	FetchExpired(ctx context.Context, before time.Time, limit int) ([]int, error)
+	// File ids of the business documents
+	FetchDocuments(ctx context.Context, id int) ([]string, error)
+	// Remove personal data and mark the account purged
+	Anonymise(ctx context.Context, id int) error
+}
+
+// Deleted accounts cannot log in and can be restored during the grace period
+type AccountDeletion struct {
+	DeletionRepo DeletionRepository
+	GracePeriod  time.Duration
+}
+
+// The password must be checked by the caller, who must also destroy the sessions of the account
+func (d *AccountDeletion) Delete(ctx context.Context, acc *Base) error {
+	if acc.Deleted {
+		return ErrAccountDeleted(ctx)
+	}
+
+	acc.Deleted = true
+	return d.DeletionRepo.MarkDeleted(ctx, *acc.Id, time.Now())
+}
+
+// The password must be checked by the caller, an account that is not deleted cannot be restored
+func (d *AccountDeletion) Restore(ctx context.Context, acc *Base) error {
+	if !acc.Deleted {
+		return ErrAccountNotExist(ctx)
+	}
+
+	deletedAt, err := d.DeletionRepo.FetchDeletedAt(ctx, *acc.Id)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().After(deletedAt.Add(d.GracePeriod)) {
+		return ErrAccountNotExist(ctx)
+	}
+
+	acc.Deleted = false
+	return d.DeletionRepo.Restore(ctx, *acc.Id)
+}
+
+// Anonymise accounts after the grace period and delete their documents
+type AccountPurger struct {
+	DeletionRepo DeletionRepository
+	ObjStore     object.Deleter
+	GracePeriod  time.Duration
+	// Number of accounts purged in one run
+	BatchSize    int
+}
+
+func (p *AccountPurger) PurgeExpired(ctx context.Context) (int, error) {
+	ids, err := p.DeletionRepo.FetchExpired(ctx, time.Now().Add(-p.GracePeriod), p.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, id := range ids {
+		fids, err := p.DeletionRepo.FetchDocuments(ctx, id)
+		if err != nil {
+			return i, err
+		}
+
+		err = p.DeletionRepo.Anonymise(ctx, id)
+		if err != nil {
+			return i, err
+		}
+
+		if len(fids) == 0 {
+			continue
+		}
+
+		err = p.ObjStore.Delete(ctx, fids...)
+		if err != nil {
+			// ALERT log
+			log.Printf("fail to delete documents %v of purged account %d: %s", fids, id, err)
+		}
+	}
+	return len(ids), nil
+}
+
+// Purge on every interval until the context is cancelled
+func (p *AccountPurger) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.PurgeExpired(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("account purge failed: %s", err)
+		} else if n > 0 {
+			log.Printf("purged %d accounts", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+)
+
+type DeletionRepo struct {
+	DB *sql.DB
+}
+
+func (r *DeletionRepo) MarkDeleted(ctx context.Context, id int, at time.Time) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE account SET Deleted = b'1', DeletedAt = ? WHERE Id = ?;", at, id)
+	return err
+}
+
+func (r *DeletionRepo) Restore(ctx context.Context, id int) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE account SET Deleted = b'0', DeletedAt = NULL WHERE Id = ? AND Purged = b'0';", id)
+	return err
+}
+
+func (r *DeletionRepo) FetchDeletedAt(ctx context.Context, id int) (time.Time, error) {
+	var deletedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, "SELECT DeletedAt FROM account WHERE Id = ? LIMIT 1;", id).Scan(&deletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, errDoesNotExist
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return deletedAt.Time, nil
+}
+
+func (r *DeletionRepo) FetchExpired(ctx context.Context, before time.Time, limit int) ([]int, error) {
+	query := "SELECT Id FROM account WHERE Deleted = b'1' AND Purged = b'0' AND DeletedAt < ? " +
+			 "ORDER BY DeletedAt LIMIT ?;"
+
+	rows, err := r.DB.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *DeletionRepo) FetchDocuments(ctx context.Context, id int) ([]string, error) {
+	var documents string
+	err := r.DB.QueryRowContext(ctx, "SELECT Documents FROM business_identity WHERE Id = ? LIMIT 1;", id).Scan(&documents)
+	if errors.Is(err, sql.ErrNoRows) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return unserializeFileId(documents), nil
+}
+
+// The username is replaced since it is unique, transactions are kept for bookkeeping
+func (r *DeletionRepo) Anonymise(ctx context.Context, id int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		"UPDATE account SET Username = ?, UsernameCanonical = ?, PasswordHash = '', RecoveryEmail = '', UnverifiedRecoveryEmail = '', " +
+		"TotpSecret = NULL, TotpEnabled = b'0', Purged = b'1' WHERE Id = ?;",
+		"UPDATE user SET DisplayName = '', InvitationEmail = '' WHERE Id = ?;",
+		"UPDATE business SET DisplayName = '', DisplayNameVerified = b'0' WHERE Id = ?;",
+		"UPDATE business_identity SET BusinessOfficialName = '', BusinessRegistrationNumber = '', BusinessAddress = '', Documents = '' WHERE Id = ?;",
+		"DELETE FROM wallet WHERE AccountId = ?;",
+		"DELETE FROM passkey WHERE AccountId = ?;",
+		"DELETE FROM api_key WHERE BusinessId = ?;",
+		"DELETE FROM webhook_endpoint WHERE BusinessId = ?;",
+	}
+
+	for i, v := range statements {
+		args := []interface{}{id}
+		if i == 0 {
+			name := "deleted~" + strconv.Itoa(id)
+			args = []interface{}{name, CanonicalUsername(name), id}
+		}
+
+		_, err = tx.ExecContext(ctx, v, args...)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
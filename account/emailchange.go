@@ -0,0 +1,119 @@
+package account
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/random"
+)
+
+// Previous email of an account, the owner can undo the change with the token
+type EmailRevert struct {
+	AccountId int
+	Email     string
+	Token     string
+}
+
+func NewEmailRevert(accountId int, email string) (*EmailRevert, error) {
+	token, err := random.Token20Byte()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &EmailRevert{
+		AccountId: accountId,
+		Email:     email,
+		Token:     token,
+	}
+	return obj, nil
+}
+
+type EmailRevertRepository interface {
+	Store(ctx context.Context, revert *EmailRevert) error
+	// Previous email of the account
+	Fetch(ctx context.Context, accountId int, token string) (string, error)
+	Delete(ctx context.Context, accountId int, token string) error
+}
+
+// Change the email of a logged in account,
+// the previous address is notified and can revert the change
+type EmailChange struct {
+	RevertRepo EmailRevertRepository
+	// Page that confirms the revert, the token is added as the token query parameter
+	RevertURL  string
+	Ext        ExternalComm
+}
+
+func (c *EmailChange) separator() string {
+	return "~"
+}
+
+func (c *EmailChange) serializeToken(id int, token string) string {
+	return strconv.Itoa(id) + c.separator() + token
+}
+
+func (c *EmailChange) revertLink(id int, token string) string {
+	return c.RevertURL + "?" + url.Values{"token": {c.serializeToken(id, token)}}.Encode()
+}
+
+// Account id of the revert token
+func (c *EmailChange) ParseRevertToken(ctx context.Context, serialized string) (id int, token string, err error) {
+	str := strings.Split(serialized, c.separator())
+	if len(str) != 2 {
+		return 0, "", ErrEmailRevertToken(ctx)
+	}
+
+	id, err = strconv.Atoi(str[0])
+	if err != nil {
+		return 0, "", ErrEmailRevertToken(ctx)
+	}
+	return id, str[1], nil
+}
+
+// The new email must be verified before it replaces the current one, the password must be checked by the caller
+func (c *EmailChange) Request(ctx context.Context, acc *Base, email string) error {
+	if email == "" || email == acc.Email {
+		return ErrEmailUnchanged(ctx)
+	}
+
+	acc.UpdateEmail(email)
+	return nil
+}
+
+// Send the revert link to the previous email
+func (c *EmailChange) NotifyChanged(ctx context.Context, acc *Base, previous string) error {
+	if previous == "" || previous == acc.Email {
+		return nil
+	}
+
+	revert, err := NewEmailRevert(*acc.Id, previous)
+	if err != nil {
+		return err
+	}
+
+	err = c.RevertRepo.Store(ctx, revert)
+	if err != nil {
+		return err
+	}
+
+	return c.Ext.NotifyEmailChanged(ctx, previous, acc.Username, acc.Email, c.revertLink(*acc.Id, revert.Token))
+}
+
+// Restore the previous email, pending changes are discarded
+func (c *EmailChange) Revert(ctx context.Context, acc *Base, token string) error {
+	previous, err := c.RevertRepo.Fetch(ctx, *acc.Id, token)
+	if err != nil {
+		return ErrEmailRevertToken(ctx)
+	}
+
+	err = c.RevertRepo.Delete(ctx, *acc.Id, token)
+	if err != nil {
+		return err
+	}
+
+	acc.Email = previous
+	acc.UnverifiedEmail = ""
+	return nil
+}
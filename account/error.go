@@ -3,6 +3,8 @@ package account
 import (
 	"context"
 	"errors"
+	"math"
+	"time"
 
 	"github.com/stevealexrs/Go-Libra/fmtext"
 	"github.com/stevealexrs/Go-Libra/namespace/reqscope"
@@ -10,6 +12,7 @@ import (
 )
 
 var errDoesNotExist = errors.New("item does not exist")
+var errAlreadyExist = errors.New("item already exists")
 
 type PrintableError struct {
 	Message string
@@ -74,5 +77,310 @@ func ErrInvalidCredentials(ctx context.Context) *PrintableError {
 	return &PrintableError{p.Sprintf("Invalid username or password")}
 }
 
+func ErrUnsupportedChain(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The blockchain is not supported")}
+}
+
+func ErrInvalidWalletAddress(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Invalid wallet address")}
+}
+
+func ErrWalletChallenge(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The wallet challenge has expired, please request a new one")}
+}
+
+func ErrWalletSignature(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Invalid wallet signature")}
+}
+
+func ErrWalletTaken(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The wallet is linked to another account")}
+}
+
+func ErrDocumentNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The document does not exist")}
+}
+
+func ErrReviewNotPending(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The item is not pending for review")}
+}
+
+func ErrIdentityNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The business has not submitted its identity")}
+}
+
+func ErrChildNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The child account does not exist")}
+}
+
+func ErrNestedChild(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("A child account cannot create child accounts")}
+}
+
+func ErrAccountDeactivated(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The account has been deactivated")}
+}
+
+func ErrTOTPEnabled(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Two-factor authentication is already enabled")}
+}
+
+func ErrTOTPNotEnrolled(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Two-factor authentication is not enabled")}
+}
+
+func ErrTOTPCode(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Invalid authentication code")}
+}
+
+func ErrLoginExpired(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The login has expired, please log in again")}
+}
+
+func ErrPasskeyChallenge(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The passkey request has expired, please try again")}
+}
+
+func ErrPasskeyInvalid(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The passkey could not be verified")}
+}
+
+func ErrPasskeyTaken(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The passkey is already registered")}
+}
+
+func ErrPasskeyNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The passkey does not exist")}
+}
+
+func ErrAccountDeleted(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The account has been deleted, it can be restored before the grace period ends")}
+}
+
+func ErrEmailUnchanged(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Please enter a different email")}
+}
+
+func ErrEmailRevertToken(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The link to undo the email change is invalid or has expired")}
+}
+
+func ErrNoPendingEmail(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("There is no email waiting for verification")}
+}
+
+func ErrLoginLocked(ctx context.Context, wait time.Duration) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	minutes := int(math.Ceil(wait.Minutes()))
+	return &PrintableError{p.Sprintf("Too many failed attempts, please try again in %d minutes", minutes)}
+}
+
+func ErrUnlockToken(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The unlock link is invalid or has expired")}
+}
+
+func ErrPasswordTooShort(ctx context.Context, length int) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The password must have at least %d characters", length)}
+}
+
+func ErrPasswordTooWeak(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The password is too easy to guess, try a longer phrase or fewer common patterns")}
+}
+
+func ErrPasswordPersonalInfo(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The password must not contain your username or email")}
+}
+
+func ErrPasswordBreached(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The password has appeared in a data breach, please choose another one")}
+}
+
+func ErrUsernameLength(ctx context.Context, min, max int) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The username must have %d to %d characters", min, max)}
+}
+
+func ErrUsernameCharacter(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The username can only contain letters, digits, '_', '-' and '.'")}
+}
+
+func ErrUsernameScript(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The username contains letters that are not supported")}
+}
+
+func ErrUsernameMixedScript(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The username must not mix letters of different languages")}
+}
+
+func ErrInvitationPending(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The email has already been invited")}
+}
+
+func ErrInvitationQuota(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("You have no invitations left")}
+}
+
+func ErrInvitationNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The invitation does not exist")}
+}
+
+func ErrInvitationNotPending(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The invitation has already been accepted or revoked")}
+}
+
+func ErrInvitationCooldown(ctx context.Context, wait time.Duration) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	minutes := int(math.Ceil(wait.Minutes()))
+	return &PrintableError{p.Sprintf("The invitation was sent recently, please try again in %d minutes", minutes)}
+}
+
+func ErrAPIKeyScope(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Choose at least one of the scopes read, invoices and payouts")}
+}
+
+func ErrAPIKeyLimit(ctx context.Context, max int) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("A business can have at most %d API keys", max)}
+}
+
+func ErrAPIKeyNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The API key does not exist")}
+}
+
+func ErrAPIKeyInvalid(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The API key is invalid or has been revoked")}
+}
+
+func ErrWebhookURL(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The webhook URL must be an absolute https URL")}
+}
+
+func ErrWebhookEvent(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("Choose at least one of the events transaction.received and transaction.updated")}
+}
+
+func ErrWebhookLimit(ctx context.Context, max int) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("A business can have at most %d webhook endpoints", max)}
+}
+
+func ErrWebhookNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The webhook does not exist")}
+}
+
+func ErrInvoiceCurrency(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The currency must be a Diem currency code or a Celo token address")}
+}
+
+func ErrInvoiceAmount(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The amount must be a positive whole number in the smallest unit of the currency")}
+}
 
+func ErrInvoiceMemo(ctx context.Context, max int) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The memo can have at most %d characters", max)}
+}
 
+func ErrInvoiceExpiry(ctx context.Context, max time.Duration) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	hours := int(math.Floor(max.Hours()))
+	return &PrintableError{p.Sprintf("The invoice must expire within %d hours", hours)}
+}
+
+func ErrInvoiceWallet(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The wallet is not linked to the business")}
+}
+
+func ErrInvoiceNotExist(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The invoice does not exist")}
+}
+
+func ErrRefundTransaction(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The transaction is not found, please try again after it is confirmed")}
+}
+
+func ErrRefundWallet(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The refund must be sent from a wallet of the business that received the payment")}
+}
+
+func ErrRefundMismatch(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The refund must return the same currency to the payer on the same chain")}
+}
+
+func ErrRefundExists(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The transaction is already marked as a refund")}
+}
+
+func ErrRefundExceeds(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The refunds cannot add up to more than the payment")}
+}
+
+func ErrTransactionCursor(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The cursor is invalid")}
+}
+
+func ErrTransactionFilter(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The transaction filter is invalid")}
+}
+
+func ErrExportFormat(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The export format must be csv, ofx or qif")}
+}
+
+func ErrExportRange(ctx context.Context) *PrintableError {
+	p:= message.NewPrinter(reqscope.Language(ctx))
+	return &PrintableError{p.Sprintf("The export period is invalid or too long")}
+}
@@ -9,4 +9,10 @@ type ExternalComm interface {
 	VerifyRecoveryEmail(ctx context.Context, to, otp string) error
 	RemindUsername(ctx context.Context, to string, names ...string) error
 	ResetPassword(ctx context.Context, to, username, token string) error
+	NotifyReviewDecision(ctx context.Context, to, username, item string, approved bool, reason string) error
+	// Sent to the previous email with a link that reverts the change
+	NotifyEmailChanged(ctx context.Context, to, username, newEmail, revertLink string) error
+	NotifyPasswordChanged(ctx context.Context, to, username string) error
+	// Sent when the account is locked after too many failed logins
+	NotifyAccountLocked(ctx context.Context, to, username, unlockToken string) error
 }
\ No newline at end of file
@@ -0,0 +1,142 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Invitation struct {
+	Id        int
+	InviterId int
+	Email     string
+	// Nil until the invitee registers
+	InviteeId *int
+	Revoked   bool
+	CreatedAt time.Time
+	SentAt    time.Time
+}
+
+// Waiting for the invitee to register
+func (inv *Invitation) Pending() bool {
+	return inv.InviteeId == nil && !inv.Revoked
+}
+
+// The invitee that registered with an invitation
+type Referral struct {
+	InviterId int
+	InviteeId int
+	Username  string
+	Email     string
+	CreatedAt time.Time
+}
+
+type ReferralNode struct {
+	Referral
+	Invitees []*ReferralNode
+}
+
+type InvitationRepository interface {
+	// Id of the invitation is set after it is stored. Nothing is stored and false is returned
+	// if the inviter has issued its quota, which is the default quota unless it is overridden
+	StoreWithinQuota(ctx context.Context, invitation *Invitation, defaultQuota int) (bool, error)
+	FetchById(ctx context.Context, id int) (*Invitation, error)
+	// Latest invitation of the email that is neither revoked nor accepted
+	FetchPendingByEmail(ctx context.Context, email string) (*Invitation, error)
+	// Latest invitation first
+	FetchByInviter(ctx context.Context, inviterId int) ([]Invitation, error)
+	// Invitations that are not revoked
+	CountIssued(ctx context.Context, inviterId int) (int, error)
+	Update(ctx context.Context, invitation *Invitation) error
+	FetchReferrals(ctx context.Context, inviterIds ...int) ([]Referral, error)
+	// False if the user has the default quota
+	FetchQuota(ctx context.Context, userId int) (int, bool, error)
+	StoreQuota(ctx context.Context, userId, quota int) error
+}
+
+// Invite-only signup, each user can issue a limited number of invitations
+type Invitations struct {
+	InvitationRepo InvitationRepository
+	DefaultQuota   int
+	ResendCooldown time.Duration
+}
+
+func (i *Invitations) Quota(ctx context.Context, userId int) (int, error) {
+	quota, found, err := i.InvitationRepo.FetchQuota(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return i.DefaultQuota, nil
+	}
+	return quota, nil
+}
+
+// Override the default quota of the user
+func (i *Invitations) SetQuota(ctx context.Context, userId, quota int) error {
+	if quota < 0 {
+		quota = 0
+	}
+	return i.InvitationRepo.StoreQuota(ctx, userId, quota)
+}
+
+// Number of invitations the user can still issue
+func (i *Invitations) Remaining(ctx context.Context, userId int) (int, error) {
+	quota, err := i.Quota(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	issued, err := i.InvitationRepo.CountIssued(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if issued >= quota {
+		return 0, nil
+	}
+	return quota - issued, nil
+}
+
+// Only the inviter can manage the invitation
+func (i *Invitations) fetchOwned(ctx context.Context, inviterId, invitationId int) (*Invitation, error) {
+	inv, err := i.InvitationRepo.FetchById(ctx, invitationId)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrInvitationNotExist(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if inv.InviterId != inviterId {
+		return nil, ErrInvitationNotExist(ctx)
+	}
+	return inv, nil
+}
+
+// Invitees of the user down to the depth, the root is the user
+func (i *Invitations) ReferralTree(ctx context.Context, userId, depth int) (*ReferralNode, error) {
+	root := &ReferralNode{Referral: Referral{InviteeId: userId}}
+
+	level := map[int]*ReferralNode{userId: root}
+	for d := 0; d < depth && len(level) > 0; d++ {
+		ids := make([]int, 0, len(level))
+		for id := range level {
+			ids = append(ids, id)
+		}
+
+		referrals, err := i.InvitationRepo.FetchReferrals(ctx, ids...)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make(map[int]*ReferralNode, len(referrals))
+		for _, v := range referrals {
+			node := &ReferralNode{Referral: v}
+			parent := level[v.InviterId]
+			parent.Invitees = append(parent.Invitees, node)
+			next[v.InviteeId] = node
+		}
+		level = next
+	}
+	return root, nil
+}
@@ -0,0 +1,78 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+type memoryInvitationRepo struct {
+	account.InvitationRepository
+	referrals []account.Referral
+	issued    int
+	quota     map[int]int
+}
+
+func (r *memoryInvitationRepo) FetchReferrals(ctx context.Context, inviterIds ...int) ([]account.Referral, error) {
+	res := make([]account.Referral, 0)
+	for _, v := range r.referrals {
+		for _, id := range inviterIds {
+			if v.InviterId == id {
+				res = append(res, v)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryInvitationRepo) CountIssued(ctx context.Context, inviterId int) (int, error) {
+	return r.issued, nil
+}
+
+func (r *memoryInvitationRepo) FetchQuota(ctx context.Context, userId int) (int, bool, error) {
+	quota, found := r.quota[userId]
+	return quota, found, nil
+}
+
+func TestInvitationsRemaining(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryInvitationRepo{issued: 3, quota: map[int]int{2: 10, 3: 1}}
+	invitations := account.Invitations{InvitationRepo: repo, DefaultQuota: 5}
+
+	for id, want := range map[int]int{1: 2, 2: 7, 3: 0} {
+		remaining, err := invitations.Remaining(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if remaining != want {
+			t.Errorf("user %d has %d remaining invitations, want %d", id, remaining, want)
+		}
+	}
+}
+
+func TestReferralTree(t *testing.T) {
+	repo := &memoryInvitationRepo{referrals: []account.Referral{
+		{InviterId: 1, InviteeId: 2, Username: "b"},
+		{InviterId: 1, InviteeId: 3, Username: "c"},
+		{InviterId: 2, InviteeId: 4, Username: "d"},
+		{InviterId: 4, InviteeId: 5, Username: "e"},
+	}}
+	invitations := account.Invitations{InvitationRepo: repo}
+
+	root, err := invitations.ReferralTree(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(root.Invitees) != 2 {
+		t.Fatalf("root has %d invitees, want 2", len(root.Invitees))
+	}
+	b := root.Invitees[0]
+	if b.Username != "b" || len(b.Invitees) != 1 || b.Invitees[0].Username != "d" {
+		t.Errorf("unexpected invitees of b %+v", b)
+	}
+	if len(b.Invitees[0].Invitees) != 0 {
+		t.Error("tree is deeper than the depth")
+	}
+}
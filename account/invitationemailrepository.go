@@ -17,13 +17,13 @@ func (r *InvitationEmailVerificationRepo) makeKey(email string) string {
 	return r.namespace + ":" + email
 }
 
-// Keep the invitation for 15 mins
+// Keep the invitation for 7 days, the invitee might not sign up right away
 func (r *InvitationEmailVerificationRepo) Store(ctx context.Context, invitation InvitationEmail) error {
 	return r.store.SetWithExpiration(
 		ctx,
 		r.makeKey(invitation.Email),
 		invitation.Code,
-		15*time.Minute,
+		7*24*time.Hour,
 	)
 }
 
@@ -34,4 +34,9 @@ func (r *InvitationEmailVerificationRepo) Fetch(ctx context.Context, email strin
 func (r *InvitationEmailVerificationRepo) Exist(ctx context.Context, email string) (bool, error) {
 	num, err := r.store.Exist(ctx, r.makeKey(email))
 	return num == 1, err
-}
\ No newline at end of file
+}
+
+func (r *InvitationEmailVerificationRepo) Delete(ctx context.Context, email string) error {
+	_, err := r.store.Delete(ctx, r.makeKey(email))
+	return err
+}
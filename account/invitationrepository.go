@@ -0,0 +1,192 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+type InvitationRepo struct {
+	DB *sql.DB
+}
+
+const invitationColumns = "Id, InviterId, Email, InviteeId, Revoked, CreatedAt, SentAt"
+
+func scanInvitation(scan func(dest ...interface{}) error) (*Invitation, error) {
+	var v Invitation
+	var inviteeId sql.NullInt64
+	var revoked sqltype.MyBool
+
+	err := scan(&v.Id, &v.InviterId, &v.Email, &inviteeId, &revoked, &v.CreatedAt, &v.SentAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if inviteeId.Valid {
+		id := int(inviteeId.Int64)
+		v.InviteeId = &id
+	}
+	v.Revoked = bool(revoked)
+	return &v, nil
+}
+
+func (r *InvitationRepo) StoreWithinQuota(ctx context.Context, invitation *Invitation, defaultQuota int) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// lock the inviter so concurrent invitations of the same user are counted one after another
+	var quota sql.NullInt64
+	query := "SELECT q.Quota FROM user AS u LEFT JOIN invitation_quota AS q ON q.UserId = u.Id WHERE u.Id = ? FOR UPDATE;"
+	err = tx.QueryRowContext(ctx, query, invitation.InviterId).Scan(&quota)
+	if err != nil {
+		return false, err
+	}
+
+	limit := defaultQuota
+	if quota.Valid {
+		limit = int(quota.Int64)
+	}
+
+	var issued int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM invitation WHERE InviterId = ? AND Revoked = b'0';", invitation.InviterId).Scan(&issued)
+	if err != nil {
+		return false, err
+	}
+	if issued >= limit {
+		return false, nil
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO invitation (InviterId, Email, InviteeId, Revoked, CreatedAt, SentAt) VALUES(?, ?, ?, ?, ?, ?);",
+		invitation.InviterId,
+		invitation.Email,
+		invitation.InviteeId,
+		sqltype.MyBool(invitation.Revoked),
+		invitation.CreatedAt,
+		invitation.SentAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return false, err
+	}
+	invitation.Id = int(lastId)
+	return true, tx.Commit()
+}
+
+func (r *InvitationRepo) FetchById(ctx context.Context, id int) (*Invitation, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+invitationColumns+" FROM invitation WHERE Id = ? LIMIT 1;", id)
+
+	invitation, err := scanInvitation(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return invitation, err
+}
+
+func (r *InvitationRepo) FetchPendingByEmail(ctx context.Context, email string) (*Invitation, error) {
+	query := "SELECT " + invitationColumns + " FROM invitation " +
+			 "WHERE Email = ? AND InviteeId IS NULL AND Revoked = b'0' ORDER BY Id DESC LIMIT 1;"
+
+	invitation, err := scanInvitation(r.DB.QueryRowContext(ctx, query, email).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return invitation, err
+}
+
+func (r *InvitationRepo) FetchByInviter(ctx context.Context, inviterId int) ([]Invitation, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+invitationColumns+" FROM invitation WHERE InviterId = ? ORDER BY Id DESC;", inviterId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]Invitation, 0)
+	for rows.Next() {
+		v, err := scanInvitation(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *v)
+	}
+	return invitations, rows.Err()
+}
+
+func (r *InvitationRepo) CountIssued(ctx context.Context, inviterId int) (int, error) {
+	var count int
+	err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM invitation WHERE InviterId = ? AND Revoked = b'0';", inviterId).Scan(&count)
+	return count, err
+}
+
+func (r *InvitationRepo) Update(ctx context.Context, invitation *Invitation) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		"UPDATE invitation SET InviteeId = ?, Revoked = ?, SentAt = ? WHERE Id = ?;",
+		invitation.InviteeId,
+		sqltype.MyBool(invitation.Revoked),
+		invitation.SentAt,
+		invitation.Id,
+	)
+	return err
+}
+
+func (r *InvitationRepo) FetchReferrals(ctx context.Context, inviterIds ...int) ([]Referral, error) {
+	if len(inviterIds) == 0 {
+		return []Referral{}, nil
+	}
+
+	vars := make([]interface{}, len(inviterIds))
+	for i, v := range inviterIds {
+		vars[i] = v
+	}
+
+	query := "SELECT inv.InviterId, inv.InviteeId, acc.Username, inv.Email, inv.CreatedAt " +
+			 "FROM invitation AS inv " +
+			 "INNER JOIN account AS acc ON acc.Id = inv.InviteeId " +
+			 "WHERE inv.InviterId IN (?" + strings.Repeat(", ?", len(inviterIds)-1) + ") ORDER BY inv.Id;"
+
+	rows, err := r.DB.QueryContext(ctx, query, vars...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referrals := make([]Referral, 0)
+	for rows.Next() {
+		var v Referral
+		err = rows.Scan(&v.InviterId, &v.InviteeId, &v.Username, &v.Email, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		referrals = append(referrals, v)
+	}
+	return referrals, rows.Err()
+}
+
+func (r *InvitationRepo) FetchQuota(ctx context.Context, userId int) (int, bool, error) {
+	var quota int
+	err := r.DB.QueryRowContext(ctx, "SELECT Quota FROM invitation_quota WHERE UserId = ? LIMIT 1;", userId).Scan(&quota)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+	return quota, true, nil
+}
+
+func (r *InvitationRepo) StoreQuota(ctx context.Context, userId, quota int) error {
+	query := "INSERT INTO invitation_quota VALUES(?, ?) ON DUPLICATE KEY UPDATE Quota = VALUES(Quota);"
+	_, err := r.DB.ExecContext(ctx, query, userId, quota)
+	return err
+}
@@ -0,0 +1,346 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"log"
+	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/random"
+)
+
+// Status of invoices, pending and underpaid invoices accept payments until they expire
+const (
+	InvoicePending   = "pending"
+	InvoicePaid      = "paid"
+	InvoiceUnderpaid = "underpaid"
+	InvoiceOverpaid  = "overpaid"
+	InvoiceExpired   = "expired"
+)
+
+const maxInvoiceMemo = 255
+
+var (
+	diemCurrencyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,15}$`)
+	celoTokenPattern    = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+)
+
+// A request to pay the amount in the smallest unit of the currency to a wallet of the business
+type Invoice struct {
+	Id         int
+	BusinessId int
+	// Random 16 bytes in hex included in the payment URI,
+	// Diem payments must carry it in their metadata to be applied to the invoice
+	Reference  string
+	Chain      string
+	Address    string
+	// Diem currency code or Celo token address
+	Currency   string
+	Amount     *big.Int
+	Received   *big.Int
+	Memo       string
+	Status     string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	PaidAt     *time.Time
+}
+
+// Expired invoices are open to the transfers made before they expired
+func (i *Invoice) Open(at time.Time) bool {
+	switch i.Status {
+	case InvoicePending, InvoiceUnderpaid, InvoiceExpired:
+		return at.Before(i.ExpiresAt)
+	}
+	return false
+}
+
+// Add the amount to the received amount and update the status
+func (i *Invoice) AddPayment(amount *big.Int, at time.Time) {
+	i.Received = new(big.Int).Add(i.Received, amount)
+
+	switch i.Received.Cmp(i.Amount) {
+	case -1:
+		i.Status = InvoiceUnderpaid
+	case 0:
+		i.Status = InvoicePaid
+	default:
+		i.Status = InvoiceOverpaid
+	}
+
+	if i.Status != InvoiceUnderpaid && i.PaidAt == nil {
+		i.PaidAt = &at
+	}
+}
+
+// URI that wallets can open to pay the invoice, the amount is in the smallest unit
+func (i *Invoice) PaymentURI() string {
+	switch i.Chain {
+	case blockchain.DiemChain:
+		query := url.Values{"c": {i.Currency}, "am": {i.Amount.String()}, "ref": {i.Reference}}
+		return "diem://" + i.Address + "?" + query.Encode()
+	case blockchain.CeloChain:
+		query := url.Values{"address": {i.Address}, "token": {i.Currency}, "amount": {i.Amount.String()}, "comment": {i.Reference}}
+		return "celo://wallet/pay?" + query.Encode()
+	}
+	return ""
+}
+
+// A transfer matched to an invoice, a transfer is only applied once
+type InvoicePayment struct {
+	InvoiceId int
+	Chain     string
+	Version   uint64
+	Index     int
+	// Position of the transfer event in Celo transactions
+	Transfer  int
+	From      string
+	Amount    *big.Int
+	Time      time.Time
+}
+
+type InvoiceForm struct {
+	Chain     string
+	Address   string
+	Currency  string
+	// Decimal integer in the smallest unit of the currency
+	Amount    string
+	Memo      string
+	ExpiresIn time.Duration
+}
+
+type InvoiceRepository interface {
+	// Id of the invoice is set after it is stored
+	Store(ctx context.Context, invoice *Invoice) error
+	Fetch(ctx context.Context, id int) (*Invoice, error)
+	// Latest first, before is the id of the last invoice of the previous page, status is optional
+	FetchByBusiness(ctx context.Context, businessId int, status string, before int, limit int) ([]Invoice, error)
+	// Invoices that are open at the time for the wallet and currency, oldest first
+	FetchOpen(ctx context.Context, chain, address, currency string, at time.Time) ([]Invoice, error)
+	// Store the payment and add it to the invoice atomically,
+	// returns nil if the transfer is already stored
+	StorePayment(ctx context.Context, payment InvoicePayment) (*Invoice, error)
+	FetchPayments(ctx context.Context, invoiceId int) ([]InvoicePayment, error)
+	// Mark pending invoices that expired before the time, returns the number of invoices
+	Expire(ctx context.Context, at time.Time) (int, error)
+}
+
+type invoiceWalletRepository interface {
+	FetchOwner(ctx context.Context, chain, address string) (int, error)
+}
+
+// Payment requests of businesses matched against incoming transfers
+type Invoices struct {
+	InvoiceRepo InvoiceRepository
+	WalletRepo  invoiceWalletRepository
+	MaxExpiry   time.Duration
+	MaxLimit    int
+}
+
+func (inv *Invoices) Create(ctx context.Context, businessId int, form InvoiceForm) (*Invoice, error) {
+	switch form.Chain {
+	case blockchain.DiemChain:
+		if !diemCurrencyPattern.MatchString(form.Currency) {
+			return nil, ErrInvoiceCurrency(ctx)
+		}
+	case blockchain.CeloChain:
+		if !celoTokenPattern.MatchString(form.Currency) {
+			return nil, ErrInvoiceCurrency(ctx)
+		}
+		form.Currency = strings.ToLower(form.Currency)
+	default:
+		return nil, ErrInvoiceCurrency(ctx)
+	}
+
+	amount, ok := new(big.Int).SetString(form.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, ErrInvoiceAmount(ctx)
+	}
+
+	if len(form.Memo) > maxInvoiceMemo {
+		return nil, ErrInvoiceMemo(ctx, maxInvoiceMemo)
+	}
+
+	if form.ExpiresIn <= 0 || form.ExpiresIn > inv.MaxExpiry {
+		return nil, ErrInvoiceExpiry(ctx, inv.MaxExpiry)
+	}
+
+	owner, err := inv.WalletRepo.FetchOwner(ctx, form.Chain, form.Address)
+	if errors.Is(err, errDoesNotExist) || (err == nil && owner != businessId) {
+		return nil, ErrInvoiceWallet(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	reference, err := random.Token16Byte()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invoice := &Invoice{
+		BusinessId: businessId,
+		Reference:  reference,
+		Chain:      form.Chain,
+		Address:    form.Address,
+		Currency:   form.Currency,
+		Amount:     amount,
+		Received:   new(big.Int),
+		Memo:       form.Memo,
+		Status:     InvoicePending,
+		ExpiresAt:  now.Add(form.ExpiresIn),
+		CreatedAt:  now,
+	}
+	return invoice, inv.InvoiceRepo.Store(ctx, invoice)
+}
+
+func (inv *Invoices) List(ctx context.Context, businessId int, status string, before int, limit int) ([]Invoice, error) {
+	if limit <= 0 || limit > inv.MaxLimit {
+		limit = inv.MaxLimit
+	}
+	return inv.InvoiceRepo.FetchByBusiness(ctx, businessId, status, before, limit)
+}
+
+// Only the business that created the invoice can see it
+func (inv *Invoices) Get(ctx context.Context, businessId, id int) (*Invoice, []InvoicePayment, error) {
+	invoice, err := inv.InvoiceRepo.Fetch(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, nil, ErrInvoiceNotExist(ctx)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if invoice.BusinessId != businessId {
+		return nil, nil, ErrInvoiceNotExist(ctx)
+	}
+
+	payments, err := inv.InvoiceRepo.FetchPayments(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return invoice, payments, nil
+}
+
+// The reference is the reference id of the Diem payment metadata, which is kept as it is in the encoded metadata
+func diemMetadataReferences(metadata, reference string) bool {
+	data, err := hex.DecodeString(strings.TrimPrefix(metadata, "0x"))
+	if err != nil || len(data) == 0 {
+		return false
+	}
+
+	ref, err := hex.DecodeString(reference)
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(data, ref)
+}
+
+// Apply the transfer to the open invoice of the wallet and currency that is referenced by the transfer,
+// the transfer is not applied if it references no invoice
+func (inv *Invoices) match(ctx context.Context, payment InvoicePayment, to, currency string, referenced func(reference string) bool) error {
+	if payment.Chain == blockchain.CeloChain {
+		currency = strings.ToLower(currency)
+	}
+
+	invoices, err := inv.InvoiceRepo.FetchOpen(ctx, payment.Chain, to, currency, payment.Time)
+	if err != nil || len(invoices) == 0 {
+		return err
+	}
+
+	for _, v := range invoices {
+		if referenced(v.Reference) {
+			payment.InvoiceId = v.Id
+			break
+		}
+	}
+	if payment.InvoiceId == 0 {
+		return nil
+	}
+
+	_, err = inv.InvoiceRepo.StorePayment(ctx, payment)
+	return err
+}
+
+// Match received transactions, updated transactions are already matched when they are received
+func (inv *Invoices) NotifyDiem(ctx context.Context, event string, txs ...DiemTransaction) error {
+	if event != WebhookTransactionReceived {
+		return nil
+	}
+
+	for _, v := range txs {
+		if v.Amount == nil || v.Amount.Sign() <= 0 {
+			continue
+		}
+
+		metadata := v.Metadata
+		err := inv.match(ctx, InvoicePayment{
+			Chain:   v.Chain,
+			Version: v.Version,
+			From:    v.From,
+			Amount:  v.Amount,
+			Time:    v.Time,
+		}, v.To, v.Currency, func(reference string) bool {
+			return diemMetadataReferences(metadata, reference)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (inv *Invoices) NotifyCelo(ctx context.Context, event string, txs ...CeloTransaction) error {
+	if event != WebhookTransactionReceived {
+		return nil
+	}
+
+	for _, v := range txs {
+		for i, t := range v.TransferEvents {
+			if t.Amount == nil || t.Amount.Sign() <= 0 {
+				continue
+			}
+
+			// the payment link puts the reference in the comment of the transfer
+			comment := v.TransferComments[i]
+			err := inv.match(ctx, InvoicePayment{
+				Chain:    v.Chain,
+				Version:  v.Version,
+				Index:    v.Index,
+				Transfer: i,
+				From:     t.From,
+				Amount:   t.Amount,
+				Time:     v.Time,
+			}, t.To, t.Currency, func(reference string) bool {
+				return comment != "" && strings.Contains(comment, reference)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Expire invoices on every interval until the context is cancelled
+func (inv *Invoices) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := inv.InvoiceRepo.Expire(ctx, time.Now())
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("invoice expiry failed: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package account_test
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type memoryInvoiceRepo struct {
+	account.InvoiceRepository
+	invoices []account.Invoice
+	payments map[string]bool
+}
+
+func (r *memoryInvoiceRepo) Store(ctx context.Context, invoice *account.Invoice) error {
+	invoice.Id = len(r.invoices) + 1
+	r.invoices = append(r.invoices, *invoice)
+	return nil
+}
+
+func (r *memoryInvoiceRepo) FetchOpen(ctx context.Context, chain, address, currency string, at time.Time) ([]account.Invoice, error) {
+	invoices := make([]account.Invoice, 0)
+	for _, v := range r.invoices {
+		if v.Chain == chain && v.Address == address && v.Currency == currency && v.Open(at) {
+			invoices = append(invoices, v)
+		}
+	}
+	return invoices, nil
+}
+
+func (r *memoryInvoiceRepo) StorePayment(ctx context.Context, payment account.InvoicePayment) (*account.Invoice, error) {
+	key := payment.Chain + "/" + strconv.FormatUint(payment.Version, 10)
+	if r.payments[key] {
+		return nil, nil
+	}
+	r.payments[key] = true
+
+	invoice := &r.invoices[payment.InvoiceId-1]
+	invoice.AddPayment(payment.Amount, payment.Time)
+	return invoice, nil
+}
+
+// The metadata has the reference of the invoice as its reference id
+func diemPayment(version uint64, to, currency string, amount int64, invoice *account.Invoice) account.DiemTransaction {
+	tx := account.DiemTransaction{}
+	if invoice != nil {
+		tx.Metadata = "0200" + invoice.Reference
+	}
+	tx.Chain = blockchain.DiemChain
+	tx.Version = version
+	tx.Time = time.Now()
+	tx.To = to
+	tx.Currency = currency
+	tx.Amount = big.NewInt(amount)
+	return tx
+}
+
+// The comment of the transfer has the reference of the invoice
+func celoPayment(version uint64, to, currency string, amount int64, invoice *account.Invoice) account.CeloTransaction {
+	tx := account.CeloTransaction{}
+	tx.Chain = blockchain.CeloChain
+	tx.Version = version
+	tx.Time = time.Now()
+	tx.TransferEvents = map[int]wallet.Transfer{3: {Currency: currency, To: to, Amount: big.NewInt(amount)}}
+	if invoice != nil {
+		tx.TransferComments = map[int]string{3: invoice.Reference}
+	}
+	return tx
+}
+
+func TestInvoiceMatching(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryInvoiceRepo{payments: make(map[string]bool)}
+	invoices := account.Invoices{
+		InvoiceRepo: repo,
+		WalletRepo:  ownerWalletRepo{"shop": 1, "other": 2},
+		MaxExpiry:   time.Hour,
+		MaxLimit:    10,
+	}
+
+	form := account.InvoiceForm{Chain: blockchain.DiemChain, Address: "other", Currency: "XUS", Amount: "100", ExpiresIn: time.Minute}
+	_, err := invoices.Create(ctx, 1, form)
+	if err == nil {
+		t.Error("invoice is created for the wallet of another account")
+	}
+
+	form.Address = "shop"
+	first, err := invoices.Create(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := invoices.Create(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(first.PaymentURI(), "am=100") {
+		t.Errorf("unexpected payment URI %s", first.PaymentURI())
+	}
+
+	err = invoices.NotifyDiem(ctx, account.WebhookTransactionReceived, diemPayment(1, "shop", "XUS", 60, first), diemPayment(1, "shop", "XUS", 60, first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := repo.invoices[0]; v.Status != account.InvoiceUnderpaid || v.Received.Int64() != 60 {
+		t.Errorf("payment is not applied once: %+v", v)
+	}
+
+	err = invoices.NotifyDiem(ctx, account.WebhookTransactionReceived, diemPayment(2, "shop", "XDX", 40, first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.invoices[0].Status != account.InvoiceUnderpaid {
+		t.Error("payment in another currency is applied")
+	}
+
+	err = invoices.NotifyDiem(ctx, account.WebhookTransactionReceived, diemPayment(3, "shop", "XUS", 150, second), diemPayment(4, "shop", "XUS", 40, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := repo.invoices[1]; v.Status != account.InvoiceOverpaid || v.PaidAt == nil {
+		t.Errorf("payment is not matched by reference: %+v", v)
+	}
+	if v := repo.invoices[0]; v.Status != account.InvoiceUnderpaid {
+		t.Errorf("payment without reference is applied: %+v", v)
+	}
+
+	err = invoices.NotifyDiem(ctx, account.WebhookTransactionReceived, diemPayment(5, "shop", "XUS", 40, first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := repo.invoices[0]; v.Status != account.InvoicePaid {
+		t.Errorf("payment is not applied to the referenced invoice: %+v", v)
+	}
+}
+
+func TestCeloInvoiceMatching(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryInvoiceRepo{payments: make(map[string]bool)}
+	invoices := account.Invoices{
+		InvoiceRepo: repo,
+		WalletRepo:  ownerWalletRepo{"shop": 1},
+		MaxExpiry:   time.Hour,
+		MaxLimit:    10,
+	}
+
+	form := account.InvoiceForm{Chain: blockchain.CeloChain, Address: "shop", Currency: "0x765DE816845861e75A25fCA122bb6898B8B1282a", Amount: "100", ExpiresIn: time.Minute}
+	_, err := invoices.Create(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := invoices.Create(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = invoices.NotifyCelo(ctx, account.WebhookTransactionReceived, celoPayment(1, "shop", "0x765DE816845861e75A25fCA122bb6898B8B1282a", 100, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := repo.invoices[0]; v.Status != account.InvoicePending {
+		t.Errorf("transfer without comment is applied: %+v", v)
+	}
+
+	err = invoices.NotifyCelo(ctx, account.WebhookTransactionReceived, celoPayment(2, "shop", "0x765DE816845861e75A25fCA122bb6898B8B1282a", 100, second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := repo.invoices[1]; v.Status != account.InvoicePaid {
+		t.Errorf("transfer is not matched by its comment: %+v", v)
+	}
+	if v := repo.invoices[0]; v.Status != account.InvoicePending {
+		t.Errorf("transfer is applied to the oldest invoice: %+v", v)
+	}
+}
@@ -0,0 +1,213 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+type InvoiceRepo struct {
+	DB *sql.DB
+}
+
+const invoiceColumns = "Id, BusinessId, Reference, Chain, Address, Currency, Amount, Received, Memo, Status, ExpiresAt, CreatedAt, PaidAt"
+
+func scanInvoice(scan func(dest ...interface{}) error) (*Invoice, error) {
+	var v Invoice
+	var amount, received sql.NullString
+	var paidAt sql.NullTime
+
+	err := scan(
+		&v.Id,
+		&v.BusinessId,
+		&v.Reference,
+		&v.Chain,
+		&v.Address,
+		&v.Currency,
+		&amount,
+		&received,
+		&v.Memo,
+		&v.Status,
+		&v.ExpiresAt,
+		&v.CreatedAt,
+		&paidAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Amount = sqltype.ToBigInt(amount)
+	v.Received = sqltype.ToBigInt(received)
+	if paidAt.Valid {
+		v.PaidAt = &paidAt.Time
+	}
+	return &v, nil
+}
+
+func (r *InvoiceRepo) Store(ctx context.Context, invoice *Invoice) error {
+	res, err := r.DB.ExecContext(
+		ctx,
+		"INSERT INTO invoice (BusinessId, Reference, Chain, Address, Currency, Amount, Received, Memo, Status, ExpiresAt, CreatedAt) " +
+		"VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		invoice.BusinessId,
+		invoice.Reference,
+		invoice.Chain,
+		invoice.Address,
+		invoice.Currency,
+		invoice.Amount.String(),
+		invoice.Received.String(),
+		invoice.Memo,
+		invoice.Status,
+		invoice.ExpiresAt,
+		invoice.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	invoice.Id = int(lastId)
+	return nil
+}
+
+func (r *InvoiceRepo) Fetch(ctx context.Context, id int) (*Invoice, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+invoiceColumns+" FROM invoice WHERE Id = ? LIMIT 1;", id)
+
+	invoice, err := scanInvoice(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return invoice, err
+}
+
+func (r *InvoiceRepo) query(ctx context.Context, query string, args ...interface{}) ([]Invoice, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := make([]Invoice, 0)
+	for rows.Next() {
+		v, err := scanInvoice(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *v)
+	}
+	return invoices, rows.Err()
+}
+
+func (r *InvoiceRepo) FetchByBusiness(ctx context.Context, businessId int, status string, before int, limit int) ([]Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoice WHERE BusinessId = ?"
+	args := []interface{}{businessId}
+
+	if status != "" {
+		query += " AND Status = ?"
+		args = append(args, status)
+	}
+	if before > 0 {
+		query += " AND Id < ?"
+		args = append(args, before)
+	}
+	query += " ORDER BY Id DESC LIMIT ?;"
+	args = append(args, limit)
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *InvoiceRepo) FetchOpen(ctx context.Context, chain, address, currency string, at time.Time) ([]Invoice, error) {
+	query := "SELECT " + invoiceColumns + " FROM invoice WHERE Chain = ? AND Address = ? AND Currency = ? " +
+		"AND Status IN (?, ?, ?) AND ExpiresAt > ? ORDER BY Id;"
+	// expired invoices are included as the transfer can be seen after they are marked
+	return r.query(ctx, query, chain, address, currency, InvoicePending, InvoiceUnderpaid, InvoiceExpired, at)
+}
+
+func (r *InvoiceRepo) StorePayment(ctx context.Context, payment InvoicePayment) (*Invoice, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		"INSERT IGNORE INTO invoice_payment (Chain, Version, `Index`, Transfer, InvoiceId, `From`, Amount, Time) VALUES(?, ?, ?, ?, ?, ?, ?, ?);",
+		payment.Chain,
+		payment.Version,
+		payment.Index,
+		payment.Transfer,
+		payment.InvoiceId,
+		payment.From,
+		payment.Amount.String(),
+		payment.Time,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return nil, err
+	}
+
+	// lock the invoice so concurrent payments add up
+	row := tx.QueryRowContext(ctx, "SELECT "+invoiceColumns+" FROM invoice WHERE Id = ? FOR UPDATE;", payment.InvoiceId)
+	invoice, err := scanInvoice(row.Scan)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.AddPayment(payment.Amount, payment.Time)
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE invoice SET Received = ?, Status = ?, PaidAt = ? WHERE Id = ?;",
+		invoice.Received.String(),
+		invoice.Status,
+		invoice.PaidAt,
+		invoice.Id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return invoice, tx.Commit()
+}
+
+func (r *InvoiceRepo) FetchPayments(ctx context.Context, invoiceId int) ([]InvoicePayment, error) {
+	query := "SELECT Chain, Version, `Index`, Transfer, InvoiceId, `From`, Amount, Time FROM invoice_payment WHERE InvoiceId = ? ORDER BY Time;"
+
+	rows, err := r.DB.QueryContext(ctx, query, invoiceId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := make([]InvoicePayment, 0)
+	for rows.Next() {
+		var v InvoicePayment
+		var amount sql.NullString
+		err = rows.Scan(&v.Chain, &v.Version, &v.Index, &v.Transfer, &v.InvoiceId, &v.From, &amount, &v.Time)
+		if err != nil {
+			return nil, err
+		}
+		v.Amount = sqltype.ToBigInt(amount)
+		payments = append(payments, v)
+	}
+	return payments, rows.Err()
+}
+
+func (r *InvoiceRepo) Expire(ctx context.Context, at time.Time) (int, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE invoice SET Status = ? WHERE Status = ? AND ExpiresAt <= ?;", InvoiceExpired, InvoicePending, at)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	return int(affected), err
+}
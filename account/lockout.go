@@ -0,0 +1,176 @@
+package account
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/random"
+)
+
+type LoginAttemptRepository interface {
+	// Count a failure of the key, the count is forgotten after the window of the last failure
+	AddFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Zero time if the key is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Clear both the failures and the lock
+	Reset(ctx context.Context, key string) error
+	StoreUnlock(ctx context.Context, accountId int, token string) error
+	// The token can only be taken once
+	TakeUnlock(ctx context.Context, accountId int, token string) (bool, error)
+}
+
+// Failed attempts are shared by every instance, the delay between attempts doubles
+// after the free attempts and the key is locked after too many failures
+type LoginThrottle struct {
+	AttemptRepo  LoginAttemptRepository
+	Ext          ExternalComm
+	// Failures allowed without delay
+	FreeAttempts int
+	// Delay after the first failure over the free attempts
+	BaseDelay    time.Duration
+	// Failures that lock the key for LockDuration, the owner of an account is sent an unlock link
+	LockAttempts int
+	LockDuration time.Duration
+	Window       time.Duration
+}
+
+func accountThrottleKey(id int) string {
+	return "account:" + strconv.Itoa(id)
+}
+
+func totpThrottleKey(id int) string {
+	return "totp:" + strconv.Itoa(id)
+}
+
+func invitationThrottleKey(email string) string {
+	return "invitation:" + email
+}
+
+func (t *LoginThrottle) delay(failures int) time.Duration {
+	if failures <= t.FreeAttempts {
+		return 0
+	}
+	if failures >= t.LockAttempts {
+		return t.LockDuration
+	}
+
+	delay := t.BaseDelay
+	for i := t.FreeAttempts + 1; i < failures && delay < t.LockDuration; i++ {
+		delay *= 2
+	}
+	if delay > t.LockDuration {
+		return t.LockDuration
+	}
+	return delay
+}
+
+// Reject the attempt while the key is locked
+func (t *LoginThrottle) Check(ctx context.Context, key string) error {
+	until, err := t.AttemptRepo.LockedUntil(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if wait := time.Until(until); wait > 0 {
+		return ErrLoginLocked(ctx, wait)
+	}
+	return nil
+}
+
+// Record a failure and lock the key for the backoff delay, returns the number of failures
+func (t *LoginThrottle) Fail(ctx context.Context, key string) (int, error) {
+	failures, err := t.AttemptRepo.AddFailure(ctx, key, t.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	delay := t.delay(failures)
+	if delay == 0 {
+		return failures, nil
+	}
+	return failures, t.AttemptRepo.Lock(ctx, key, time.Now().Add(delay))
+}
+
+func (t *LoginThrottle) Reset(ctx context.Context, key string) error {
+	return t.AttemptRepo.Reset(ctx, key)
+}
+
+func (t *LoginThrottle) CheckAccount(ctx context.Context, id int) error {
+	return t.Check(ctx, accountThrottleKey(id))
+}
+
+// The owner is emailed an unlock link when the account gets locked
+func (t *LoginThrottle) FailAccount(ctx context.Context, acc *Base) error {
+	failures, err := t.Fail(ctx, accountThrottleKey(*acc.Id))
+	if err != nil {
+		return err
+	}
+
+	if failures != t.LockAttempts || acc.Email == "" {
+		return nil
+	}
+
+	token, err := random.Token20Byte()
+	if err != nil {
+		return err
+	}
+
+	err = t.AttemptRepo.StoreUnlock(ctx, *acc.Id, token)
+	if err != nil {
+		return err
+	}
+
+	return t.Ext.NotifyAccountLocked(ctx, acc.Email, acc.Username, t.serializeToken(*acc.Id, token))
+}
+
+func (t *LoginThrottle) ResetAccount(ctx context.Context, id int) error {
+	return t.Reset(ctx, accountThrottleKey(id))
+}
+
+// Codes are counted apart from passwords so a correct password does not clear the failed codes
+func (t *LoginThrottle) CheckTOTP(ctx context.Context, id int) error {
+	return t.Check(ctx, totpThrottleKey(id))
+}
+
+func (t *LoginThrottle) FailTOTP(ctx context.Context, id int) error {
+	_, err := t.Fail(ctx, totpThrottleKey(id))
+	return err
+}
+
+func (t *LoginThrottle) ResetTOTP(ctx context.Context, id int) error {
+	return t.Reset(ctx, totpThrottleKey(id))
+}
+
+func (t *LoginThrottle) separator() string {
+	return "~"
+}
+
+func (t *LoginThrottle) serializeToken(id int, token string) string {
+	return strconv.Itoa(id) + t.separator() + token
+}
+
+// Unlock the account with the token sent to its owner, returns the account id
+func (t *LoginThrottle) Unlock(ctx context.Context, serialized string) (int, error) {
+	str := strings.Split(serialized, t.separator())
+	if len(str) != 2 {
+		return 0, ErrUnlockToken(ctx)
+	}
+
+	id, err := strconv.Atoi(str[0])
+	if err != nil {
+		return 0, ErrUnlockToken(ctx)
+	}
+
+	valid, err := t.AttemptRepo.TakeUnlock(ctx, id, str[1])
+	if err != nil {
+		return 0, err
+	}
+	if !valid {
+		return 0, ErrUnlockToken(ctx)
+	}
+
+	return id, t.ResetAccount(ctx, id)
+}
@@ -0,0 +1,101 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/database/redisdb"
+)
+
+func newTestThrottle(t *testing.T) *account.LoginThrottle {
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mini.Close)
+
+	return &account.LoginThrottle{
+		AttemptRepo: account.NewLoginAttemptRepo(&redisdb.Handler{
+			Client: redis.NewClient(&redis.Options{
+				Addr: mini.Addr(),
+			}),
+		}, "test"),
+		FreeAttempts: 2,
+		BaseDelay: time.Minute,
+		LockAttempts: 4,
+		LockDuration: time.Hour,
+		Window: time.Hour,
+	}
+}
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	ctx := context.Background()
+	throttle := newTestThrottle(t)
+	key := "account:1"
+
+	for i := 0; i < 2; i++ {
+		if _, err := throttle.Fail(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+		if err := throttle.Check(ctx, key); err != nil {
+			t.Fatalf("expect no lock within the free attempts, got %v", err)
+		}
+	}
+
+	failures, err := throttle.Fail(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failures != 3 {
+		t.Errorf("expect 3 failures, got %v", failures)
+	}
+
+	var printable *account.PrintableError
+	if err := throttle.Check(ctx, key); !errors.As(err, &printable) {
+		t.Fatalf("expect the key to be locked, got %v", err)
+	}
+
+	if err := throttle.Reset(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := throttle.Check(ctx, key); err != nil {
+		t.Errorf("expect no lock after reset, got %v", err)
+	}
+}
+
+func TestLoginThrottle_Unlock(t *testing.T) {
+	ctx := context.Background()
+	throttle := newTestThrottle(t)
+
+	for _, v := range []string{"", "1", "x~token", "1~token"} {
+		if _, err := throttle.Unlock(ctx, v); err == nil {
+			t.Errorf("expect invalid unlock token %q to fail", v)
+		}
+	}
+}
+
+func TestLoginThrottle_TOTPSurvivesPassword(t *testing.T) {
+	ctx := context.Background()
+	throttle := newTestThrottle(t)
+
+	for i := 0; i < 3; i++ {
+		if err := throttle.FailTOTP(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a correct password only resets the password failures
+	if err := throttle.ResetAccount(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var printable *account.PrintableError
+	if err := throttle.CheckTOTP(ctx, 1); !errors.As(err, &printable) {
+		t.Errorf("expect the codes to stay locked, got %v", err)
+	}
+}
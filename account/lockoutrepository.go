@@ -0,0 +1,72 @@
+package account
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/kv"
+)
+
+type LoginAttemptRepo struct {
+	store     kv.ExpiringCounter
+	namespace string
+}
+
+func NewLoginAttemptRepo(store kv.ExpiringCounter, namespace string) *LoginAttemptRepo {
+	return &LoginAttemptRepo{store: store, namespace: namespace}
+}
+
+func (r *LoginAttemptRepo) failureKey(key string) string {
+	return r.namespace + ":failure:" + key
+}
+
+func (r *LoginAttemptRepo) lockKey(key string) string {
+	return r.namespace + ":lock:" + key
+}
+
+func (r *LoginAttemptRepo) unlockKey(accountId int, token string) string {
+	return r.namespace + ":unlock:" + strconv.Itoa(accountId) + ":" + token
+}
+
+func (r *LoginAttemptRepo) AddFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	return r.store.IncrWithExpiration(ctx, r.failureKey(key), window)
+}
+
+func (r *LoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.store.SetWithExpiration(ctx, r.lockKey(key), until.Unix(), time.Until(until))
+}
+
+func (r *LoginAttemptRepo) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	num, err := r.store.Exist(ctx, r.lockKey(key))
+	if err != nil || num == 0 {
+		return time.Time{}, err
+	}
+
+	// The lock can expire between the calls
+	until, err := r.store.Get(ctx, r.lockKey(key))
+	if err != nil {
+		return time.Time{}, nil
+	}
+
+	sec, err := strconv.ParseInt(until, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+func (r *LoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	_, err := r.store.Delete(ctx, r.failureKey(key), r.lockKey(key))
+	return err
+}
+
+// Keep the unlock token for 24 hours
+func (r *LoginAttemptRepo) StoreUnlock(ctx context.Context, accountId int, token string) error {
+	return r.store.SetWithExpiration(ctx, r.unlockKey(accountId, token), 1, 24*time.Hour)
+}
+
+func (r *LoginAttemptRepo) TakeUnlock(ctx context.Context, accountId int, token string) (bool, error) {
+	num, err := r.store.Delete(ctx, r.unlockKey(accountId, token))
+	return num == 1, err
+}
@@ -0,0 +1,175 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/random"
+	"github.com/stevealexrs/Go-Libra/webauthn"
+)
+
+type Passkey struct {
+	webauthn.Credential
+	AccountId int
+	CreatedAt time.Time
+}
+
+type PasskeyRepository interface {
+	Store(ctx context.Context, passkey Passkey) error
+	FetchById(ctx context.Context, id []byte) (*Passkey, error)
+	FetchByAccount(ctx context.Context, accountId int) ([]Passkey, error)
+	UpdateSignCount(ctx context.Context, id []byte, signCount uint32) error
+	Delete(ctx context.Context, accountId int, id []byte) error
+}
+
+// Challenges are single use
+type PasskeyChallengeRepository interface {
+	Store(ctx context.Context, key string, challenge []byte) error
+	Fetch(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type PasskeyAssertion struct {
+	CredentialId      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+}
+
+type PasskeyAuthenticator struct {
+	PasskeyRepo   PasskeyRepository
+	ChallengeRepo PasskeyChallengeRepository
+	RelyingParty  webauthn.RelyingParty
+}
+
+func registrationKey(accountId int) string {
+	return "register:" + strconv.Itoa(accountId)
+}
+
+func loginKey(token string) string {
+	return "login:" + token
+}
+
+func (a *PasskeyAuthenticator) takeChallenge(ctx context.Context, key string) ([]byte, error) {
+	challenge, err := a.ChallengeRepo.Fetch(ctx, key)
+	if err != nil {
+		return nil, ErrPasskeyChallenge(ctx)
+	}
+
+	err = a.ChallengeRepo.Delete(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Returns the challenge and existing credentials that should be excluded
+func (a *PasskeyAuthenticator) BeginRegistration(ctx context.Context, accountId int) ([]byte, []Passkey, error) {
+	existing, err := a.PasskeyRepo.FetchByAccount(ctx, accountId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = a.ChallengeRepo.Store(ctx, registrationKey(accountId), challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	return challenge, existing, nil
+}
+
+func (a *PasskeyAuthenticator) FinishRegistration(ctx context.Context, accountId int, clientDataJSON, attestationObject []byte) error {
+	challenge, err := a.takeChallenge(ctx, registrationKey(accountId))
+	if err != nil {
+		return err
+	}
+
+	cred, err := a.RelyingParty.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	if err != nil {
+		return ErrPasskeyInvalid(ctx)
+	}
+
+	_, err = a.PasskeyRepo.FetchById(ctx, cred.ID)
+	if err == nil {
+		return ErrPasskeyTaken(ctx)
+	} else if !errors.Is(err, errDoesNotExist) {
+		return err
+	}
+
+	return a.PasskeyRepo.Store(ctx, Passkey{
+		Credential: *cred,
+		AccountId:  accountId,
+		CreatedAt:  time.Now(),
+	})
+}
+
+// The account is unknown before the assertion, so the challenge is identified by a token
+func (a *PasskeyAuthenticator) BeginLogin(ctx context.Context) (string, []byte, error) {
+	token, err := random.Token16Byte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = a.ChallengeRepo.Store(ctx, loginKey(token), challenge)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, challenge, nil
+}
+
+// Returns the owner of the passkey, the caller must check the account type
+func (a *PasskeyAuthenticator) FinishLogin(ctx context.Context, token string, assertion PasskeyAssertion) (int, error) {
+	challenge, err := a.takeChallenge(ctx, loginKey(token))
+	if err != nil {
+		return 0, err
+	}
+
+	passkey, err := a.PasskeyRepo.FetchById(ctx, assertion.CredentialId)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	signCount, err := a.RelyingParty.VerifyAssertion(
+		challenge,
+		passkey.Credential,
+		assertion.ClientDataJSON,
+		assertion.AuthenticatorData,
+		assertion.Signature,
+	)
+	if err != nil {
+		return 0, ErrInvalidCredentials(ctx)
+	}
+
+	err = a.PasskeyRepo.UpdateSignCount(ctx, passkey.ID, signCount)
+	if err != nil {
+		return 0, err
+	}
+	return passkey.AccountId, nil
+}
+
+func (a *PasskeyAuthenticator) List(ctx context.Context, accountId int) ([]Passkey, error) {
+	return a.PasskeyRepo.FetchByAccount(ctx, accountId)
+}
+
+func (a *PasskeyAuthenticator) Delete(ctx context.Context, accountId int, id []byte) error {
+	passkey, err := a.PasskeyRepo.FetchById(ctx, id)
+	if errors.Is(err, errDoesNotExist) || (err == nil && passkey.AccountId != accountId) {
+		return ErrPasskeyNotExist(ctx)
+	} else if err != nil {
+		return err
+	}
+	return a.PasskeyRepo.Delete(ctx, accountId, id)
+}
@@ -0,0 +1,103 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/kv"
+)
+
+type PasskeyRepo struct {
+	DB *sql.DB
+}
+
+type PasskeyChallengeRepo kvRepo
+
+func NewPasskeyChallengeRepo(store kv.ExpiringStore, namespace string) *PasskeyChallengeRepo {
+	return &PasskeyChallengeRepo{store: store, namespace: namespace}
+}
+
+func (r *PasskeyRepo) Store(ctx context.Context, passkey Passkey) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		"INSERT INTO passkey VALUES(?, ?, ?, ?, ?);",
+		passkey.ID,
+		passkey.AccountId,
+		passkey.PublicKey,
+		passkey.SignCount,
+		passkey.CreatedAt,
+	)
+	return err
+}
+
+func (r *PasskeyRepo) FetchById(ctx context.Context, id []byte) (*Passkey, error) {
+	passkey := Passkey{}
+
+	err := r.DB.QueryRowContext(ctx, "SELECT Id, AccountId, PublicKey, SignCount, CreatedAt FROM passkey WHERE Id = ? LIMIT 1;", id).Scan(
+		&passkey.ID,
+		&passkey.AccountId,
+		&passkey.PublicKey,
+		&passkey.SignCount,
+		&passkey.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	} else if err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+func (r *PasskeyRepo) FetchByAccount(ctx context.Context, accountId int) ([]Passkey, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT Id, PublicKey, SignCount, CreatedAt FROM passkey WHERE AccountId = ? ORDER BY CreatedAt;", accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passkeys := make([]Passkey, 0)
+	for rows.Next() {
+		passkey := Passkey{AccountId: accountId}
+
+		err = rows.Scan(&passkey.ID, &passkey.PublicKey, &passkey.SignCount, &passkey.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, passkey)
+	}
+	return passkeys, rows.Err()
+}
+
+func (r *PasskeyRepo) UpdateSignCount(ctx context.Context, id []byte, signCount uint32) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE passkey SET SignCount = ? WHERE Id = ?;", signCount, id)
+	return err
+}
+
+func (r *PasskeyRepo) Delete(ctx context.Context, accountId int, id []byte) error {
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM passkey WHERE Id = ? AND AccountId = ?;", id, accountId)
+	return err
+}
+
+func (r *PasskeyChallengeRepo) makeKey(key string) string {
+	return r.namespace + ":" + key
+}
+
+// Keep the challenge for 5 mins
+func (r *PasskeyChallengeRepo) Store(ctx context.Context, key string, challenge []byte) error {
+	return r.store.SetWithExpiration(ctx, r.makeKey(key), string(challenge), 5*time.Minute)
+}
+
+func (r *PasskeyChallengeRepo) Fetch(ctx context.Context, key string) ([]byte, error) {
+	challenge, err := r.store.Get(ctx, r.makeKey(key))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(challenge), nil
+}
+
+func (r *PasskeyChallengeRepo) Delete(ctx context.Context, key string) error {
+	_, err := r.store.Delete(ctx, r.makeKey(key))
+	return err
+}
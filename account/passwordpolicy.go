@@ -0,0 +1,120 @@
+package account
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/password"
+)
+
+// Password with the account details it must not contain
+type PasswordCandidate struct {
+	Password string
+	Username string
+	Email    string
+}
+
+type PasswordRule interface {
+	// Return a PrintableError when the password violates the rule
+	Check(ctx context.Context, candidate PasswordCandidate) error
+}
+
+// Used when a policy has no length, so a missing policy doesn't accept any password
+const minPasswordLength = 10
+
+// Rules are checked in order, the first violation is returned.
+// A policy without rules still requires the minimum length
+type PasswordPolicy []PasswordRule
+
+func (p PasswordPolicy) Check(ctx context.Context, candidate PasswordCandidate) error {
+	if len(p) == 0 {
+		return MinPasswordLength{}.Check(ctx, candidate)
+	}
+
+	for _, v := range p {
+		err := v.Check(ctx, candidate)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Breached passwords are only rejected when the dataset is given
+func DefaultPasswordPolicy(breached password.BreachChecker) PasswordPolicy {
+	policy := PasswordPolicy{
+		MinPasswordLength{Length: minPasswordLength},
+		NoPersonalInfo{},
+		MinPasswordEntropy{Bits: 45},
+	}
+	if breached != nil {
+		policy = append(policy, NotBreached{Checker: breached})
+	}
+	return policy
+}
+
+// Zero length is the default minimum length
+type MinPasswordLength struct {
+	Length int
+}
+
+func (r MinPasswordLength) Check(ctx context.Context, candidate PasswordCandidate) error {
+	length := r.Length
+	if length <= 0 {
+		length = minPasswordLength
+	}
+
+	if len([]rune(candidate.Password)) < length {
+		return ErrPasswordTooShort(ctx, length)
+	}
+	return nil
+}
+
+type MinPasswordEntropy struct {
+	Bits float64
+}
+
+func (r MinPasswordEntropy) Check(ctx context.Context, candidate PasswordCandidate) error {
+	if password.Entropy(candidate.Password) < r.Bits {
+		return ErrPasswordTooWeak(ctx)
+	}
+	return nil
+}
+
+// Reject passwords containing the username or the name part of the email
+type NoPersonalInfo struct{}
+
+func (r NoPersonalInfo) Check(ctx context.Context, candidate PasswordCandidate) error {
+	pw := strings.ToLower(candidate.Password)
+
+	info := []string{strings.ToLower(candidate.Username)}
+	if email := strings.ToLower(candidate.Email); email != "" {
+		info = append(info, email, strings.SplitN(email, "@", 2)[0])
+	}
+
+	for _, v := range info {
+		// Too short to be meaningful
+		if len([]rune(v)) < 3 {
+			continue
+		}
+		if strings.Contains(pw, v) {
+			return ErrPasswordPersonalInfo(ctx)
+		}
+	}
+	return nil
+}
+
+type NotBreached struct {
+	Checker password.BreachChecker
+}
+
+func (r NotBreached) Check(ctx context.Context, candidate PasswordCandidate) error {
+	breached, err := r.Checker.Breached(candidate.Password)
+	if err != nil {
+		return err
+	}
+	if breached {
+		return ErrPasswordBreached(ctx)
+	}
+	return nil
+}
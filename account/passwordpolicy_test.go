@@ -0,0 +1,51 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/password"
+)
+
+func TestDefaultPasswordPolicy(t *testing.T) {
+	policy := account.DefaultPasswordPolicy(&password.PrefixDataset{
+		FS: fstest.MapFS{
+			// SHA-1 of "correct horse battery staple"
+			"ABF7A": {Data: []byte("AD6438836DBE526AA231ABDE2D0EEF74D42:10\n")},
+		},
+	})
+
+	tests := []struct {
+		password string
+		valid    bool
+	}{
+		{"", false},
+		{"short1!", false},
+		{"aaaaaaaaaaaaaaaa", false},
+		{"merchant_jane-x7#Kp2", false},
+		{"jane.doe.x7#Kp2!vQ", false},
+		{"correct horse battery staple", false},
+		{"x7#Kp2!vQm9z-river", true},
+	}
+
+	for _, v := range tests {
+		err := policy.Check(context.Background(), account.PasswordCandidate{
+			Password: v.password,
+			Username: "Merchant_Jane",
+			Email:    "jane.doe@random.com",
+		})
+		if (err == nil) != v.valid {
+			t.Errorf("password %q: expect valid %v, got %v", v.password, v.valid, err)
+		}
+	}
+}
+
+func TestPasswordPolicy_Zero(t *testing.T) {
+	var policy account.PasswordPolicy
+	err := policy.Check(context.Background(), account.PasswordCandidate{Password: "short"})
+	if err == nil {
+		t.Error("zero policy accepts a short password")
+	}
+}
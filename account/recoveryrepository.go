@@ -78,4 +78,33 @@ func (r *RecoveryRepo) Delete(ctx context.Context, accountId int) error {
 func (r *RecoveryRepo) Exist(ctx context.Context, accountId int) (bool, error) {
 	num, err := r.store.Exist(ctx, r.makeKey(accountId))
 	return num == 1, err
-}
\ No newline at end of file
+}
+
+type EmailRevertRepo kvRepo
+
+func NewEmailRevertRepo(store kv.ExpiringStore, namespace string) *EmailRevertRepo {
+	return &EmailRevertRepo{store: store, namespace: namespace}
+}
+
+func (r *EmailRevertRepo) makeKey(accountId int, token string) string {
+	return r.namespace + ":" + strconv.Itoa(accountId) + ":" + token
+}
+
+// Store the revert token for 7 days
+func (r *EmailRevertRepo) Store(ctx context.Context, revert *EmailRevert) error {
+	return r.store.SetWithExpiration(
+		ctx,
+		r.makeKey(revert.AccountId, revert.Token),
+		revert.Email,
+		7*24*time.Hour,
+	)
+}
+
+func (r *EmailRevertRepo) Fetch(ctx context.Context, accountId int, token string) (string, error) {
+	return r.store.Get(ctx, r.makeKey(accountId, token))
+}
+
+func (r *EmailRevertRepo) Delete(ctx context.Context, accountId int, token string) error {
+	_, err := r.store.Delete(ctx, r.makeKey(accountId, token))
+	return err
+}
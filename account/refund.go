@@ -0,0 +1,199 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// A transfer of a transaction, Diem transactions only have the transfer 0
+// and Celo transfers are identified by their log index
+type TransferId struct {
+	wallet.TransactionId
+	Transfer int
+}
+
+// An outgoing transfer of a business that returns some or all of an incoming transfer
+type Refund struct {
+	// The refund transfer
+	TransferId
+	Original   TransferId
+	BusinessId int
+	// Wallet of the payer that receives the refund
+	Recipient  string
+	Currency   string
+	Amount     *big.Int
+	CreatedAt  time.Time
+}
+
+// Refunds of an incoming transfer
+type RefundSummary struct {
+	Original TransferId
+	Currency string
+	Amount   *big.Int
+	Refunded *big.Int
+	Refunds  []Refund
+}
+
+func (s *RefundSummary) Full() bool {
+	return s.Refunded.Cmp(s.Amount) >= 0
+}
+
+type RefundRepository interface {
+	// Transfer stored in the local transaction tables
+	FetchTransfer(ctx context.Context, id TransferId) (*wallet.Transfer, error)
+	// Store the refund if the refunds of its original add up to at most the limit, the refunds of the original
+	// are locked while they are added up. Returns false without storing if the limit is exceeded.
+	// The sender remark of the refund transaction is marked as a refund too
+	Store(ctx context.Context, refund Refund, limit *big.Int) (bool, error)
+	FetchByOriginal(ctx context.Context, original TransferId) ([]Refund, error)
+	// Latest first
+	FetchByBusiness(ctx context.Context, businessId int) ([]Refund, error)
+	// Refunds to the wallets of the account, latest first
+	FetchByRecipient(ctx context.Context, accountId int) ([]Refund, error)
+	// Refunds where either the refund or the original transfer is in the list
+	FetchLinked(ctx context.Context, ids ...TransferId) ([]Refund, error)
+}
+
+type refundWalletRepository interface {
+	FetchOwner(ctx context.Context, chain, address string) (int, error)
+}
+
+// Links refunds of businesses to the payments they return
+type Refunds struct {
+	RefundRepo RefundRepository
+	WalletRepo refundWalletRepository
+}
+
+func (f *Refunds) fetchTransfer(ctx context.Context, id TransferId) (*wallet.Transfer, error) {
+	transfer, err := f.RefundRepo.FetchTransfer(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrRefundTransaction(ctx)
+	}
+	return transfer, err
+}
+
+// The wallet must belong to the business
+func (f *Refunds) owned(ctx context.Context, businessId int, chain, address string) error {
+	owner, err := f.WalletRepo.FetchOwner(ctx, chain, address)
+	if errors.Is(err, errDoesNotExist) || (err == nil && owner != businessId) {
+		return ErrRefundWallet(ctx)
+	}
+	return err
+}
+
+// Mark the outgoing transfer as a refund of the incoming transfer, the refunds of a transfer cannot
+// add up to more than its amount
+func (f *Refunds) Mark(ctx context.Context, businessId int, refundId, originalId TransferId) (*RefundSummary, error) {
+	if refundId.Chain != originalId.Chain || refundId.TransactionId == originalId.TransactionId {
+		return nil, ErrRefundMismatch(ctx)
+	}
+
+	refundTransfer, err := f.fetchTransfer(ctx, refundId)
+	if err != nil {
+		return nil, err
+	}
+	original, err := f.fetchTransfer(ctx, originalId)
+	if err != nil {
+		return nil, err
+	}
+	if refundTransfer.Amount == nil || original.Amount == nil {
+		return nil, ErrRefundTransaction(ctx)
+	}
+
+	err = f.owned(ctx, businessId, originalId.Chain, original.To)
+	if err != nil {
+		return nil, err
+	}
+	err = f.owned(ctx, businessId, refundId.Chain, refundTransfer.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(refundTransfer.To, original.From) || !strings.EqualFold(refundTransfer.Currency, original.Currency) {
+		return nil, ErrRefundMismatch(ctx)
+	}
+
+	linked, err := f.RefundRepo.FetchLinked(ctx, refundId)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range linked {
+		if v.TransferId == refundId {
+			return nil, ErrRefundExists(ctx)
+		}
+	}
+
+	refund := Refund{
+		TransferId: refundId,
+		Original:   originalId,
+		BusinessId: businessId,
+		Recipient:  refundTransfer.To,
+		Currency:   refundTransfer.Currency,
+		Amount:     refundTransfer.Amount,
+		CreatedAt:  time.Now(),
+	}
+	stored, err := f.RefundRepo.Store(ctx, refund, original.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, ErrRefundExceeds(ctx)
+	}
+	return f.summary(ctx, originalId, original)
+}
+
+func (f *Refunds) summary(ctx context.Context, originalId TransferId, original *wallet.Transfer) (*RefundSummary, error) {
+	refunds, err := f.RefundRepo.FetchByOriginal(ctx, originalId)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded := new(big.Int)
+	for _, v := range refunds {
+		if v.Amount != nil {
+			refunded.Add(refunded, v.Amount)
+		}
+	}
+
+	return &RefundSummary{
+		Original: originalId,
+		Currency: original.Currency,
+		Amount:   original.Amount,
+		Refunded: refunded,
+		Refunds:  refunds,
+	}, nil
+}
+
+// Refunds of an incoming transfer of the business
+func (f *Refunds) Summary(ctx context.Context, businessId int, originalId TransferId) (*RefundSummary, error) {
+	original, err := f.fetchTransfer(ctx, originalId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = f.owned(ctx, businessId, originalId.Chain, original.To)
+	if err != nil {
+		return nil, err
+	}
+	return f.summary(ctx, originalId, original)
+}
+
+func (f *Refunds) Issued(ctx context.Context, businessId int) ([]Refund, error) {
+	return f.RefundRepo.FetchByBusiness(ctx, businessId)
+}
+
+func (f *Refunds) Received(ctx context.Context, accountId int) ([]Refund, error) {
+	return f.RefundRepo.FetchByRecipient(ctx, accountId)
+}
+
+func (f *Refunds) Linked(ctx context.Context, ids ...TransferId) ([]Refund, error) {
+	if len(ids) == 0 {
+		return []Refund{}, nil
+	}
+	return f.RefundRepo.FetchLinked(ctx, ids...)
+}
@@ -0,0 +1,120 @@
+package account_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type memoryRefundRepo struct {
+	account.RefundRepository
+	transfers map[account.TransferId]wallet.Transfer
+	refunds   []account.Refund
+}
+
+func (r *memoryRefundRepo) FetchTransfer(ctx context.Context, id account.TransferId) (*wallet.Transfer, error) {
+	transfer, ok := r.transfers[id]
+	if !ok {
+		return nil, account.ErrRefundTransaction(ctx)
+	}
+	return &transfer, nil
+}
+
+func (r *memoryRefundRepo) Store(ctx context.Context, refund account.Refund, limit *big.Int) (bool, error) {
+	refunded := new(big.Int).Set(refund.Amount)
+	for _, v := range r.refunds {
+		if v.Original == refund.Original {
+			refunded.Add(refunded, v.Amount)
+		}
+	}
+	if refunded.Cmp(limit) > 0 {
+		return false, nil
+	}
+
+	r.refunds = append(r.refunds, refund)
+	return true, nil
+}
+
+func (r *memoryRefundRepo) FetchLinked(ctx context.Context, ids ...account.TransferId) ([]account.Refund, error) {
+	refunds := make([]account.Refund, 0)
+	for _, v := range r.refunds {
+		for _, id := range ids {
+			if v.TransferId == id || v.Original == id {
+				refunds = append(refunds, v)
+			}
+		}
+	}
+	return refunds, nil
+}
+
+func (r *memoryRefundRepo) FetchByOriginal(ctx context.Context, original account.TransferId) ([]account.Refund, error) {
+	refunds := make([]account.Refund, 0)
+	for _, v := range r.refunds {
+		if v.Original == original {
+			refunds = append(refunds, v)
+		}
+	}
+	return refunds, nil
+}
+
+func diemTransferId(version uint64) account.TransferId {
+	return account.TransferId{TransactionId: wallet.TransactionId{Version: version, Chain: blockchain.DiemChain}}
+}
+
+func TestRefundMark(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryRefundRepo{transfers: map[account.TransferId]wallet.Transfer{
+		diemTransferId(1): {Currency: "XUS", From: "payer", To: "shop", Amount: big.NewInt(100)},
+		diemTransferId(2): {Currency: "XUS", From: "shop", To: "payer", Amount: big.NewInt(60)},
+		diemTransferId(3): {Currency: "XUS", From: "shop", To: "payer", Amount: big.NewInt(50)},
+		diemTransferId(4): {Currency: "XUS", From: "shop", To: "payer", Amount: big.NewInt(40)},
+		diemTransferId(5): {Currency: "XUS", From: "shop", To: "stranger", Amount: big.NewInt(1)},
+		diemTransferId(6): {Currency: "XUS", From: "shop", To: "payer"},
+	}}
+	refunds := account.Refunds{RefundRepo: repo, WalletRepo: ownerWalletRepo{"shop": 1, "payer": 2}}
+
+	_, err := refunds.Mark(ctx, 2, diemTransferId(2), diemTransferId(1))
+	if err == nil {
+		t.Error("refund is marked by an account that did not receive the payment")
+	}
+
+	_, err = refunds.Mark(ctx, 1, diemTransferId(5), diemTransferId(1))
+	if err == nil {
+		t.Error("transfer to someone else is marked as a refund")
+	}
+
+	_, err = refunds.Mark(ctx, 1, diemTransferId(6), diemTransferId(1))
+	if err == nil {
+		t.Error("transfer without amount is marked as a refund")
+	}
+
+	summary, err := refunds.Mark(ctx, 1, diemTransferId(2), diemTransferId(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Refunded.Int64() != 60 || summary.Full() {
+		t.Errorf("unexpected partial refund %+v", summary)
+	}
+
+	_, err = refunds.Mark(ctx, 1, diemTransferId(2), diemTransferId(1))
+	if err == nil {
+		t.Error("the same transfer is marked as a refund twice")
+	}
+
+	_, err = refunds.Mark(ctx, 1, diemTransferId(3), diemTransferId(1))
+	if err == nil {
+		t.Error("refunds add up to more than the payment")
+	}
+
+	summary, err = refunds.Mark(ctx, 1, diemTransferId(4), diemTransferId(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !summary.Full() || len(summary.Refunds) != 2 {
+		t.Errorf("unexpected full refund %+v", summary)
+	}
+}
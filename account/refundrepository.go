@@ -0,0 +1,211 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type RefundRepo struct {
+	DB *sql.DB
+}
+
+const refundColumns = "r.Chain, r.Version, r.`Index`, r.Transfer, r.OriginalVersion, r.OriginalIndex, r.OriginalTransfer, " +
+	"r.BusinessId, r.Recipient, r.Currency, r.Amount, r.CreatedAt"
+
+func scanRefund(scan func(dest ...interface{}) error) (*Refund, error) {
+	var v Refund
+	var amount sql.NullString
+
+	err := scan(
+		&v.Chain,
+		&v.Version,
+		&v.Index,
+		&v.Transfer,
+		&v.Original.Version,
+		&v.Original.Index,
+		&v.Original.Transfer,
+		&v.BusinessId,
+		&v.Recipient,
+		&v.Currency,
+		&amount,
+		&v.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Original.Chain = v.Chain
+	v.Amount = sqltype.ToBigInt(amount)
+	return &v, nil
+}
+
+func (r *RefundRepo) FetchTransfer(ctx context.Context, id TransferId) (*wallet.Transfer, error) {
+	var row *sql.Row
+	switch id.Chain {
+	case blockchain.DiemChain:
+		if id.Transfer != 0 {
+			return nil, errDoesNotExist
+		}
+		row = r.DB.QueryRowContext(
+			ctx,
+			"SELECT Currency, Amount, `From`, `To` FROM transaction_diem WHERE Version = ? AND Chain = ? AND `Index` = ? LIMIT 1;",
+			id.Version, id.Chain, id.Index,
+		)
+	case blockchain.CeloChain:
+		row = r.DB.QueryRowContext(
+			ctx,
+			"SELECT Currency, Amount, `From`, `To` FROM transaction_celo_transfer WHERE Version = ? AND Chain = ? AND `Index` = ? AND LogIndex = ? LIMIT 1;",
+			id.Version, id.Chain, id.Index, id.Transfer,
+		)
+	default:
+		return nil, errDoesNotExist
+	}
+
+	var transfer wallet.Transfer
+	var amount sql.NullString
+	err := row.Scan(&transfer.Currency, &amount, &transfer.From, &transfer.To)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	} else if err != nil {
+		return nil, err
+	}
+
+	transfer.Amount = sqltype.ToBigInt(amount)
+	return &transfer, nil
+}
+
+func (r *RefundRepo) Store(ctx context.Context, refund Refund, limit *big.Int) (bool, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// concurrent refunds of the same original wait for each other so they can't exceed the limit together
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT Amount FROM transaction_refund WHERE Chain = ? AND OriginalVersion = ? AND OriginalIndex = ? AND OriginalTransfer = ? FOR UPDATE;",
+		refund.Chain,
+		refund.Original.Version,
+		refund.Original.Index,
+		refund.Original.Transfer,
+	)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	refunded := new(big.Int).Set(refund.Amount)
+	for rows.Next() {
+		var amount sql.NullString
+		err = rows.Scan(&amount)
+		if err != nil {
+			return false, err
+		}
+		if v := sqltype.ToBigInt(amount); v != nil {
+			refunded.Add(refunded, v)
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return false, err
+	}
+	rows.Close()
+
+	if refunded.Cmp(limit) > 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO transaction_refund VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		refund.Chain,
+		refund.Version,
+		refund.Index,
+		refund.Transfer,
+		refund.Original.Version,
+		refund.Original.Index,
+		refund.Original.Transfer,
+		refund.BusinessId,
+		refund.Recipient,
+		refund.Currency,
+		refund.Amount.String(),
+		refund.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO transaction_sender (Version, Chain, `Index`, Refund) VALUES(?, ?, ?, b'1') ON DUPLICATE KEY UPDATE Refund = b'1';",
+		refund.Version,
+		refund.Chain,
+		refund.Index,
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *RefundRepo) query(ctx context.Context, query string, args ...interface{}) ([]Refund, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refunds := make([]Refund, 0)
+	for rows.Next() {
+		v, err := scanRefund(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *v)
+	}
+	return refunds, rows.Err()
+}
+
+func (r *RefundRepo) FetchByOriginal(ctx context.Context, original TransferId) ([]Refund, error) {
+	query := "SELECT " + refundColumns + " FROM transaction_refund AS r " +
+		"WHERE r.Chain = ? AND r.OriginalVersion = ? AND r.OriginalIndex = ? AND r.OriginalTransfer = ? ORDER BY r.CreatedAt;"
+	return r.query(ctx, query, original.Chain, original.Version, original.Index, original.Transfer)
+}
+
+func (r *RefundRepo) FetchByBusiness(ctx context.Context, businessId int) ([]Refund, error) {
+	query := "SELECT " + refundColumns + " FROM transaction_refund AS r WHERE r.BusinessId = ? ORDER BY r.CreatedAt DESC;"
+	return r.query(ctx, query, businessId)
+}
+
+func (r *RefundRepo) FetchByRecipient(ctx context.Context, accountId int) ([]Refund, error) {
+	query := "SELECT " + refundColumns + " FROM transaction_refund AS r " +
+		"INNER JOIN wallet AS w ON w.Chain = r.Chain AND w.Address = r.Recipient " +
+		"WHERE w.AccountId = ? ORDER BY r.CreatedAt DESC;"
+	return r.query(ctx, query, accountId)
+}
+
+func (r *RefundRepo) FetchLinked(ctx context.Context, ids ...TransferId) ([]Refund, error) {
+	query := "SELECT " + refundColumns + " FROM transaction_refund AS r WHERE "
+	args := make([]interface{}, 0, len(ids)*8)
+
+	conditions := make([]string, 0, len(ids)*2)
+	for _, v := range ids {
+		conditions = append(
+			conditions,
+			"(r.Chain = ? AND r.Version = ? AND r.`Index` = ? AND r.Transfer = ?)",
+			"(r.Chain = ? AND r.OriginalVersion = ? AND r.OriginalIndex = ? AND r.OriginalTransfer = ?)",
+		)
+		args = append(args, v.Chain, v.Version, v.Index, v.Transfer, v.Chain, v.Version, v.Index, v.Transfer)
+	}
+	query += strings.Join(conditions, " OR ") + ";"
+
+	return r.query(ctx, query, args...)
+}
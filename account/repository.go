@@ -29,14 +29,14 @@ func (r *UserRepo) Store(ctx context.Context, account *User) (int, error) {
 		return 0, err
 	}
 
-	accStmt, err := tx.PrepareContext(ctx, "INSERT INTO account VALUES(NULL, ?, ?, ?, ?, ?);")
+	accStmt, err := tx.PrepareContext(ctx, "INSERT INTO account (Username, UsernameCanonical, PasswordHash, RecoveryEmail, UnverifiedRecoveryEmail, Deleted) VALUES(?, ?, ?, ?, ?, ?);")
 	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
 	defer accStmt.Close()
 
-	res, err := accStmt.ExecContext(ctx, account.Username, account.PasswordHash, account.Email, account.UnverifiedEmail, sqltype.MyBool(account.Deleted))
+	res, err := accStmt.ExecContext(ctx, account.Username, CanonicalUsername(account.Username), account.PasswordHash, account.Email, account.UnverifiedEmail, sqltype.MyBool(account.Deleted))
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -103,9 +103,9 @@ func (r *UserRepo) FetchById(ctx context.Context, id int) (*User, error) {
 }
 
 func (r *UserRepo) FetchByUsername(ctx context.Context, name string) (*User, error) {
-	query := "SELECT user.Id, user.InvitationEmail, user.DisplayName, account.PasswordHash, " +
+	query := "SELECT user.Id, user.InvitationEmail, account.Username, user.DisplayName, account.PasswordHash, " +
 			 "account.RecoveryEmail, account.UnverifiedRecoveryEmail, account.Deleted " + 
-			 "FROM user INNER JOIN account ON user.Id = account.Id WHERE account.Username = ? LIMIT 1;"
+			 "FROM user INNER JOIN account ON user.Id = account.Id WHERE account.UsernameCanonical = ? LIMIT 1;"
 
 	stmt, err := r.DB.PrepareContext(ctx, query)
 	if err != nil {
@@ -115,10 +115,10 @@ func (r *UserRepo) FetchByUsername(ctx context.Context, name string) (*User, err
 
 	var id int
 	var passwordHash []byte
-	var invitationEmail, displayName, email, unverifiedEmail string
+	var invitationEmail, username, displayName, email, unverifiedEmail string
 	var deleted sqltype.MyBool
 
-	err = stmt.QueryRowContext(ctx, name).Scan(&id, &invitationEmail, &displayName, &passwordHash, &email, &unverifiedEmail, &deleted)
+	err = stmt.QueryRowContext(ctx, CanonicalUsername(name)).Scan(&id, &invitationEmail, &username, &displayName, &passwordHash, &email, &unverifiedEmail, &deleted)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, errDoesNotExist
 	} else if err != nil {
@@ -128,7 +128,7 @@ func (r *UserRepo) FetchByUsername(ctx context.Context, name string) (*User, err
 	acc := &User{
 		Base: Base{
 			Id:              &id,
-			Username:        name,
+			Username:        username,
 			PasswordHash:    passwordHash,
 			Email:           email,
 			UnverifiedEmail: unverifiedEmail,
@@ -193,7 +193,7 @@ func (r *UserRepo) FetchByEmail(ctx context.Context, email string) ([]User, erro
 
 func (r *UserRepo) Update(ctx context.Context, account *User) error {
 	query := "UPDATE account, user " + 
-			 "SET account.Username = ?, user.DisplayName = ?, account.PasswordHash = ?, " +
+			 "SET account.Username = ?, account.UsernameCanonical = ?, user.DisplayName = ?, account.PasswordHash = ?, " +
 			 "account.RecoveryEmail = ?, account.UnverifiedRecoveryEmail = ?, account.Deleted = ? " +
 			 "WHERE (user.Id = ? AND user.Id = account.Id);"
 
@@ -205,6 +205,7 @@ func (r *UserRepo) Update(ctx context.Context, account *User) error {
 	_, err = stmt.ExecContext(
 		ctx,
 		account.Username,
+		CanonicalUsername(account.Username),
 		account.DisplayName,
 		account.PasswordHash,
 		account.Email,
@@ -218,7 +219,7 @@ func (r *UserRepo) Update(ctx context.Context, account *User) error {
 func (r *UserRepo) HasUsername(ctx context.Context, name string) (bool, error) {
 	var username string
 	// Fetch Random Data
-	err := r.DB.QueryRowContext(ctx, "SELECT Username FROM account WHERE Username = ? LIMIT 1;", name).Scan(&username)
+	err := r.DB.QueryRowContext(ctx, "SELECT Username FROM account WHERE UsernameCanonical = ? LIMIT 1;", CanonicalUsername(name)).Scan(&username)
 	if err != nil && err != sql.ErrNoRows {
 		return false, err
 	}
@@ -244,6 +245,9 @@ func serializeFileId(fids []string) string {
 }
 
 func unserializeFileId(fids string) []string {
+	if fids == "" {
+		return []string{}
+	}
 	return strings.Split(fids, fileIdSeparator)
 }
 
@@ -276,7 +280,7 @@ func (r *BusinessRepo) Store(ctx context.Context, account *Business, documents [
 		return 0, err
 	}
 
-	accStmt, err := tx.PrepareContext(ctx, "INSERT INTO account VALUES(NULL, ?, ?, ?, ?, ?);")
+	accStmt, err := tx.PrepareContext(ctx, "INSERT INTO account (Username, UsernameCanonical, PasswordHash, RecoveryEmail, UnverifiedRecoveryEmail, Deleted) VALUES(?, ?, ?, ?, ?, ?);")
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -286,6 +290,7 @@ func (r *BusinessRepo) Store(ctx context.Context, account *Business, documents [
 	res, err := accStmt.ExecContext(
 		ctx,
 		account.Username,
+		CanonicalUsername(account.Username),
 		account.PasswordHash,
 		account.Email,
 		account.UnverifiedEmail,
@@ -316,7 +321,7 @@ func (r *BusinessRepo) Store(ctx context.Context, account *Business, documents [
 	}
 
 	if account.ChildOf != nil {
-		pcStmt, err := tx.PrepareContext(ctx, "INSERT INTO business_parent_child VALUES(?, ?)")
+		pcStmt, err := tx.PrepareContext(ctx, "INSERT INTO business_parent_child (ParentId, ChildId) VALUES(?, ?)")
 		if err != nil {
 			tx.Rollback()
 			return 0, err
@@ -451,7 +456,7 @@ func (r *BusinessRepo) FetchById(ctx context.Context, id int) (*Business, error)
 }
 
 func (r *BusinessRepo) FetchByUsername(ctx context.Context, name string) (*Business, error) {
-	query := "SELECT acc.Id, b.DisplayName, b.DisplayNameVerified, acc.PasswordHash, " +
+	query := "SELECT acc.Id, acc.Username, b.DisplayName, b.DisplayNameVerified, acc.PasswordHash, " +
 			 "acc.RecoveryEmail, acc.UnverifiedRecoveryEmail, acc.Deleted, " +
 			 "COALESCE(bi.BusinessOfficialName, ''), COALESCE(bi.BusinessRegistrationNumber, ''), " +
 			 "COALESCE(bi.BusinessAddress, ''), COALESCE(bi.Documents, ''), COALESCE(bi.Verified, b'0'), " +
@@ -459,7 +464,7 @@ func (r *BusinessRepo) FetchByUsername(ctx context.Context, name string) (*Busin
 			 "FROM account AS acc " +
 			 "INNER JOIN business AS b ON b.Id = acc.Id " +
 			 "LEFT JOIN business_identity AS bi ON bi.Id = acc.Id " +
-			 "LEFT JOIN business_parent_child AS child ON child.ChildId = acc.Id WHERE acc.Username = ? LIMIT 1;"
+			 "LEFT JOIN business_parent_child AS child ON child.ChildId = acc.Id WHERE acc.UsernameCanonical = ? LIMIT 1;"
 
 	stmt, err := r.DB.PrepareContext(ctx, query)
 	if err != nil {
@@ -469,11 +474,12 @@ func (r *BusinessRepo) FetchByUsername(ctx context.Context, name string) (*Busin
 
 	var id, parent int
 	var passwordHash []byte
-	var displayName, email, unverifiedEmail, businessName, businessRegNum, businessAddr, businessDocuments string
+	var username, displayName, email, unverifiedEmail, businessName, businessRegNum, businessAddr, businessDocuments string
 	var deleted, businessVerified, displayNameVerified sqltype.MyBool
 
-	err = stmt.QueryRowContext(ctx, name).Scan(
+	err = stmt.QueryRowContext(ctx, CanonicalUsername(name)).Scan(
 		&id,
+		&username,
 		&displayName,
 		&displayNameVerified,
 		&passwordHash,
@@ -508,7 +514,7 @@ func (r *BusinessRepo) FetchByUsername(ctx context.Context, name string) (*Busin
 	acc := &Business{
 		Base: Base{
 			Id:              &id,
-			Username:        name,
+			Username:        username,
 			PasswordHash:    passwordHash,
 			Email:           email,
 			UnverifiedEmail: unverifiedEmail,
@@ -623,13 +629,12 @@ func (r *BusinessRepo) FetchByEmail(ctx context.Context, email string) ([]Busine
 
 func (r *BusinessRepo) Update(ctx context.Context, account *Business, documents [][]byte) error {
 	var old string
+	// business registered without identity does not have the row yet
 	err := r.DB.QueryRowContext(ctx, "SELECT bi.Documents FROM business_identity AS bi WHERE bi.Id = ? LIMIT 1;", account.Id).Scan(&old)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrAccountNotExist(ctx)
-		}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return err
 	}
+	hasIdentity := !errors.Is(err, sql.ErrNoRows)
 
 	newFID, err := r.ObjStore.FormatFID(account.Documents...)
 	if err != nil {
@@ -663,38 +668,67 @@ func (r *BusinessRepo) Update(ctx context.Context, account *Business, documents
 
 	newFID = append(newFID, addFID...)
 
-	query := "UPDATE account AS acc, business AS b, business_identity AS bi " + 
-			 "SET acc.Username = ?, b.DisplayName = ?, b.DisplayNameVerified = ?, acc.PasswordHash = ?, acc.RecoveryEmail = ?, acc.UnverifiedRecoveryEmail = ?, " +
-			 "bi.BusinessOfficialName = ?, bi.BusinessRegistrationNumber = ?, bi.BusinessAddress = ?, bi.Documents = ?, bi.Verified = ? " +
-			 "WHERE (b.Id = ? AND b.Id = acc.Id AND b.Id = bi.Id);"
-
-	stmt, err := r.DB.PrepareContext(ctx, query)
+	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.ExecContext(
+	query := "UPDATE account AS acc, business AS b " + 
+			 "SET acc.Username = ?, acc.UsernameCanonical = ?, b.DisplayName = ?, b.DisplayNameVerified = ?, acc.PasswordHash = ?, acc.RecoveryEmail = ?, acc.UnverifiedRecoveryEmail = ? " +
+			 "WHERE (b.Id = ? AND b.Id = acc.Id);"
+
+	_, err = tx.ExecContext(
 		ctx,
+		query,
 		account.Username,
+		CanonicalUsername(account.Username),
 		account.DisplayName,
 		sqltype.MyBool(account.BusinessName.Verified),
 		account.PasswordHash,
 		account.Email,
 		account.UnverifiedEmail,
-		account.BusinessIdentity.Name,
-		account.RegistrationNumber,
-		account.Address,
-		serializeFileId(newFID),
-		sqltype.MyBool(account.BusinessIdentity.Verified),
 		account.Id,
 	)
-	return err
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	identity := account.BusinessIdentity
+	if !hasIdentity &&
+	   identity.Name == "" &&
+	   identity.Address == "" &&
+	   identity.RegistrationNumber == "" &&
+	   !identity.Verified &&
+	   len(newFID) == 0 {
+		return tx.Commit()
+	}
+
+	query = "INSERT INTO business_identity VALUES(?, ?, ?, ?, ?, ?) " +
+			"ON DUPLICATE KEY UPDATE BusinessOfficialName = VALUES(BusinessOfficialName), BusinessRegistrationNumber = VALUES(BusinessRegistrationNumber), " +
+			"BusinessAddress = VALUES(BusinessAddress), Documents = VALUES(Documents), Verified = VALUES(Verified);"
+
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		account.Id,
+		identity.Name,
+		identity.RegistrationNumber,
+		identity.Address,
+		serializeFileId(newFID),
+		sqltype.MyBool(identity.Verified),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *BusinessRepo) HasUsername(ctx context.Context, name string) (bool, error) {
 	var username string
 	// Fetch Random Data
-	err := r.DB.QueryRowContext(ctx, "SELECT account.Username FROM account WHERE account.Username = ? LIMIT 1;", name).Scan(&username)
+	err := r.DB.QueryRowContext(ctx, "SELECT account.Username FROM account WHERE account.UsernameCanonical = ? LIMIT 1;", CanonicalUsername(name)).Scan(&username)
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		return false, err
 	}
@@ -129,8 +129,11 @@ func cmpObject(obj []byte, link string) (bool, error) {
 	return equal, nil
 }
 
+// The sample accounts use short passwords
+var samplePolicy = account.PasswordPolicy{account.MinPasswordLength{Length: 8}}
+
 func TestUserRepo_StoreFetchUpdate(t *testing.T) {
-	sampleAccount, err := account.NewUserAccountWithPassword("invitation@email.com", "myname", "mydisplay", "password", "personal@email.com")
+	sampleAccount, err := account.NewUserAccountWithPassword(context.Background(), samplePolicy, "invitation@email.com", "myname", "mydisplay", "password", "personal@email.com")
 	if err != nil {
 		t.Error(err)
 	}
@@ -184,7 +187,7 @@ func TestUserRepo_StoreFetchUpdate(t *testing.T) {
 }
 
 func TestUserRepo_Exist(t *testing.T) {
-	sampleAccount, err := account.NewUserAccountWithPassword("thefabulous@email.com", "thyname", "thydisplay", "password", "particle@email.com")
+	sampleAccount, err := account.NewUserAccountWithPassword(context.Background(), samplePolicy, "thefabulous@email.com", "thyname", "thydisplay", "password", "particle@email.com")
 	if err != nil {
 		t.Error(err)
 	}
@@ -252,7 +255,7 @@ func TestBusinessRepo_StoreFetchUpdate(t *testing.T) {
 		t.Error(err)
 	}
 	
-	sample, err := account.NewBusinessAccountWithPassword("mybusiness", "publicname", "password", "business@email.com", sampleIdentity)
+	sample, err := account.NewBusinessAccountWithPassword(context.Background(), samplePolicy, "mybusiness", "publicname", "password", "business@email.com", sampleIdentity)
 	if err != nil {
 		t.Error(err)
 	}
@@ -342,7 +345,7 @@ func TestBusinessRepo_Exist(t *testing.T) {
 		t.Error(err)
 	}
 	
-	sample, err := account.NewBusinessAccountWithPassword("mybusiness", "publicname", "password", "business@email.com", sampleIdentity)
+	sample, err := account.NewBusinessAccountWithPassword(context.Background(), samplePolicy, "mybusiness", "publicname", "password", "business@email.com", sampleIdentity)
 	if err != nil {
 		t.Error(err)
 	}
@@ -0,0 +1,148 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/object"
+)
+
+// Items of a business that are verified by staff
+const (
+	ReviewDisplayName = "displayname"
+	ReviewIdentity    = "identity"
+)
+
+type PendingReview struct {
+	BusinessId  int
+	Item        string
+	SubmittedAt time.Time
+}
+
+type ReviewDecision struct {
+	Id         int
+	BusinessId int
+	Item       string
+	Approved   bool
+	Reason     string
+	ReviewerId int
+	Time       time.Time
+}
+
+type ReviewRepository interface {
+	// Queue the items again if they are already pending
+	Enqueue(ctx context.Context, businessId int, items ...string) error
+	FetchPending(ctx context.Context, limit, offset int) ([]PendingReview, error)
+	IsPending(ctx context.Context, businessId int, item string) (bool, error)
+	// Remove the item from queue and record the decision
+	StoreDecision(ctx context.Context, decision *ReviewDecision) error
+	FetchDecisions(ctx context.Context, businessId int) ([]ReviewDecision, error)
+}
+
+type StaffRepository interface {
+	IsStaff(ctx context.Context, id int) (bool, error)
+}
+
+type reviewObjectStore interface {
+	object.Getter
+	object.FIDFormatter
+}
+
+// Staff verify the display name and identity of businesses
+type BusinessReviewer struct {
+	BusinessRepo BusinessAccountRepository
+	ReviewRepo   ReviewRepository
+	ObjStore     reviewObjectStore
+	Ext          ExternalComm
+}
+
+func (r *BusinessReviewer) Pending(ctx context.Context, limit, offset int) ([]PendingReview, error) {
+	return r.ReviewRepo.FetchPending(ctx, limit, offset)
+}
+
+func (r *BusinessReviewer) Business(ctx context.Context, businessId int) (*Business, []ReviewDecision, error) {
+	acc, err := r.BusinessRepo.FetchById(ctx, businessId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decisions, err := r.ReviewRepo.FetchDecisions(ctx, businessId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return acc, decisions, nil
+}
+
+// Only the documents submitted by the business can be fetched
+func (r *BusinessReviewer) Document(ctx context.Context, businessId int, fid string) ([]byte, error) {
+	acc, err := r.BusinessRepo.FetchById(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+
+	fids, err := r.ObjStore.FormatFID(acc.Documents...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range fids {
+		if v == fid {
+			return r.ObjStore.Get(ctx, fid)
+		}
+	}
+	return nil, ErrDocumentNotExist(ctx)
+}
+
+func (r *BusinessReviewer) Decide(ctx context.Context, reviewerId, businessId int, item string, approved bool, reason string) error {
+	if item != ReviewDisplayName && item != ReviewIdentity {
+		return ErrReviewNotPending(ctx)
+	}
+
+	pending, err := r.ReviewRepo.IsPending(ctx, businessId, item)
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return ErrReviewNotPending(ctx)
+	}
+
+	acc, err := r.BusinessRepo.FetchById(ctx, businessId)
+	if err != nil {
+		return err
+	}
+
+	// the identity row is only created when the business submits its identity
+	identity := acc.BusinessIdentity
+	if item == ReviewIdentity && identity.Name == "" && identity.RegistrationNumber == "" && identity.Address == "" && len(identity.Documents) == 0 {
+		return ErrIdentityNotExist(ctx)
+	}
+
+	if item == ReviewDisplayName {
+		acc.BusinessName.Verified = approved
+	} else {
+		acc.BusinessIdentity.Verified = approved
+	}
+
+	err = r.BusinessRepo.Update(ctx, acc, nil)
+	if err != nil {
+		return err
+	}
+
+	err = r.ReviewRepo.StoreDecision(ctx, &ReviewDecision{
+		BusinessId: businessId,
+		Item:       item,
+		Approved:   approved,
+		Reason:     reason,
+		ReviewerId: reviewerId,
+		Time:       time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// business without verified email cannot be notified
+	if acc.Email == "" {
+		return nil
+	}
+	return r.Ext.NotifyReviewDecision(ctx, acc.Email, acc.Username, item, approved, reason)
+}
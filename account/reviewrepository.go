@@ -0,0 +1,146 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+type ReviewRepo struct {
+	DB *sql.DB
+}
+
+type StaffRepo struct {
+	DB *sql.DB
+}
+
+func (r *ReviewRepo) Enqueue(ctx context.Context, businessId int, items ...string) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO business_review_queue VALUES "
+	vars := []interface{}{}
+
+	now := time.Now()
+	for _, v := range items {
+		query += "(?, ?, ?),"
+		vars = append(vars, businessId, v, now)
+	}
+
+	query = strings.TrimSuffix(query, ",")
+	query += " ON DUPLICATE KEY UPDATE SubmittedAt = VALUES(SubmittedAt);"
+
+	_, err := r.DB.ExecContext(ctx, query, vars...)
+	return err
+}
+
+// Oldest submission first
+func (r *ReviewRepo) FetchPending(ctx context.Context, limit, offset int) ([]PendingReview, error) {
+	query := "SELECT BusinessId, Item, SubmittedAt FROM business_review_queue " +
+			 "ORDER BY SubmittedAt ASC LIMIT ? OFFSET ?;"
+
+	rows, err := r.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pending := make([]PendingReview, 0)
+	for rows.Next() {
+		var v PendingReview
+		err = rows.Scan(&v.BusinessId, &v.Item, &v.SubmittedAt)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, v)
+	}
+	return pending, rows.Err()
+}
+
+func (r *ReviewRepo) IsPending(ctx context.Context, businessId int, item string) (bool, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, "SELECT BusinessId FROM business_review_queue WHERE BusinessId = ? AND Item = ? LIMIT 1;", businessId, item).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	return !errors.Is(err, sql.ErrNoRows), nil
+}
+
+func (r *ReviewRepo) StoreDecision(ctx context.Context, decision *ReviewDecision) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM business_review_queue WHERE BusinessId = ? AND Item = ?;", decision.BusinessId, decision.Item)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO business_review VALUES(NULL, ?, ?, ?, ?, ?, ?);",
+		decision.BusinessId,
+		decision.Item,
+		sqltype.MyBool(decision.Approved),
+		decision.Reason,
+		decision.ReviewerId,
+		decision.Time,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	decision.Id = int(lastId)
+
+	return tx.Commit()
+}
+
+// Latest decision first
+func (r *ReviewRepo) FetchDecisions(ctx context.Context, businessId int) ([]ReviewDecision, error) {
+	query := "SELECT Id, Item, Approved, Reason, ReviewerId, Time FROM business_review " +
+			 "WHERE BusinessId = ? ORDER BY Time DESC;"
+
+	rows, err := r.DB.QueryContext(ctx, query, businessId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := make([]ReviewDecision, 0)
+	for rows.Next() {
+		var approved sqltype.MyBool
+		v := ReviewDecision{BusinessId: businessId}
+
+		err = rows.Scan(&v.Id, &v.Item, &approved, &v.Reason, &v.ReviewerId, &v.Time)
+		if err != nil {
+			return nil, err
+		}
+		v.Approved = bool(approved)
+		decisions = append(decisions, v)
+	}
+	return decisions, rows.Err()
+}
+
+func (r *StaffRepo) IsStaff(ctx context.Context, id int) (bool, error) {
+	var staffId int
+	err := r.DB.QueryRowContext(ctx, "SELECT Id FROM staff WHERE Id = ? LIMIT 1;", id).Scan(&staffId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	return !errors.Is(err, sql.ErrNoRows), nil
+}
@@ -55,6 +55,35 @@ type baseDiemTransactionRepository interface {
 	UpdateDiem(context.Context, ...DiemTransaction) error
 }
 
+// Told about transactions found on blockchain after they are stored
+type TransactionNotifier interface {
+	NotifyDiem(ctx context.Context, event string, txs ...DiemTransaction) error
+	NotifyCelo(ctx context.Context, event string, txs ...CeloTransaction) error
+}
+
+// Tell every notifier in order, stop at the first error
+type TransactionNotifiers []TransactionNotifier
+
+func (n TransactionNotifiers) NotifyDiem(ctx context.Context, event string, txs ...DiemTransaction) error {
+	for _, v := range n {
+		err := v.NotifyDiem(ctx, event, txs...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n TransactionNotifiers) NotifyCelo(ctx context.Context, event string, txs ...CeloTransaction) error {
+	for _, v := range n {
+		err := v.NotifyCelo(ctx, event, txs...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type TransactionRepository interface {
 	baseDiemTransactionRepository
 	baseCeloTransactionRepository
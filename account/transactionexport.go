@@ -0,0 +1,499 @@
+package account
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+const (
+	ExportCSV = "csv"
+	ExportOFX = "ofx"
+	ExportQIF = "qif"
+)
+
+// Diem amounts are in micro units and Celo tokens use 18 decimals like ERC-20 tokens
+const (
+	diemDecimals = 6
+	celoDecimals = 18
+)
+
+// Symbol and decimals of a currency in exported statements
+type ExportCurrency struct {
+	Symbol   string
+	Decimals int
+}
+
+type exportWalletRepository interface {
+	FetchByAccount(ctx context.Context, accountId int) ([]wallet.Wallet, error)
+}
+
+// Statements of the transaction history for accounting software
+type TransactionExport struct {
+	HistoryRepo TransactionHistoryRepository
+	WalletRepo  exportWalletRepository
+	// Keyed by Diem currency code or lowercase Celo token address, other currencies use the code or
+	// address as symbol and the default decimals of the chain
+	Currencies  map[string]ExportCurrency
+	// Entries fetched per query, the statement is written as it is fetched
+	BatchSize   int
+	MaxRange    time.Duration
+}
+
+// Entries from since and before until
+type ExportForm struct {
+	Format string
+	Since  time.Time
+	Until  time.Time
+}
+
+// A checked export that is ready to be written
+type Statement struct {
+	ExportForm
+	AccountId int
+	export    *TransactionExport
+	// Chain and lowercase address of the wallets of the account
+	wallets   map[wallet.Address]bool
+}
+
+func (e *TransactionExport) Statement(ctx context.Context, accountId int, form ExportForm) (*Statement, error) {
+	if form.Format != ExportCSV && form.Format != ExportOFX && form.Format != ExportQIF {
+		return nil, ErrExportFormat(ctx)
+	}
+	if !form.Since.Before(form.Until) || form.Until.Sub(form.Since) > e.MaxRange {
+		return nil, ErrExportRange(ctx)
+	}
+
+	wallets, err := e.WalletRepo.FetchByAccount(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Statement{ExportForm: form, AccountId: accountId, export: e, wallets: make(map[wallet.Address]bool)}
+	for _, v := range wallets {
+		s.wallets[wallet.Address{Chain: v.Chain, Hex: strings.ToLower(v.Hex)}] = true
+	}
+	return s, nil
+}
+
+func (s *Statement) ContentType() string {
+	switch s.Format {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportOFX:
+		return "application/x-ofx"
+	default:
+		return "application/qif"
+	}
+}
+
+func (s *Statement) FileName() string {
+	return fmt.Sprintf("transactions-%s-%s.%s", s.Since.UTC().Format("20060102"), s.Until.UTC().Format("20060102"), s.Format)
+}
+
+// A transfer as written in the statement, amounts are scaled by the decimals of the currency
+type statementLine struct {
+	TransactionEntry
+	Incoming   bool
+	Outgoing   bool
+	Symbol     string
+	Amount     string
+	// Negative for outgoing transfers and zero for transfers between wallets of the account
+	Net        string
+	// Empty unless the account paid the fees of the transaction
+	Fee        string
+	GatewayFee string
+	FeeSymbol  string
+}
+
+func (l *statementLine) Direction() string {
+	switch {
+	case l.Incoming && l.Outgoing:
+		return "internal"
+	case l.Outgoing:
+		return "out"
+	default:
+		return "in"
+	}
+}
+
+// Sender and account remarks in one line
+func (l *statementLine) Memo() string {
+	memo := make([]string, 0, 2)
+	if l.TransactionSenderRemark.Message != "" {
+		memo = append(memo, l.TransactionSenderRemark.Message)
+	}
+	if l.TransactionAccountRemark.Message != "" {
+		memo = append(memo, l.TransactionAccountRemark.Message)
+	}
+	return strings.Join(memo, " | ")
+}
+
+func (l *statementLine) Counterparty() string {
+	if l.Outgoing && !l.Incoming {
+		return l.To
+	}
+	return l.From
+}
+
+// Unique id of the transfer
+func (l *statementLine) Id() string {
+	return fmt.Sprintf("%s-%d-%d-%d", l.Chain, l.Version, l.Index, l.TransferId.Transfer)
+}
+
+func (e *TransactionExport) currency(chain, currency string) ExportCurrency {
+	if chain == blockchain.DiemChain {
+		if v, ok := e.Currencies[currency]; ok {
+			return v
+		}
+		return ExportCurrency{Symbol: currency, Decimals: diemDecimals}
+	}
+	if v, ok := e.Currencies[strings.ToLower(currency)]; ok {
+		return v
+	}
+	// fees in the native token have no fee currency
+	if currency == "" {
+		return ExportCurrency{Symbol: "CELO", Decimals: celoDecimals}
+	}
+	return ExportCurrency{Symbol: currency, Decimals: celoDecimals}
+}
+
+// Decimal string of the amount in whole units, trailing zeros after the second decimal are removed
+func FormatAmount(amount *big.Int, decimals int) string {
+	if amount == nil {
+		return ""
+	}
+
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	whole, fraction := digits[:len(digits)-decimals], digits[len(digits)-decimals:]
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) < 2 && decimals >= 2 {
+		fraction += strings.Repeat("0", 2-len(fraction))
+	}
+
+	s := whole
+	if fraction != "" {
+		s += "." + fraction
+	}
+	if amount.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+func (s *Statement) line(v TransactionEntry, paidFee bool) statementLine {
+	l := statementLine{
+		TransactionEntry: v,
+		Incoming:         s.wallets[wallet.Address{Chain: v.Chain, Hex: strings.ToLower(v.To)}],
+		Outgoing:         s.wallets[wallet.Address{Chain: v.Chain, Hex: strings.ToLower(v.From)}],
+	}
+
+	currency := s.export.currency(v.Chain, v.Currency)
+	l.Symbol = currency.Symbol
+	l.Amount = FormatAmount(v.Amount, currency.Decimals)
+
+	net := new(big.Int)
+	if v.Amount != nil && l.Incoming != l.Outgoing {
+		net.Set(v.Amount)
+		if l.Outgoing {
+			net.Neg(net)
+		}
+	}
+	l.Net = FormatAmount(net, currency.Decimals)
+
+	// fees are paid by the sender once per transaction
+	if l.Outgoing && !paidFee {
+		feeCurrency := s.export.currency(v.Chain, v.FeeCurrency)
+		l.FeeSymbol = feeCurrency.Symbol
+		if v.Fee != nil && v.Fee.Sign() > 0 {
+			l.Fee = FormatAmount(v.Fee, feeCurrency.Decimals)
+		}
+		if v.GatewayFee != nil && v.GatewayFee.Sign() > 0 {
+			l.GatewayFee = FormatAmount(v.GatewayFee, feeCurrency.Decimals)
+		}
+	}
+	return l
+}
+
+type statementWriter interface {
+	header(s *Statement) error
+	line(l statementLine) error
+	footer(s *Statement) error
+}
+
+// Write the statement as it is fetched, the writer is flushed after every batch
+func (s *Statement) Write(ctx context.Context, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	var out statementWriter
+	switch s.Format {
+	case ExportCSV:
+		out = &csvStatement{w: csv.NewWriter(buf)}
+	case ExportOFX:
+		out = &ofxStatement{w: buf}
+	default:
+		out = &qifStatement{w: buf}
+	}
+
+	flush := func() error {
+		if v, ok := out.(*csvStatement); ok {
+			v.w.Flush()
+			if err := v.w.Error(); err != nil {
+				return err
+			}
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+		return nil
+	}
+
+	err := out.header(s)
+	if err != nil {
+		return err
+	}
+
+	since, until := s.Since, s.Until
+	query := TransactionQuery{
+		AccountId:         s.AccountId,
+		TransactionFilter: TransactionFilter{Since: &since, Until: &until},
+		Limit:             s.export.BatchSize,
+	}
+	var last *wallet.TransactionId
+	for {
+		entries, err := s.export.HistoryRepo.FetchHistory(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range entries {
+			// transfers of a transaction are next to each other in the history
+			paidFee := last != nil && *last == v.TransactionId
+			err = out.line(s.line(v, paidFee))
+			if err != nil {
+				return err
+			}
+			if s.wallets[wallet.Address{Chain: v.Chain, Hex: strings.ToLower(v.From)}] {
+				id := v.TransactionId
+				last = &id
+			}
+		}
+
+		err = flush()
+		if err != nil {
+			return err
+		}
+		if len(entries) < query.Limit {
+			break
+		}
+
+		end := entries[len(entries)-1]
+		query.After = &TransactionCursor{Time: end.Time, TransferId: end.TransferId}
+	}
+
+	err = out.footer(s)
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+type csvStatement struct {
+	w *csv.Writer
+}
+
+func (c *csvStatement) header(s *Statement) error {
+	return c.w.Write([]string{
+		"Time", "Chain", "Version", "Index", "Transfer", "Hash", "Status", "Direction", "From", "To",
+		"Currency", "Amount", "Net", "Fee", "Gateway Fee", "Fee Currency", "Sender Remark", "Account Remark", "Refund",
+	})
+}
+
+// Spreadsheets run cells that start with these as formulas, text from the chain or the users is quoted so it stays text
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func (c *csvStatement) line(l statementLine) error {
+	return c.w.Write([]string{
+		l.Time.UTC().Format(time.RFC3339),
+		l.Chain,
+		strconv.FormatUint(l.Version, 10),
+		strconv.Itoa(l.Index),
+		strconv.Itoa(l.TransferId.Transfer),
+		csvText(l.Hash),
+		csvText(l.Status),
+		l.Direction(),
+		csvText(l.From),
+		csvText(l.To),
+		csvText(l.Symbol),
+		l.Amount,
+		l.Net,
+		l.Fee,
+		l.GatewayFee,
+		csvText(l.FeeSymbol),
+		csvText(l.TransactionSenderRemark.Message),
+		csvText(l.TransactionAccountRemark.Message),
+		strconv.FormatBool(l.IsRefund),
+	})
+}
+
+func (c *csvStatement) footer(s *Statement) error {
+	return nil
+}
+
+// OFX 2.x bank statement, the currencies of the transfers are not ISO 4217 so the statement
+// has no default currency and every transfer names its own
+type ofxStatement struct {
+	w io.Writer
+}
+
+const ofxTime = "20060102150405.000[+0:UTC]"
+
+func ofxText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// OFX limits names to 32 characters
+func ofxName(s string) string {
+	if len(s) > 32 {
+		return s[:32]
+	}
+	return s
+}
+
+func (o *ofxStatement) header(s *Statement) error {
+	now := time.Now().UTC().Format(ofxTime)
+	_, err := fmt.Fprintf(
+		o.w,
+		"<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n"+
+			"<?OFX OFXHEADER=\"200\" VERSION=\"220\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n"+
+			"<OFX>\n"+
+			"<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>"+
+			"<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>\n"+
+			"<BANKMSGSRSV1><STMTTRNRS><TRNUID>0</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n"+
+			"<STMTRS><CURDEF>XXX</CURDEF>\n"+
+			"<BANKACCTFROM><BANKID>LIBRA</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>\n"+
+			"<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n",
+		now, s.AccountId, s.Since.UTC().Format(ofxTime), s.Until.UTC().Format(ofxTime),
+	)
+	return err
+}
+
+func (o *ofxStatement) transaction(kind, id string, t time.Time, amount, name, memo, symbol string) error {
+	_, err := fmt.Fprintf(
+		o.w,
+		"<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID>"+
+			"<NAME>%s</NAME><MEMO>%s</MEMO><CURRENCY><CURRATE>1</CURRATE><CURSYM>%s</CURSYM></CURRENCY></STMTTRN>\n",
+		kind, t.UTC().Format(ofxTime), amount, ofxText(id), ofxText(ofxName(name)), ofxText(memo), ofxText(symbol),
+	)
+	return err
+}
+
+// Fees are separate transactions so the transfer amount stays as sent
+func (o *ofxStatement) line(l statementLine) error {
+	kind := "CREDIT"
+	if l.Outgoing {
+		kind = "DEBIT"
+	}
+	if l.Incoming && l.Outgoing {
+		kind = "XFER"
+	}
+
+	err := o.transaction(kind, l.Id(), l.Time, l.Net, l.Counterparty(), l.Memo(), l.Symbol)
+	if err != nil {
+		return err
+	}
+	if l.Fee != "" {
+		err = o.transaction("FEE", l.Id()+"-fee", l.Time, "-"+l.Fee, l.Chain, "Gas fee "+l.Hash, l.FeeSymbol)
+		if err != nil {
+			return err
+		}
+	}
+	if l.GatewayFee != "" {
+		return o.transaction("SRVCHG", l.Id()+"-gateway", l.Time, "-"+l.GatewayFee, l.Chain, "Gateway fee "+l.Hash, l.FeeSymbol)
+	}
+	return nil
+}
+
+// Balances are not part of the history so the ledger balance is 0
+func (o *ofxStatement) footer(s *Statement) error {
+	_, err := fmt.Fprintf(
+		o.w,
+		"</BANKTRANLIST>\n<LEDGERBAL><BALAMT>0</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n"+
+			"</STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n",
+		s.Until.UTC().Format(ofxTime),
+	)
+	return err
+}
+
+// QIF bank account, the currency is added to the memo since QIF has no currency field
+type qifStatement struct {
+	w io.Writer
+}
+
+// Lines of QIF cannot contain a new line
+func qifText(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+func (q *qifStatement) header(s *Statement) error {
+	_, err := io.WriteString(q.w, "!Type:Bank\n")
+	return err
+}
+
+func (q *qifStatement) transaction(t time.Time, amount, ref, payee, memo string) error {
+	_, err := fmt.Fprintf(
+		q.w,
+		"D%s\nT%s\nN%s\nP%s\nM%s\n^\n",
+		t.UTC().Format("01/02/2006"), amount, qifText(ref), qifText(payee), qifText(memo),
+	)
+	return err
+}
+
+func (q *qifStatement) line(l statementLine) error {
+	memo := l.Symbol
+	if m := l.Memo(); m != "" {
+		memo += " " + m
+	}
+
+	err := q.transaction(l.Time, l.Net, l.Id(), l.Counterparty(), memo)
+	if err != nil {
+		return err
+	}
+	if l.Fee != "" {
+		err = q.transaction(l.Time, "-"+l.Fee, l.Id()+"-fee", l.Chain, l.FeeSymbol+" Gas fee "+l.Hash)
+		if err != nil {
+			return err
+		}
+	}
+	if l.GatewayFee != "" {
+		return q.transaction(l.Time, "-"+l.GatewayFee, l.Id()+"-gateway", l.Chain, l.FeeSymbol+" Gateway fee "+l.Hash)
+	}
+	return nil
+}
+
+func (q *qifStatement) footer(s *Statement) error {
+	return nil
+}
@@ -0,0 +1,176 @@
+package account_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type accountWalletRepo []wallet.Wallet
+
+func (r accountWalletRepo) FetchByAccount(ctx context.Context, accountId int) ([]wallet.Wallet, error) {
+	return r, nil
+}
+
+func TestFormatAmount(t *testing.T) {
+	cases := []struct {
+		amount   *big.Int
+		decimals int
+		want     string
+	}{
+		{big.NewInt(1500000), 6, "1.50"},
+		{big.NewInt(1), 6, "0.000001"},
+		{big.NewInt(-25), 2, "-0.25"},
+		{big.NewInt(0), 18, "0.00"},
+		{new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil), 18, "1.00"},
+	}
+	for _, v := range cases {
+		if got := account.FormatAmount(v.amount, v.decimals); got != v.want {
+			t.Errorf("FormatAmount(%s, %d) = %s, want %s", v.amount, v.decimals, got, v.want)
+		}
+	}
+}
+
+func TestTransactionExport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	own := "0xAbC"
+
+	repo := &memoryHistoryRepo{}
+	// an outgoing Celo transaction with two transfers and an incoming Diem transfer
+	for i, v := range []struct {
+		chain, from, to, currency string
+		transfer                  int
+	}{
+		{blockchain.CeloChain, own, "0xdef", "0x765de816845861e75a25fca122bb6898b8b1282a", 1},
+		{blockchain.CeloChain, own, "0x123", "0x765de816845861e75a25fca122bb6898b8b1282a", 0},
+		{blockchain.DiemChain, "payer", "diemaddr", "XUS", 0},
+	} {
+		entry := account.TransactionEntry{Time: now.Add(-time.Duration(i) * time.Minute), Fee: big.NewInt(2000), GatewayFee: big.NewInt(0)}
+		entry.TransferId = account.TransferId{TransactionId: wallet.TransactionId{Version: uint64(10 - i/2), Chain: v.chain}, Transfer: v.transfer}
+		entry.Transfer = wallet.Transfer{Currency: v.currency, From: v.from, To: v.to, Amount: big.NewInt(2500000)}
+		entry.TransactionSenderRemark.Message = "order 42"
+		repo.entries = append(repo.entries, entry)
+	}
+
+	export := account.TransactionExport{
+		HistoryRepo: repo,
+		WalletRepo: accountWalletRepo{
+			{Address: wallet.Address{Chain: blockchain.CeloChain, Hex: "0xabc"}},
+			{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "diemaddr"}},
+		},
+		Currencies: map[string]account.ExportCurrency{"0x765de816845861e75a25fca122bb6898b8b1282a": {Symbol: "cUSD", Decimals: 18}},
+		BatchSize:  2,
+		MaxRange:   24 * time.Hour,
+	}
+	form := account.ExportForm{Format: account.ExportCSV, Since: now.Add(-time.Hour), Until: now.Add(time.Minute)}
+
+	statement, err := export.Statement(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = statement.Write(ctx, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected a header and 3 rows, got %d", len(rows))
+	}
+	// time, chain, version, index, transfer, hash, status, direction, from, to, currency, amount, net, fee, gateway fee, fee currency
+	if rows[1][7] != "out" || rows[1][10] != "cUSD" || rows[1][12] != "-0.0000000000025" || rows[1][13] != "0.000000000000002" || rows[1][14] != "" {
+		t.Errorf("unexpected outgoing row %v", rows[1])
+	}
+	if rows[2][13] != "" {
+		t.Errorf("fee of the transaction is repeated %v", rows[2])
+	}
+	if rows[3][7] != "in" || rows[3][12] != "2.50" || rows[3][13] != "" || rows[3][16] != "order 42" {
+		t.Errorf("unexpected incoming row %v", rows[3])
+	}
+
+	for _, format := range []string{account.ExportOFX, account.ExportQIF} {
+		form.Format = format
+		statement, err := export.Statement(ctx, 1, form)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Reset()
+		err = statement.Write(ctx, &out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(out.String(), "2.50") != 1 || strings.Count(out.String(), "order 42") != 3 {
+			t.Errorf("unexpected %s statement\n%s", format, out.String())
+		}
+	}
+
+	form.Format = "pdf"
+	_, err = export.Statement(ctx, 1, form)
+	if err == nil {
+		t.Error("unknown format is accepted")
+	}
+
+	form = account.ExportForm{Format: account.ExportCSV, Since: now.Add(-48 * time.Hour), Until: now}
+	_, err = export.Statement(ctx, 1, form)
+	if err == nil {
+		t.Error("period longer than the maximum range is accepted")
+	}
+}
+
+func TestTransactionExportCSVFormula(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	entry := account.TransactionEntry{Time: now, Fee: big.NewInt(0), GatewayFee: big.NewInt(0)}
+	entry.TransferId = account.TransferId{TransactionId: wallet.TransactionId{Version: 1, Chain: blockchain.DiemChain}}
+	entry.Transfer = wallet.Transfer{Currency: "XUS", From: "=HYPERLINK(\"http://example.com\")", To: "diemaddr", Amount: big.NewInt(1000000)}
+	entry.TransactionSenderRemark.Message = "+1+cmd|' /C calc'!A0"
+	entry.TransactionAccountRemark.Message = "@SUM(A1:A2)"
+
+	export := account.TransactionExport{
+		HistoryRepo: &memoryHistoryRepo{entries: []account.TransactionEntry{entry}},
+		WalletRepo:  accountWalletRepo{{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "diemaddr"}}},
+		BatchSize:   2,
+		MaxRange:    24 * time.Hour,
+	}
+	form := account.ExportForm{Format: account.ExportCSV, Since: now.Add(-time.Hour), Until: now.Add(time.Minute)}
+
+	statement, err := export.Statement(ctx, 1, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = statement.Write(ctx, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected a header and 1 row, got %d", len(rows))
+	}
+	for _, i := range []int{8, 16, 17} {
+		if !strings.HasPrefix(rows[1][i], "'") {
+			t.Errorf("formula in column %d is not quoted: %s", i, rows[1][i])
+		}
+	}
+	if rows[1][9] != "diemaddr" || rows[1][12] != "1.00" {
+		t.Errorf("plain cells are changed %v", rows[1])
+	}
+}
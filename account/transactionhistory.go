@@ -0,0 +1,140 @@
+package account
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// An entry of the transaction feed, Celo transactions have one entry per transfer event
+type TransactionEntry struct {
+	TransferId
+	Time   time.Time
+	Status string
+	Hash   string
+	// Gas fee of the transaction, repeated for every transfer of a Celo transaction
+	Fee         *big.Int
+	// Empty for the native token of Celo
+	FeeCurrency string
+	GatewayFee  *big.Int
+	wallet.Transfer
+	wallet.TransactionSenderRemark
+	TransactionAccountRemark
+}
+
+// Position in the feed, entries are ordered by time, chain, version, index and transfer, latest first
+type TransactionCursor struct {
+	Time time.Time `json:"t"`
+	TransferId
+}
+
+func (c TransactionCursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func ParseTransactionCursor(ctx context.Context, s string) (*TransactionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrTransactionCursor(ctx)
+	}
+
+	var c TransactionCursor
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, ErrTransactionCursor(ctx)
+	}
+	return &c, nil
+}
+
+// Empty fields are not filtered, amounts are in the smallest unit of the currency
+type TransactionFilter struct {
+	Chain        string
+	Currency     string
+	MinAmount    *big.Int
+	MaxAmount    *big.Int
+	// Sender or receiver on the other side of the transfer
+	Counterparty string
+	// Entries from since and before until
+	Since        *time.Time
+	Until        *time.Time
+	Status       string
+	Refund       *bool
+	// Words in the sender or account remark
+	Remark       string
+}
+
+func (f *TransactionFilter) check(ctx context.Context) error {
+	if f.Chain != "" && f.Chain != blockchain.DiemChain && f.Chain != blockchain.CeloChain {
+		return ErrTransactionFilter(ctx)
+	}
+	if (f.MinAmount != nil && f.MinAmount.Sign() < 0) || (f.MaxAmount != nil && f.MaxAmount.Sign() < 0) {
+		return ErrTransactionFilter(ctx)
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && f.MinAmount.Cmp(f.MaxAmount) > 0 {
+		return ErrTransactionFilter(ctx)
+	}
+	if f.Since != nil && f.Until != nil && !f.Since.Before(*f.Until) {
+		return ErrTransactionFilter(ctx)
+	}
+	return nil
+}
+
+type TransactionQuery struct {
+	AccountId int
+	TransactionFilter
+	// Entries after the cursor, from the latest entry if nil
+	After     *TransactionCursor
+	Limit     int
+}
+
+type TransactionHistoryRepository interface {
+	// Entries sent from or to the wallets of the account, latest first
+	FetchHistory(ctx context.Context, query TransactionQuery) ([]TransactionEntry, error)
+}
+
+// Diem and Celo transactions of an account merged into one feed
+type TransactionHistory struct {
+	HistoryRepo TransactionHistoryRepository
+	MaxLimit    int
+}
+
+// Returns the cursor of the next page, empty if there is no more entry
+func (h *TransactionHistory) Page(ctx context.Context, accountId int, filter TransactionFilter, cursor string, limit int) ([]TransactionEntry, string, error) {
+	err := filter.check(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if limit <= 0 || limit > h.MaxLimit {
+		limit = h.MaxLimit
+	}
+
+	query := TransactionQuery{AccountId: accountId, TransactionFilter: filter, Limit: limit + 1}
+	if cursor != "" {
+		after, err := ParseTransactionCursor(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query.After = after
+	}
+
+	entries, err := h.HistoryRepo.FetchHistory(ctx, query)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// one more entry is fetched to know if there is a next page
+	if len(entries) <= limit {
+		return entries, "", nil
+	}
+
+	entries = entries[:limit]
+	last := entries[limit-1]
+	return entries, TransactionCursor{Time: last.Time, TransferId: last.TransferId}.String(), nil
+}
@@ -0,0 +1,73 @@
+package account_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// Entries are kept latest first like the feed
+type memoryHistoryRepo struct {
+	entries []account.TransactionEntry
+}
+
+func (r *memoryHistoryRepo) FetchHistory(ctx context.Context, query account.TransactionQuery) ([]account.TransactionEntry, error) {
+	entries := make([]account.TransactionEntry, 0)
+	after := query.After == nil
+	for _, v := range r.entries {
+		if after && len(entries) < query.Limit {
+			entries = append(entries, v)
+		}
+		if !after && v.TransferId == query.After.TransferId {
+			after = true
+		}
+	}
+	return entries, nil
+}
+
+func TestTransactionHistoryPage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	repo := &memoryHistoryRepo{}
+	for i := 0; i < 5; i++ {
+		entry := account.TransactionEntry{Time: now.Add(-time.Duration(i) * time.Minute)}
+		entry.TransferId = account.TransferId{TransactionId: wallet.TransactionId{Version: uint64(5 - i), Chain: blockchain.CeloChain}}
+		repo.entries = append(repo.entries, entry)
+	}
+	history := account.TransactionHistory{HistoryRepo: repo, MaxLimit: 10}
+
+	seen := make([]uint64, 0)
+	cursor := ""
+	for {
+		entries, next, err := history.Page(ctx, 1, account.TransactionFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range entries {
+			seen = append(seen, v.Version)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	if len(seen) != 5 || seen[0] != 5 || seen[4] != 1 {
+		t.Errorf("unexpected feed %v", seen)
+	}
+
+	_, _, err := history.Page(ctx, 1, account.TransactionFilter{}, "not a cursor", 2)
+	if err == nil {
+		t.Error("invalid cursor is accepted")
+	}
+
+	_, _, err = history.Page(ctx, 1, account.TransactionFilter{MinAmount: big.NewInt(10), MaxAmount: big.NewInt(1)}, "", 2)
+	if err == nil {
+		t.Error("minimum amount above the maximum is accepted")
+	}
+}
@@ -0,0 +1,151 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+// Both chains are selected with the same columns so they can be merged and ordered in one query,
+// the transfer table is aliased as tr
+const (
+	diemHistoryQuery = "SELECT " +
+		"t.Chain, t.Version, t.Index, 0 AS Transfer, " +
+		"t.Time, t.Status, t.Hash, " +
+		"t.GasPrice * t.GasUsed AS Fee, tr.GasCurrency AS FeeCurrency, NULL AS GatewayFee, " +
+		"tr.Currency, tr.Amount, tr.From, tr.To, " +
+		"COALESCE(s.Message, '') AS SenderMessage, COALESCE(s.Refund, b'0') AS Refund, " +
+		"COALESCE(c.Message, '') AS AccountMessage " +
+		"FROM transaction AS t " +
+		"INNER JOIN transaction_diem AS tr ON tr.Version = t.Version AND tr.Chain = t.Chain AND tr.Index = t.Index "
+
+	celoHistoryQuery = "SELECT " +
+		"t.Chain, t.Version, t.Index, tr.LogIndex AS Transfer, " +
+		"t.Time, t.Status, t.Hash, " +
+		"t.GasPrice * t.GasUsed AS Fee, cx.GatewayCurrency AS FeeCurrency, cx.GatewayFee, " +
+		"tr.Currency, tr.Amount, tr.From, tr.To, " +
+		"COALESCE(s.Message, '') AS SenderMessage, COALESCE(s.Refund, b'0') AS Refund, " +
+		"COALESCE(c.Message, '') AS AccountMessage " +
+		"FROM transaction AS t " +
+		"INNER JOIN transaction_celo AS cx ON cx.Version = t.Version AND cx.Chain = t.Chain AND cx.Index = t.Index " +
+		"INNER JOIN transaction_celo_transfer AS tr ON tr.Version = t.Version AND tr.Chain = t.Chain AND tr.Index = t.Index "
+
+	remarkHistoryJoin = "LEFT JOIN transaction_sender AS s ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
+		"LEFT JOIN transaction_context AS c ON c.Version = t.Version AND c.Chain = t.Chain AND c.Index = t.Index AND c.AccountId = ? "
+)
+
+// Select a page of the entries of the chain that match the filter, every condition uses an index of the tables.
+// The cursor and the limit are applied to each chain so the merged page only reads the rows it can return
+func historySubquery(base, transfer, chain string, q TransactionQuery) (string, []interface{}) {
+	query := base + remarkHistoryJoin +
+		"WHERE t.Chain = ? " +
+		"AND (tr.From IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?) " +
+		"OR tr.To IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?))"
+	args := []interface{}{q.AccountId, chain, chain, q.AccountId, chain, q.AccountId}
+
+	conditions := make([]string, 0)
+	if q.Currency != "" {
+		conditions = append(conditions, "tr.Currency = ?")
+		args = append(args, q.Currency)
+	}
+	// amounts are compared as decimals so they are not limited to 64 bits
+	if q.MinAmount != nil {
+		conditions = append(conditions, "tr.Amount >= CAST(? AS DECIMAL(65, 0))")
+		args = append(args, q.MinAmount.String())
+	}
+	if q.MaxAmount != nil {
+		conditions = append(conditions, "tr.Amount <= CAST(? AS DECIMAL(65, 0))")
+		args = append(args, q.MaxAmount.String())
+	}
+	if q.Counterparty != "" {
+		conditions = append(conditions, "(tr.From = ? OR tr.To = ?)")
+		args = append(args, q.Counterparty, q.Counterparty)
+	}
+	if q.Since != nil {
+		conditions = append(conditions, "t.Time >= ?")
+		args = append(args, *q.Since)
+	}
+	if q.Until != nil {
+		conditions = append(conditions, "t.Time < ?")
+		args = append(args, *q.Until)
+	}
+	if q.Status != "" {
+		conditions = append(conditions, "t.Status = ?")
+		args = append(args, q.Status)
+	}
+	if q.Refund != nil {
+		conditions = append(conditions, "COALESCE(s.Refund, b'0') = ?")
+		args = append(args, sqltype.MyBool(*q.Refund))
+	}
+	if q.Remark != "" {
+		conditions = append(conditions, "(MATCH (s.Message) AGAINST (?) OR MATCH (c.Message) AGAINST (?))")
+		args = append(args, q.Remark, q.Remark)
+	}
+
+	if q.After != nil {
+		conditions = append(conditions, "(t.Time, t.Chain, t.Version, t.Index, "+transfer+") < (?, ?, ?, ?, ?)")
+		args = append(args, q.After.Time, q.After.Chain, q.After.Version, q.After.Index, q.After.Transfer)
+	}
+
+	for _, v := range conditions {
+		query += " AND " + v
+	}
+	query += " ORDER BY t.Time DESC, t.Chain DESC, t.Version DESC, t.Index DESC, " + transfer + " DESC LIMIT ?"
+	args = append(args, q.Limit)
+	return "(" + query + ")", args
+}
+
+func (r *LocalTransactionRepo) FetchHistory(ctx context.Context, q TransactionQuery) ([]TransactionEntry, error) {
+	subqueries := make([]string, 0, 2)
+	args := make([]interface{}, 0)
+	if q.Chain == "" || q.Chain == blockchain.DiemChain {
+		query, queryArgs := historySubquery(diemHistoryQuery, "0", blockchain.DiemChain, q)
+		subqueries = append(subqueries, query)
+		args = append(args, queryArgs...)
+	}
+	if q.Chain == "" || q.Chain == blockchain.CeloChain {
+		query, queryArgs := historySubquery(celoHistoryQuery, "tr.LogIndex", blockchain.CeloChain, q)
+		subqueries = append(subqueries, query)
+		args = append(args, queryArgs...)
+	}
+
+	query := "SELECT * FROM (" + strings.Join(subqueries, " UNION ALL ") + ") AS h " +
+		"ORDER BY h.Time DESC, h.Chain DESC, h.Version DESC, h.Index DESC, h.Transfer DESC LIMIT ?;"
+	args = append(args, q.Limit)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]TransactionEntry, 0)
+	for rows.Next() {
+		var v TransactionEntry
+		var amount, fee, gatewayFee sql.NullString
+		var refund sqltype.MyBool
+
+		err = rows.Scan(
+			&v.Chain, &v.Version, &v.Index, &v.TransferId.Transfer,
+			&v.Time, &v.Status, &v.Hash,
+			&fee, &v.FeeCurrency, &gatewayFee,
+			&v.Currency, &amount, &v.From, &v.To,
+			&v.TransactionSenderRemark.Message, &refund,
+			&v.TransactionAccountRemark.Message,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Amount = sqltype.ToBigInt(amount)
+		v.Fee = sqltype.ToBigInt(fee)
+		v.GatewayFee = sqltype.ToBigInt(gatewayFee)
+		v.IsRefund = bool(refund)
+		v.AccountId = q.AccountId
+		entries = append(entries, v)
+	}
+	return entries, rows.Err()
+}
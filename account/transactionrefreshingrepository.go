@@ -12,6 +12,40 @@ type RefreshingTransactionRepo struct {
 	*LocalTransactionRepo
 	diemBC 		wallet.DiemTxQuery
 	celoBC 		wallet.CeloTxQuery
+	// Optional
+	Notifier	TransactionNotifier
+}
+
+func NewRefreshingTransactionRepo(local *LocalTransactionRepo, diemBC wallet.DiemTxQuery, celoBC wallet.CeloTxQuery) *RefreshingTransactionRepo {
+	return &RefreshingTransactionRepo{
+		LocalTransactionRepo: local,
+		diemBC: diemBC,
+		celoBC: celoBC,
+	}
+}
+
+func (r *RefreshingTransactionRepo) notifyDiem(ctx context.Context, received, updated []DiemTransaction) error {
+	if r.Notifier == nil {
+		return nil
+	}
+
+	err := r.Notifier.NotifyDiem(ctx, WebhookTransactionReceived, received...)
+	if err != nil {
+		return err
+	}
+	return r.Notifier.NotifyDiem(ctx, WebhookTransactionUpdated, updated...)
+}
+
+func (r *RefreshingTransactionRepo) notifyCelo(ctx context.Context, received, updated []CeloTransaction) error {
+	if r.Notifier == nil {
+		return nil
+	}
+
+	err := r.Notifier.NotifyCelo(ctx, WebhookTransactionReceived, received...)
+	if err != nil {
+		return err
+	}
+	return r.Notifier.NotifyCelo(ctx, WebhookTransactionUpdated, updated...)
 }
 
 func (r *RefreshingTransactionRepo) FetchDiemByWallet(ctx context.Context, start uint64, addresses ...string) (<-chan DiemTxWithError, <-chan error) {
@@ -98,6 +132,12 @@ func (r *RefreshingTransactionRepo) FetchDiemByWallet(ctx context.Context, start
 			errChan <- err
 			return
 		}
+
+		err = r.notifyDiem(ctx, storeList, updateList)
+		if err != nil {
+			errChan <- err
+			return
+		}
 	}()
 	return txChan, errChan
 }
@@ -186,6 +226,12 @@ func (r *RefreshingTransactionRepo) FetchDiemByAccount(ctx context.Context, acco
 			errChan <- err
 			return
 		}
+
+		err = r.notifyDiem(ctx, storeList, updateList)
+		if err != nil {
+			errChan <- err
+			return
+		}
 	}()
 	return txChan, addresses, errChan
 }
@@ -243,6 +289,8 @@ func (r *RefreshingTransactionRepo) FetchCeloByWallet(ctx context.Context, start
 
 		updateList := make([]CeloTransaction, 0)
 		storeList := make([]CeloTransaction, 0)
+		// Transactions that are not stored yet, the store list overlaps with the local ones
+		receivedList := make([]CeloTransaction, 0)
 		for k0, v0 := range txsRemote {
 			for k1, v1 := range v0 {
 				if _, ok := txsLocal[k0][k1]; ok {
@@ -273,6 +321,7 @@ func (r *RefreshingTransactionRepo) FetchCeloByWallet(ctx context.Context, start
 						v1, TransactionAccountRemark{}, wallet.TransactionSenderRemark{},
 					}
 					storeList = append(storeList, tTx)
+					receivedList = append(receivedList, tTx)
 					txsLocal[k0][k1] = tTx
 				}
 			}
@@ -292,6 +341,12 @@ func (r *RefreshingTransactionRepo) FetchCeloByWallet(ctx context.Context, start
 			errChan <- err
 			return
 		}
+
+		err = r.notifyCelo(ctx, receivedList, updateList)
+		if err != nil {
+			errChan <- err
+			return
+		}
 	}()
 	return txChan, errChan
 }
@@ -349,6 +404,8 @@ func (r *RefreshingTransactionRepo) FetchCeloByAccount(ctx context.Context, acco
 
 		updateList := make([]CeloTransaction, 0)
 		storeList := make([]CeloTransaction, 0)
+		// Transactions that are not stored yet, the store list overlaps with the local ones
+		receivedList := make([]CeloTransaction, 0)
 		for k0, v0 := range txsRemote {
 			for k1, v1 := range v0 {
 				if _, ok := txsLocal[k0][k1]; ok {
@@ -379,6 +436,7 @@ func (r *RefreshingTransactionRepo) FetchCeloByAccount(ctx context.Context, acco
 						v1, TransactionAccountRemark{}, wallet.TransactionSenderRemark{},
 					}
 					storeList = append(storeList, tTx)
+					receivedList = append(receivedList, tTx)
 					txsLocal[k0][k1] = tTx
 				}
 			}
@@ -398,6 +456,12 @@ func (r *RefreshingTransactionRepo) FetchCeloByAccount(ctx context.Context, acco
 			errChan <- err
 			return
 		}
+
+		err = r.notifyCelo(ctx, receivedList, updateList)
+		if err != nil {
+			errChan <- err
+			return
+		}
 	}()
 	return txChan, addresses, errChan
 }
\ No newline at end of file
@@ -3,7 +3,6 @@ package account
 import (
 	"context"
 	"database/sql"
-	"fmt"
 	"strings"
 	"time"
 
@@ -28,6 +27,14 @@ type LocalTransactionRepo struct {
 	DB *sql.DB
 }
 
+func NewLocalTransactionRepo(database *sql.DB) *LocalTransactionRepo {
+	return &LocalTransactionRepo{
+		baseCeloTransactionRepo: &baseCeloTransactionRepo{DB: database},
+		baseDiemTransactionRepo: &baseDiemTransactionRepo{DB: database},
+		DB: database,
+	}
+}
+
 func (r *TransactionAccountRepo) StoreAccount(ctx context.Context, txs ...TransactionAccount) error {
 	query := "INSERT INTO transaction_context VALUES "
 	vars := []interface{}{}
@@ -377,6 +384,10 @@ func (r *baseDiemTransactionRepo) UpdateDiem(ctx context.Context, txs ...DiemTra
 }
 
 func (r *LocalTransactionRepo) FetchDiemByWallet(ctx context.Context, start uint64, addresses ...string) (map[uint64]DiemTransaction, error) {
+	if len(addresses) == 0 {
+		return make(map[uint64]DiemTransaction), nil
+	}
+
 	chain := blockchain.DiemChain
 	query := "SELECT " + 
 			 "t.Version, t.Index, " +
@@ -391,9 +402,9 @@ func (r *LocalTransactionRepo) FetchDiemByWallet(ctx context.Context, start uint
 			 "ON d.Version = t.Version AND d.Chain = t.Chain AND d.Index = t.Index " +
 			 "LEFT JOIN transaction_sender AS s " +
 			 "ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
-			 "WHERE t.Chain = " + chain + " AND t.Version >= ? " +
+			 "WHERE t.Chain = ? AND t.Version >= ? " +
 			 "AND ("
-	qVars := []interface{}{start,}
+	qVars := []interface{}{chain, start,}
 
 	for _, v := range addresses {
 		query +="d.From = ? OR d.To = ? OR "
@@ -479,7 +490,7 @@ func (r *LocalTransactionRepo) fetchAddressByAccount(ctx context.Context, chain
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx, accountId)
+	rows, err := stmt.QueryContext(ctx, chain, accountId)
 	if err != nil {
 		return nil, err
 	}
@@ -523,9 +534,9 @@ func (r *LocalTransactionRepo) FetchDiemByAccount(ctx context.Context, accountId
 			 "ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
 			 "LEFT JOIN transaction_context AS c " +
 			 "ON c.Version = t.Version AND c.Chain = t.Chain AND c.Index = t.Index AND c.AccountId = ? " +
-			 fmt.Sprintf("WHERE t.Chain = %s ", chain) + "AND t.Version >= ? " +
-			 fmt.Sprintf("AND (d.From IN (SELECT Address FROM wallet WHERE chain = %s AND AccountId = ?) ", chain) +
-			 fmt.Sprintf("OR d.To IN (SELECT Address FROM wallet WHERE chain = %s AND AccountId = ?));", chain)
+			 "WHERE t.Chain = ? AND t.Version >= ? " +
+			 "AND (d.From IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?) " +
+			 "OR d.To IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?));"
 
 	stmt, err := r.DB.PrepareContext(ctx, query)
 	if err != nil {
@@ -533,7 +544,7 @@ func (r *LocalTransactionRepo) FetchDiemByAccount(ctx context.Context, accountId
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx, accountId, start, accountId, accountId)
+	rows, err := stmt.QueryContext(ctx, accountId, chain, start, chain, accountId, chain, accountId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -607,7 +618,7 @@ func storeCeloIgnoreDuplicate(ctx context.Context, sqlTx *sql.Tx, txs ...CeloTra
 				Version: v.Version,
 				Chain:   v.Chain,
 			},
-			Index: 	diemIndex,
+			Index: 	v.Index,
 			Gas: 	v.Gas,
 			Status: v.Status,
 			Hash:   v.Hash,
@@ -632,7 +643,7 @@ func storeCeloIgnoreDuplicate(ctx context.Context, sqlTx *sql.Tx, txs ...CeloTra
 
 		for k, v0 := range v.TransferEvents {
 			trfQuery += "(?, ?, ?, ?, ?, ?, ?, ?),"
-			trfVars = append(txVars, v.Version, v.Chain, v.Index, k, v0.Currency, v0.Amount, v0.From, v0.To)
+			trfVars = append(trfVars, v.Version, v.Chain, v.Index, k, v0.Currency, v0.Amount, v0.From, v0.To)
 		}
 	}
 
@@ -671,7 +682,7 @@ func storeCeloIgnoreDuplicate(ctx context.Context, sqlTx *sql.Tx, txs ...CeloTra
 	for _, v := range txs {
 		accTxs = append(accTxs, TransactionAccount{
 			v.TransactionBlock,
-			diemIndex,
+			v.Index,
 			v.TransactionAccountRemark,
 		})
 	}
@@ -684,7 +695,7 @@ func storeCeloIgnoreDuplicate(ctx context.Context, sqlTx *sql.Tx, txs ...CeloTra
 	senderTxs := make([]wallet.TransactionSender, 0)
 	for _, v := range txs {
 		senderTxs = append(senderTxs, wallet.TransactionSender{
-			Index: diemIndex,
+			Index: v.Index,
 			TransactionBlock: v.TransactionBlock,
 			TransactionSenderRemark: v.TransactionSenderRemark,
 		})
@@ -730,7 +741,7 @@ func (r *baseCeloTransactionRepo) UpdateCelo(ctx context.Context, txs ...CeloTra
 		blocks = append(blocks, wallet.TransactionId{
 			Version: v.Version,
 			Chain: v.Chain,
-			Index: diemIndex,
+			Index: v.Index,
 		})
 	}
 
@@ -748,6 +759,10 @@ func (r *baseCeloTransactionRepo) UpdateCelo(ctx context.Context, txs ...CeloTra
 }
 
 func (r *LocalTransactionRepo) FetchCeloByWallet(ctx context.Context, start uint64, addresses ...string) (map[uint64]map[int]CeloTransaction, error) {
+	if len(addresses) == 0 {
+		return make(map[uint64]map[int]CeloTransaction), nil
+	}
+
 	chain := blockchain.CeloChain
 	query := "SELECT " + 
 			 "t.Version, t.Index, " +
@@ -757,13 +772,13 @@ func (r *LocalTransactionRepo) FetchCeloByWallet(ctx context.Context, start uint
 			 "ct.LogIndex, ct.Currency, ct.Amount, " +
 			 "ct.From, ct.To, " +
 			 "COALESCE(s.Message, ''), COALESCE(s.Refund, b'0') " +
-			 "FROM transaction AS t" +
+			 "FROM transaction AS t " +
 			 "INNER JOIN transaction_celo AS c ON t.Version = c.Version AND t.Chain = c.Chain AND t.Index = c.Index " +
 			 "INNER JOIN transaction_celo_transfer AS ct ON t.Version = ct.Version AND t.Chain = ct.Chain AND t.Index = ct.Index " +
-			 "LEFT JOIN transaction_sender AS s ON ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
-			 "WHERE t.Chain = " + chain + " AND t.Version >= ? " +
+			 "LEFT JOIN transaction_sender AS s ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
+			 "WHERE t.Chain = ? AND t.Version >= ? " +
 			 "AND ("
-	qVars := []interface{}{start,}
+	qVars := []interface{}{chain, start,}
 
 	for _, v := range addresses {
 		query +="ct.From = ? OR ct.To = ? OR "
@@ -807,7 +822,14 @@ func (r *LocalTransactionRepo) FetchCeloByWallet(ctx context.Context, start uint
 			return nil, err
 		}
 
+		if _, ok := txMap[version]; !ok {
+			txMap[version] = make(map[int]CeloTransaction)
+		}
+
 		tEvents := txMap[version][index].TransferEvents
+		if tEvents == nil {
+			tEvents = make(map[int]wallet.Transfer)
+		}
 		tEvents[logIndex] = wallet.Transfer{
 			Currency: currency,
 			From: from,
@@ -861,17 +883,14 @@ func (r *LocalTransactionRepo) FetchCeloByAccount(ctx context.Context, accountId
 			 "ct.From, ct.To, " +
 			 "COALESCE(s.Message, ''), COALESCE(s.Refund, b'0'), " +
 			 "COALESCE(con.Message, '') " +
-			 "FROM transaction AS t" +
+			 "FROM transaction AS t " +
 			 "INNER JOIN transaction_celo AS c ON t.Version = c.Version AND t.Chain = c.Chain AND t.Index = c.Index " +
 			 "INNER JOIN transaction_celo_transfer AS ct ON t.Version = ct.Version AND t.Chain = ct.Chain AND t.Index = ct.Index " +
-			 "LEFT JOIN transaction_sender AS s ON ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
+			 "LEFT JOIN transaction_sender AS s ON s.Version = t.Version AND s.Chain = t.Chain AND s.Index = t.Index " +
 			 "LEFT JOIN transaction_context AS con ON t.Version = con.Version AND t.Chain = con.Chain AND t.Index = con.Index AND con.AccountId = ? " +
-			 fmt.Sprintf("WHERE t.Chain = %s ", chain) + "AND t.Version >= ? " +
-			 fmt.Sprintf("AND (d.From IN (SELECT Address FROM wallet WHERE chain = %s AND AccountId = ?) ", chain) +
-			 fmt.Sprintf("OR d.To IN (SELECT Address FROM wallet WHERE chain = %s AND AccountId = ?));", chain)
-
-	query = strings.TrimSuffix(query, " OR ")
-	query += ");"
+			 "WHERE t.Chain = ? AND t.Version >= ? " +
+			 "AND (ct.From IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?) " +
+			 "OR ct.To IN (SELECT Address FROM wallet WHERE Chain = ? AND AccountId = ?));"
 
 	stmt, err := r.DB.PrepareContext(ctx, query)
 	if err != nil {
@@ -879,7 +898,7 @@ func (r *LocalTransactionRepo) FetchCeloByAccount(ctx context.Context, accountId
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx, accountId, start, accountId, accountId)
+	rows, err := stmt.QueryContext(ctx, accountId, chain, start, chain, accountId, chain, accountId)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -908,7 +927,14 @@ func (r *LocalTransactionRepo) FetchCeloByAccount(ctx context.Context, accountId
 			return nil, nil, err
 		}
 
+		if _, ok := txMap[version]; !ok {
+			txMap[version] = make(map[int]CeloTransaction)
+		}
+
 		tEvents := txMap[version][index].TransferEvents
+		if tEvents == nil {
+			tEvents = make(map[int]wallet.Transfer)
+		}
 		tEvents[logIndex] = wallet.Transfer{
 			Currency: currency,
 			From: from,
@@ -947,3 +973,37 @@ func (r *LocalTransactionRepo) FetchCeloByAccount(ctx context.Context, accountId
 	}
 	return txMap, addresses, rows.Err()
 }
+
+
+// Hashes of the transactions that are already stored, missing ids are not stored
+func (r *LocalTransactionRepo) FetchHashes(ctx context.Context, ids ...wallet.TransactionId) (map[wallet.TransactionId]string, error) {
+	hashes := make(map[wallet.TransactionId]string)
+	if len(ids) == 0 {
+		return hashes, nil
+	}
+
+	query := "SELECT Version, Chain, `Index`, Hash FROM transaction WHERE (Version, Chain, `Index`) IN ("
+	args := make([]interface{}, 0, len(ids)*3)
+	for _, v := range ids {
+		query += "(?, ?, ?),"
+		args = append(args, v.Version, v.Chain, v.Index)
+	}
+	query = strings.TrimSuffix(query, ",") + ");"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id wallet.TransactionId
+		var hash string
+		err = rows.Scan(&id.Version, &id.Chain, &id.Index, &hash)
+		if err != nil {
+			return nil, err
+		}
+		hashes[id] = hash
+	}
+	return hashes, rows.Err()
+}
@@ -0,0 +1,154 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/encryption"
+	"github.com/stevealexrs/Go-Libra/totp"
+)
+
+// Time-based one-time password of an account, secret is encrypted
+type TOTP struct {
+	Secret   []byte
+	Enabled  bool
+	LastStep uint64
+}
+
+type TOTPRepository interface {
+	// Secret is nil if the account has not enrolled
+	FetchTOTP(ctx context.Context, id int) (*TOTP, error)
+	// Store secret and enabled, the last step is only changed by UseTOTPStep
+	StoreTOTP(ctx context.Context, id int, t TOTP) error
+	DeleteTOTP(ctx context.Context, id int) error
+	// Returns false if the step is not newer than the last used step
+	UseTOTPStep(ctx context.Context, id int, step uint64) (bool, error)
+}
+
+// Users and businesses share the account table, so the same instance serves both
+type TwoFactor struct {
+	TOTPRepo TOTPRepository
+	Cipher   encryption.Cipher
+	Issuer   string
+	// Failed codes of an account are counted across pending logins
+	Throttle LoginThrottle
+}
+
+func (f *TwoFactor) Enabled(ctx context.Context, id int) (bool, error) {
+	t, err := f.TOTPRepo.FetchTOTP(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return t.Enabled, nil
+}
+
+// Returns provisioning uri and secret, enrollment is completed after ConfirmEnrollment
+func (f *TwoFactor) Enroll(ctx context.Context, id int, accountName string) (string, string, error) {
+	t, err := f.TOTPRepo.FetchTOTP(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+	if t.Enabled {
+		return "", "", ErrTOTPEnabled(ctx)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	encrypted, err := f.Cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return "", "", err
+	}
+
+	err = f.TOTPRepo.StoreTOTP(ctx, id, TOTP{Secret: encrypted})
+	if err != nil {
+		return "", "", err
+	}
+	return totp.ProvisioningURI(f.Issuer, accountName, secret), secret, nil
+}
+
+func (f *TwoFactor) check(ctx context.Context, id int, t *TOTP, code string) error {
+	if t.Secret == nil {
+		return ErrTOTPNotEnrolled(ctx)
+	}
+
+	secret, err := f.Cipher.Decrypt(t.Secret)
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := totp.Validate(string(secret), code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok || step <= t.LastStep {
+		return ErrTOTPCode(ctx)
+	}
+
+	used, err := f.TOTPRepo.UseTOTPStep(ctx, id, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrTOTPCode(ctx)
+	}
+	return nil
+}
+
+func (f *TwoFactor) ConfirmEnrollment(ctx context.Context, id int, code string) error {
+	t, err := f.TOTPRepo.FetchTOTP(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Enabled {
+		return ErrTOTPEnabled(ctx)
+	}
+
+	err = f.check(ctx, id, t, code)
+	if err != nil {
+		return err
+	}
+
+	t.Enabled = true
+	return f.TOTPRepo.StoreTOTP(ctx, id, *t)
+}
+
+// Second stage of login
+func (f *TwoFactor) Verify(ctx context.Context, id int, code string) error {
+	t, err := f.TOTPRepo.FetchTOTP(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !t.Enabled {
+		return ErrTOTPNotEnrolled(ctx)
+	}
+
+	err = f.Throttle.CheckTOTP(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = f.check(ctx, id, t, code)
+	var printable *PrintableError
+	if errors.As(err, &printable) {
+		failErr := f.Throttle.FailTOTP(ctx, id)
+		if failErr != nil {
+			return failErr
+		}
+		return err
+	} else if err != nil {
+		return err
+	}
+	return f.Throttle.ResetTOTP(ctx, id)
+}
+
+func (f *TwoFactor) Disable(ctx context.Context, id int, code string) error {
+	err := f.Verify(ctx, id, code)
+	if err != nil {
+		return err
+	}
+	return f.TOTPRepo.DeleteTOTP(ctx, id)
+}
@@ -0,0 +1,51 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/stevealexrs/Go-Libra/database/sqltype"
+)
+
+type TOTPRepo struct {
+	DB *sql.DB
+}
+
+func (r *TOTPRepo) FetchTOTP(ctx context.Context, id int) (*TOTP, error) {
+	var t TOTP
+	var enabled sqltype.MyBool
+
+	err := r.DB.QueryRowContext(ctx, "SELECT TotpSecret, TotpEnabled, TotpLastStep FROM account WHERE Id = ? LIMIT 1;", id).Scan(&t.Secret, &enabled, &t.LastStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	} else if err != nil {
+		return nil, err
+	}
+
+	t.Enabled = bool(enabled)
+	return &t, nil
+}
+
+func (r *TOTPRepo) StoreTOTP(ctx context.Context, id int, t TOTP) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE account SET TotpSecret = ?, TotpEnabled = ? WHERE Id = ?;", t.Secret, sqltype.MyBool(t.Enabled), id)
+	return err
+}
+
+func (r *TOTPRepo) DeleteTOTP(ctx context.Context, id int) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE account SET TotpSecret = NULL, TotpEnabled = b'0' WHERE Id = ?;", id)
+	return err
+}
+
+func (r *TOTPRepo) UseTOTPStep(ctx context.Context, id int, step uint64) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, "UPDATE account SET TotpLastStep = ? WHERE Id = ? AND TotpLastStep < ?;", step, id, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -12,7 +12,18 @@ type User struct {
 	DisplayName     string
 }
 
-func NewUserAccountWithPassword(invitationEmail, username, displayName, password, email string) (*User, error) {
+// The password must satisfy the policy
+func NewUserAccountWithPassword(ctx context.Context, policy PasswordPolicy, invitationEmail, username, displayName, password, email string) (*User, error) {
+	// the invitation email is known even when the personal email is not given
+	candidate := PasswordCandidate{Password: password, Username: username, Email: invitationEmail}
+	if candidate.Email == "" {
+		candidate.Email = email
+	}
+	err := policy.Check(ctx, candidate)
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := random.GenerateHash(password)
 	if err != nil {
 		return nil, err
@@ -65,4 +76,5 @@ type InvitationEmailVerificationRepository interface {
 	Store(ctx context.Context, invitation InvitationEmail) error
 	Fetch(ctx context.Context, email string) (string, error)
 	Exist(ctx context.Context, email string) (bool, error)
+	Delete(ctx context.Context, email string) error
 }
\ No newline at end of file
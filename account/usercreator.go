@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type UserRegistrationForm struct {
@@ -19,6 +20,12 @@ type UserCreator struct {
 	UserRepo 	   UserAccountRepository
 	InvitationRepo InvitationEmailVerificationRepository
 	EmailRepo 	   RecoveryEmailVerificationRepository
+	Deletion	   AccountDeletion
+	EmailChange	   EmailChange
+	Throttle	   LoginThrottle
+	Policy		   PasswordPolicy
+	UsernamePolicy UsernamePolicy
+	Invitations	   Invitations
 	Ext	  	  	   ExternalComm
 }
 
@@ -30,31 +37,118 @@ func (c *UserCreator) InvitationEmailExist(ctx context.Context, email string) (b
 	return c.UserRepo.HasInvitationEmail(ctx, email)
 }
 
-func (c *UserCreator) CreateInvitation(ctx context.Context, email string) error {
-	exist, err := c.InvitationRepo.Exist(ctx, email)
+// The invitation counts toward the quota of the inviter unless it is revoked
+func (c *UserCreator) CreateInvitation(ctx context.Context, inviterId int, email string) (*Invitation, error) {
+	exist, err := c.InvitationEmailExist(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, ErrInvitationEmailTaken(ctx)
+	}
+
+	_, err = c.Invitations.InvitationRepo.FetchPendingByEmail(ctx, email)
+	if err == nil {
+		return nil, ErrInvitationPending(ctx)
+	} else if !errors.Is(err, errDoesNotExist) {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation := &Invitation{
+		InviterId: inviterId,
+		Email:     email,
+		CreatedAt: now,
+		SentAt:    now,
+	}
+	stored, err := c.Invitations.InvitationRepo.StoreWithinQuota(ctx, invitation, c.Invitations.DefaultQuota)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, ErrInvitationQuota(ctx)
+	}
+
+	code, err := NewInvitationEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.InvitationRepo.Store(ctx, *code)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitation, c.Ext.VerifyInvitationEmail(ctx, code.Email, code.Code)
+}
+
+func (c *UserCreator) ListInvitations(ctx context.Context, inviterId int) ([]Invitation, error) {
+	return c.Invitations.InvitationRepo.FetchByInviter(ctx, inviterId)
+}
+
+// The code is sent again, a new one is generated if it has expired
+func (c *UserCreator) ResendInvitation(ctx context.Context, inviterId, invitationId int) error {
+	invitation, err := c.Invitations.fetchOwned(ctx, inviterId, invitationId)
+	if err != nil {
+		return err
+	}
+	if !invitation.Pending() {
+		return ErrInvitationNotPending(ctx)
+	}
+
+	wait := time.Until(invitation.SentAt.Add(c.Invitations.ResendCooldown))
+	if wait > 0 {
+		return ErrInvitationCooldown(ctx, wait)
+	}
+
+	exist, err := c.InvitationRepo.Exist(ctx, invitation.Email)
 	if err != nil {
 		return err
 	}
 
+	var code string
 	if exist {
-		code, err := c.InvitationRepo.Fetch(ctx, email)
+		code, err = c.InvitationRepo.Fetch(ctx, invitation.Email)
 		if err != nil {
 			return err
 		}
-		return c.Ext.VerifyInvitationEmail(ctx, email, code)
+	} else {
+		newCode, err := NewInvitationEmail(invitation.Email)
+		if err != nil {
+			return err
+		}
+
+		err = c.InvitationRepo.Store(ctx, *newCode)
+		if err != nil {
+			return err
+		}
+		code = newCode.Code
 	}
 
-	invitation, err := NewInvitationEmail(email)
+	invitation.SentAt = time.Now()
+	err = c.Invitations.InvitationRepo.Update(ctx, invitation)
 	if err != nil {
 		return err
 	}
-	
-	err = c.InvitationRepo.Store(ctx, *invitation)
+	return c.Ext.VerifyInvitationEmail(ctx, invitation.Email, code)
+}
+
+// The code stops working and the quota is given back
+func (c *UserCreator) RevokeInvitation(ctx context.Context, inviterId, invitationId int) error {
+	invitation, err := c.Invitations.fetchOwned(ctx, inviterId, invitationId)
 	if err != nil {
 		return err
 	}
-	
-	return c.Ext.VerifyInvitationEmail(ctx, invitation.Email, invitation.Code)
+	if !invitation.Pending() {
+		return ErrInvitationNotPending(ctx)
+	}
+
+	invitation.Revoked = true
+	err = c.Invitations.InvitationRepo.Update(ctx, invitation)
+	if err != nil {
+		return err
+	}
+	return c.InvitationRepo.Delete(ctx, invitation.Email)
 }
 
 func (c *UserCreator) CreateAccountWithInvitation(ctx context.Context, form UserRegistrationForm) (int, error) {
@@ -74,15 +168,44 @@ func (c *UserCreator) CreateAccountWithInvitation(ctx context.Context, form User
 		return 0, ErrInvitationEmailTaken(ctx)
 	}
 
+	throttleKey := invitationThrottleKey(form.Invitation.Email)
+	err = c.Throttle.Check(ctx, throttleKey)
+	if err != nil {
+		return 0, err
+	}
+
 	code, err := c.InvitationRepo.Fetch(ctx, form.Invitation.Email)
 	if err != nil {
 		return 0, err
 	}
 	if form.Invitation.Code != code {
+		_, err = c.Throttle.Fail(ctx, throttleKey)
+		if err != nil {
+			return 0, err
+		}
 		return 0, ErrInvitationVerificationCode(ctx)
 	}
 
+	err = c.Throttle.Reset(ctx, throttleKey)
+	if err != nil {
+		return 0, err
+	}
+
+	invitation, err := c.Invitations.InvitationRepo.FetchPendingByEmail(ctx, form.Invitation.Email)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrInvitationVerificationCode(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.UsernamePolicy.Check(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+
 	acc, err := NewUserAccountWithPassword(
+		ctx,
+		c.Policy,
 		form.Invitation.Email,
 		form.Username,
 		form.DisplayName,
@@ -97,7 +220,18 @@ func (c *UserCreator) CreateAccountWithInvitation(ctx context.Context, form User
 		return 0, err
 	}
 
-	return newId, c.RequestEmailVerification(ctx, *acc.Id)
+	invitation.InviteeId = &newId
+	err = c.Invitations.InvitationRepo.Update(ctx, invitation)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.InvitationRepo.Delete(ctx, form.Invitation.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	return newId, c.RequestEmailVerification(ctx, newId)
 }
 
 func (c *UserCreator) CreateAccount(ctx context.Context, form UserRegistrationForm) (int, error) {
@@ -109,7 +243,14 @@ func (c *UserCreator) CreateAccount(ctx context.Context, form UserRegistrationFo
 		return 0, ErrUsernameTaken(ctx)
 	}
 
+	err = c.UsernamePolicy.Check(ctx, form.Username)
+	if err != nil {
+		return 0, err
+	}
+
 	acc, err := NewUserAccountWithPassword(
+		ctx,
+		c.Policy,
 		form.Invitation.Email,
 		form.Username,
 		form.DisplayName,
@@ -178,34 +319,247 @@ func (c *UserCreator) VerifyEmail(ctx context.Context, userId int, email, token
 		return err
 	}
 
+	previous := acc.Email
 	err = acc.VerifyEmail(email)
 	if err != nil {
 		return err
 	}
 
-	return c.UserRepo.Update(ctx, acc)
+	err = c.UserRepo.Update(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	err = c.EmailRepo.Delete(ctx, userId, email)
+	if err != nil {
+		return err
+	}
+
+	return c.EmailChange.NotifyChanged(ctx, &acc.Base, previous)
+}
+
+// The account owner is notified, other sessions are left to the caller
+func (c *UserCreator) ChangePassword(ctx context.Context, id int, current, password string) error {
+	acc, err := c.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &acc.Base, current)
+	if err != nil {
+		return err
+	}
+
+	err = acc.UpdatePassword(ctx, c.Policy, password)
+	if err != nil {
+		return err
+	}
+
+	err = c.UserRepo.Update(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	if acc.Email == "" {
+		return nil
+	}
+	return c.Ext.NotifyPasswordChanged(ctx, acc.Email, acc.Username)
+}
+
+// The new email replaces the current one after it is verified
+func (c *UserCreator) RequestEmailChange(ctx context.Context, id int, password, email string) error {
+	acc, err := c.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &acc.Base, password)
+	if err != nil {
+		return err
+	}
+
+	err = c.EmailChange.Request(ctx, &acc.Base, email)
+	if err != nil {
+		return err
+	}
+
+	err = c.UserRepo.Update(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	return c.RequestEmailVerification(ctx, id)
+}
+
+func (c *UserCreator) ResendEmailVerification(ctx context.Context, id int) error {
+	acc, err := c.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if acc.UnverifiedEmail == "" {
+		return ErrNoPendingEmail(ctx)
+	}
+	return c.RequestEmailVerification(ctx, id)
+}
+
+// Undo an email change with the token sent to the previous email,
+// the sessions of the account must be destroyed by the caller
+func (c *UserCreator) RevertEmail(ctx context.Context, serializedToken string) (int, error) {
+	id, token, err := c.EmailChange.ParseRevertToken(ctx, serializedToken)
+	if err != nil {
+		return 0, err
+	}
+
+	acc, err := c.UserRepo.FetchById(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrEmailRevertToken(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.EmailChange.Revert(ctx, &acc.Base, token)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, c.UserRepo.Update(ctx, acc)
 }
 
 func (c *UserCreator) Login(ctx context.Context, username, password string) (int, error) {
 	user, err := c.UserRepo.FetchByUsername(ctx, username)
+	if errors.Is(err, errDoesNotExist) {
+		return 0, ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.checkPassword(ctx, &user.Base, password)
 	if err != nil {
 		return 0, err
 	}
 
-	success, err := user.ComparePassword(password)
+	if user.Deleted {
+		return 0, ErrAccountDeleted(ctx)
+	}
+
+	err = c.rehash(ctx, user, password)
 	if err != nil {
 		return 0, err
 	}
+	return *user.Id, nil
+}
+
+// Upgrade the hash of the password that is just checked
+func (c *UserCreator) rehash(ctx context.Context, user *User, password string) error {
+	if !user.NeedsRehash() {
+		return nil
+	}
+
+	err := user.setPassword(password)
+	if err != nil {
+		return err
+	}
+	return c.UserRepo.Update(ctx, user)
+}
+
+// Failed attempts are counted for the lockout
+func (c *UserCreator) checkPassword(ctx context.Context, acc *Base, password string) error {
+	err := c.Throttle.CheckAccount(ctx, *acc.Id)
+	if err != nil {
+		return err
+	}
+
+	success, err := acc.ComparePassword(password)
+	if err != nil || !success {
+		err = c.Throttle.FailAccount(ctx, acc)
+		if err != nil {
+			return err
+		}
+		return ErrInvalidCredentials(ctx)
+	}
+	return c.Throttle.ResetAccount(ctx, *acc.Id)
+}
+
+// Unlock the account with the link sent after it is locked, returns the account id
+func (c *UserCreator) Unlock(ctx context.Context, token string) (int, error) {
+	return c.Throttle.Unlock(ctx, token)
+}
+
+// Check the id belongs to a user, used after passwordless login
+func (c *UserCreator) CanLogin(ctx context.Context, id int) error {
+	user, err := c.UserRepo.FetchById(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return err
+	}
 
-	if !success {
+	if user.Deleted {
+		return ErrAccountDeleted(ctx)
+	}
+	return nil
+}
+
+// The sessions of the user must be destroyed by the caller
+func (c *UserCreator) DeleteAccount(ctx context.Context, id int, password string) error {
+	user, err := c.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = c.checkPassword(ctx, &user.Base, password)
+	if err != nil {
+		return err
+	}
+	return c.Deletion.Delete(ctx, &user.Base)
+}
+
+// Restore the account deleted within the grace period, the caller must complete
+// the login like after Login
+func (c *UserCreator) RestoreAccount(ctx context.Context, username, password string) (int, error) {
+	user, err := c.UserRepo.FetchByUsername(ctx, username)
+	if errors.Is(err, errDoesNotExist) {
 		return 0, ErrInvalidCredentials(ctx)
+	} else if err != nil {
+		return 0, err
+	}
+
+	err = c.checkPassword(ctx, &user.Base, password)
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.Deletion.Restore(ctx, &user.Base)
+	if err != nil {
+		return 0, err
 	}
 	return *user.Id, nil
 }
 
+// Nil fields are left unchanged
+type UserProfileForm struct {
+	DisplayName *string
+}
+
+func (c *UserCreator) UpdateProfile(ctx context.Context, id int, form UserProfileForm) (*User, error) {
+	acc, err := c.UserRepo.FetchById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if form.DisplayName == nil || *form.DisplayName == acc.DisplayName {
+		return acc, nil
+	}
+	acc.DisplayName = *form.DisplayName
+
+	return acc, c.UserRepo.Update(ctx, acc)
+}
+
 type UserAccountRecoveryHelper struct {
 	UserRepo 	 UserAccountRepository
 	RecoveryRepo RecoveryRepository
+	Policy		 PasswordPolicy
 	Ext 		 ExternalComm
 }
 
@@ -284,30 +638,30 @@ func (helper *UserAccountRecoveryHelper) RequestPasswordReset(ctx context.Contex
 	return helper.Ext.ResetPassword(ctx, email, acc.Username, helper.serializeToken(*acc.Id, recovery.Token))
 }
 
-func (helper *UserAccountRecoveryHelper) ResetPassword(ctx context.Context, serializedToken, password string) error {
+func (helper *UserAccountRecoveryHelper) ResetPassword(ctx context.Context, serializedToken, password string) (int, error) {
 	userId, token, err := helper.unserializeToken(ctx, serializedToken)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	
 	storedToken, err := helper.RecoveryRepo.Fetch(ctx, userId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if storedToken != token {
-		return ErrPasswordResetToken(ctx)
+		return 0, ErrPasswordResetToken(ctx)
 	}
 
 	acc, err := helper.UserRepo.FetchById(ctx, userId)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	err = acc.UpdatePassword(password)
+	err = acc.UpdatePassword(ctx, helper.Policy, password)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return helper.UserRepo.Update(ctx, acc)
+	return userId, helper.UserRepo.Update(ctx, acc)
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/mtibben/confusables"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/secure/precis"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Usernames that look the same have the same canonical form.
+// The username is prepared by the UsernameCaseMapped profile of RFC 8265, mapped to its UTS #39 confusable
+// skeleton so names written with look-alike letters of another script collide, and stripped of accents.
+// Names the profile rejects, which only exist from before the policy, are NFKC normalised and case folded instead.
+// The canonical form can be longer than the username
+func CanonicalUsername(name string) string {
+	mapped, err := precis.UsernameCaseMapped.String(name)
+	if err != nil {
+		mapped = cases.Fold().String(norm.NFKC.String(name))
+	}
+
+	var b strings.Builder
+	for _, v := range confusables.Skeleton(mapped) {
+		if unicode.Is(unicode.Mn, v) {
+			continue
+		}
+		b.WriteRune(v)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// Usernames are letters of a single allowed script with digits, '_', '-' and '.'
+type UsernamePolicy struct {
+	// Any script is allowed if empty
+	Scripts   []*unicode.RangeTable
+	MinLength int
+	MaxLength int
+}
+
+func DefaultUsernamePolicy() UsernamePolicy {
+	return UsernamePolicy{
+		Scripts:   []*unicode.RangeTable{unicode.Latin, unicode.Han, unicode.Cyrillic, unicode.Greek},
+		MinLength: 3,
+		MaxLength: 32,
+	}
+}
+
+func (p UsernamePolicy) script(r rune) *unicode.RangeTable {
+	if len(p.Scripts) == 0 {
+		for _, v := range unicode.Scripts {
+			if unicode.Is(v, r) {
+				return v
+			}
+		}
+		return nil
+	}
+
+	for _, v := range p.Scripts {
+		if unicode.Is(v, r) {
+			return v
+		}
+	}
+	return nil
+}
+
+func (p UsernamePolicy) Check(ctx context.Context, name string) error {
+	name = norm.NFKC.String(name)
+
+	length := len([]rune(name))
+	if length < p.MinLength || (p.MaxLength > 0 && length > p.MaxLength) {
+		return ErrUsernameLength(ctx, p.MinLength, p.MaxLength)
+	}
+
+	var script *unicode.RangeTable
+	for _, v := range name {
+		switch {
+		case v >= '0' && v <= '9', v == '_', v == '-', v == '.':
+			continue
+		case unicode.Is(unicode.Mn, v):
+			continue
+		case !unicode.IsLetter(v):
+			return ErrUsernameCharacter(ctx)
+		}
+
+		s := p.script(v)
+		if s == nil {
+			return ErrUsernameScript(ctx)
+		}
+		if script != nil && s != script {
+			return ErrUsernameMixedScript(ctx)
+		}
+		script = s
+	}
+	return nil
+}
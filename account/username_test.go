@@ -0,0 +1,53 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/account"
+)
+
+func TestCanonicalUsername(t *testing.T) {
+	same := []string{"alice", "Alice", "ALICE", "ａｌｉｃｅ", "alicé"}
+	for _, v := range same {
+		if res := account.CanonicalUsername(v); res != "alice" {
+			t.Errorf("expect %q to be canonicalised to alice, got %q", v, res)
+		}
+	}
+
+	if account.CanonicalUsername("alice2") == account.CanonicalUsername("alice") {
+		t.Error("expect different usernames to stay different")
+	}
+
+	// whole-script Cyrillic look-alikes pass the single script rule
+	for latin, cyrillic := range map[string]string{"pay": "рау", "example": "ехаmрlе"} {
+		if account.CanonicalUsername(latin) != account.CanonicalUsername(cyrillic) {
+			t.Errorf("expect %q to have the canonical form of %q", cyrillic, latin)
+		}
+	}
+}
+
+func TestUsernamePolicy(t *testing.T) {
+	policy := account.DefaultUsernamePolicy()
+
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"alice", true},
+		{"jane_doe-1.0", true},
+		{"алиса", true},
+		{"ab", false},
+		{"alice bob", false},
+		{"аlice", false},
+		{"alice!", false},
+		{"مرحبا", false},
+	}
+
+	for _, v := range tests {
+		err := policy.Check(context.Background(), v.name)
+		if (err == nil) != v.valid {
+			t.Errorf("username %q: expect valid %v, got %v", v.name, v.valid, err)
+		}
+	}
+}
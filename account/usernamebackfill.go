@@ -0,0 +1,75 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// Fill the canonical usernames of the accounts before they are made unique,
+// it runs again whenever the canonical form changes.
+// The oldest account keeps its username, a later account whose username looks the same
+// is renamed by appending its id and logged so its owner can be told
+func BackfillCanonicalUsername(ctx context.Context, db *sql.DB) error {
+	type accountName struct {
+		id        int
+		username  string
+		canonical string
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT Id, Username FROM account ORDER BY Id;")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	names := make([]accountName, 0)
+	// number of accounts with the canonical username
+	count := make(map[string]int)
+	for rows.Next() {
+		var v accountName
+		err = rows.Scan(&v.id, &v.username)
+		if err != nil {
+			return err
+		}
+		v.canonical = CanonicalUsername(v.username)
+		names = append(names, v)
+		count[v.canonical]++
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	rows.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	owner := make(map[string]int)
+	for _, v := range names {
+		if first, ok := owner[v.canonical]; ok {
+			renamed := v.username + "_" + strconv.Itoa(v.id)
+			if count[CanonicalUsername(renamed)] > 0 {
+				tx.Rollback()
+				return fmt.Errorf("username %s of account %d cannot be renamed, %s is taken", v.username, v.id, renamed)
+			}
+
+			log.Printf("username %s of account %d looks the same as account %d, renamed to %s", v.username, v.id, first, renamed)
+			v.username = renamed
+			v.canonical = CanonicalUsername(renamed)
+			count[v.canonical]++
+		}
+		owner[v.canonical] = v.id
+
+		_, err = tx.ExecContext(ctx, "UPDATE account SET Username = ?, UsernameCanonical = ? WHERE Id = ?;", v.username, v.canonical, v.id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
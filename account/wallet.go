@@ -0,0 +1,156 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stevealexrs/Go-Libra/random"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// Message signed by the wallet to prove the ownership of an address
+type WalletChallenge struct {
+	AccountId int
+	Chain     string
+	Address   string
+	Message   string
+}
+
+func NewWalletChallenge(accountId int, chain, address string) (*WalletChallenge, error) {
+	nonce, err := random.Token16Byte()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := &WalletChallenge{
+		AccountId: accountId,
+		Chain:     chain,
+		Address:   address,
+		Message: fmt.Sprintf(
+			"Sign this message to link your wallet.\nAccount: %d\nChain: %s\nAddress: %s\nNonce: %s",
+			accountId, chain, address, nonce,
+		),
+	}
+	return challenge, nil
+}
+
+type WalletRepository interface {
+	// returns errAlreadyExist if the address is already linked
+	Store(ctx context.Context, accountId int, w wallet.Wallet) error
+	FetchByAccount(ctx context.Context, accountId int) ([]wallet.Wallet, error)
+	// returns the id of the account that owns the address
+	FetchOwner(ctx context.Context, chain, address string) (int, error)
+	Delete(ctx context.Context, accountId int, chain, address string) error
+}
+
+type WalletChallengeRepository interface {
+	Store(ctx context.Context, challenge *WalletChallenge) error
+	Fetch(ctx context.Context, accountId int, chain, address string) (string, error)
+	Delete(ctx context.Context, accountId int, chain, address string) error
+}
+
+// Link wallets to account after the ownership is proven
+type WalletLinker struct {
+	WalletRepo    WalletRepository
+	ChallengeRepo WalletChallengeRepository
+	// Keyed by chain
+	Verifiers map[string]wallet.OwnershipVerifier
+}
+
+func (l *WalletLinker) normalize(ctx context.Context, chain, address string) (wallet.OwnershipVerifier, string, error) {
+	verifier, ok := l.Verifiers[chain]
+	if !ok {
+		return nil, "", ErrUnsupportedChain(ctx)
+	}
+
+	normalized, err := verifier.NormalizeAddress(address)
+	if err != nil {
+		return nil, "", ErrInvalidWalletAddress(ctx)
+	}
+	return verifier, normalized, nil
+}
+
+// returns the message to be signed by the wallet
+func (l *WalletLinker) RequestChallenge(ctx context.Context, accountId int, chain, address string) (string, error) {
+	_, normalized, err := l.normalize(ctx, chain, address)
+	if err != nil {
+		return "", err
+	}
+
+	challenge, err := NewWalletChallenge(accountId, chain, normalized)
+	if err != nil {
+		return "", err
+	}
+
+	err = l.ChallengeRepo.Store(ctx, challenge)
+	if err != nil {
+		return "", err
+	}
+	return challenge.Message, nil
+}
+
+func (l *WalletLinker) Link(ctx context.Context, accountId int, chain, address, publicKey string, signature []byte) error {
+	verifier, normalized, err := l.normalize(ctx, chain, address)
+	if err != nil {
+		return err
+	}
+
+	message, err := l.ChallengeRepo.Fetch(ctx, accountId, chain, normalized)
+	if err != nil {
+		return ErrWalletChallenge(ctx)
+	}
+
+	err = verifier.VerifyOwnership(normalized, publicKey, []byte(message), signature)
+	if err != nil {
+		return ErrWalletSignature(ctx)
+	}
+
+	// the challenge can only be used once
+	err = l.ChallengeRepo.Delete(ctx, accountId, chain, normalized)
+	if err != nil {
+		return err
+	}
+
+	owner, err := l.WalletRepo.FetchOwner(ctx, chain, normalized)
+	if err == nil {
+		if owner == accountId {
+			return nil
+		}
+		return ErrWalletTaken(ctx)
+	} else if !errors.Is(err, errDoesNotExist) {
+		return err
+	}
+
+	err = l.WalletRepo.Store(ctx, accountId, wallet.Wallet{
+		Address: wallet.Address{
+			Chain: chain,
+			Hex:   normalized,
+		},
+		PublicKey: publicKey,
+	})
+	// linked at the same time by another request
+	if errors.Is(err, errAlreadyExist) {
+		owner, err = l.WalletRepo.FetchOwner(ctx, chain, normalized)
+		if err != nil {
+			return err
+		}
+		if owner == accountId {
+			return nil
+		}
+		return ErrWalletTaken(ctx)
+	}
+	return err
+}
+
+func (l *WalletLinker) List(ctx context.Context, accountId int) ([]wallet.Wallet, error) {
+	return l.WalletRepo.FetchByAccount(ctx, accountId)
+}
+
+func (l *WalletLinker) Unlink(ctx context.Context, accountId int, chain, address string) error {
+	_, normalized, err := l.normalize(ctx, chain, address)
+	if err != nil {
+		return err
+	}
+	return l.WalletRepo.Delete(ctx, accountId, chain, normalized)
+}
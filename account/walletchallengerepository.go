@@ -0,0 +1,38 @@
+package account
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/database/kv"
+)
+
+type WalletChallengeRepo kvRepo
+
+func NewWalletChallengeRepo(store kv.ExpiringStore, namespace string) *WalletChallengeRepo {
+	return &WalletChallengeRepo{store: store, namespace: namespace}
+}
+
+func (r *WalletChallengeRepo) makeKey(accountId int, chain, address string) string {
+	return r.namespace + ":" + strconv.Itoa(accountId) + ":" + chain + ":" + address
+}
+
+// Keep the challenge for 10 mins
+func (r *WalletChallengeRepo) Store(ctx context.Context, challenge *WalletChallenge) error {
+	return r.store.SetWithExpiration(
+		ctx,
+		r.makeKey(challenge.AccountId, challenge.Chain, challenge.Address),
+		challenge.Message,
+		10*time.Minute,
+	)
+}
+
+func (r *WalletChallengeRepo) Fetch(ctx context.Context, accountId int, chain, address string) (string, error) {
+	return r.store.Get(ctx, r.makeKey(accountId, chain, address))
+}
+
+func (r *WalletChallengeRepo) Delete(ctx context.Context, accountId int, chain, address string) error {
+	_, err := r.store.Delete(ctx, r.makeKey(accountId, chain, address))
+	return err
+}
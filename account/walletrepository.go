@@ -0,0 +1,59 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// Error number of MySQL when a unique key is violated
+const mysqlDuplicateEntry = 1062
+
+type WalletRepo struct {
+	DB *sql.DB
+}
+
+func (r *WalletRepo) Store(ctx context.Context, accountId int, w wallet.Wallet) error {
+	_, err := r.DB.ExecContext(ctx, "INSERT INTO wallet VALUES(?, ?, ?, ?);", w.Chain, w.Hex, accountId, w.PublicKey)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return errAlreadyExist
+	}
+	return err
+}
+
+func (r *WalletRepo) FetchByAccount(ctx context.Context, accountId int) ([]wallet.Wallet, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT Chain, Address, PublicKey FROM wallet WHERE AccountId = ?;", accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wallets := make([]wallet.Wallet, 0)
+	for rows.Next() {
+		var w wallet.Wallet
+		err = rows.Scan(&w.Chain, &w.Hex, &w.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+	return wallets, rows.Err()
+}
+
+func (r *WalletRepo) FetchOwner(ctx context.Context, chain, address string) (int, error) {
+	var id int
+	err := r.DB.QueryRowContext(ctx, "SELECT AccountId FROM wallet WHERE Chain = ? AND Address = ? LIMIT 1;", chain, address).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, errDoesNotExist
+	}
+	return id, err
+}
+
+func (r *WalletRepo) Delete(ctx context.Context, accountId int, chain, address string) error {
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM wallet WHERE AccountId = ? AND Chain = ? AND Address = ?;", accountId, chain, address)
+	return err
+}
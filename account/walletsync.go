@@ -0,0 +1,278 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+// Position of the indexer in the history of a wallet
+type WalletSync struct {
+	wallet.Address
+	// Only used by Diem wallets
+	Diem     wallet.DiemEventCursor
+	// Last indexed block, only used by Celo wallets
+	Block    uint64
+	// Zero if the wallet was never indexed
+	SyncedAt time.Time
+}
+
+// How far behind the indexer is on a chain
+type SyncLag struct {
+	Chain      string
+	Wallets    int
+	// Wallets that were never indexed
+	Pending    int
+	// Zero if no wallet was indexed
+	OldestSync time.Time
+	// Time since the least recently indexed wallet
+	Lag        time.Duration
+}
+
+type WalletSyncRepository interface {
+	// Registered wallets that were not indexed since before, the least recently indexed first
+	FetchDue(ctx context.Context, before time.Time, limit int) ([]WalletSync, error)
+	Store(ctx context.Context, sync WalletSync) error
+	FetchLag(ctx context.Context) ([]SyncLag, error)
+}
+
+type IndexedTransactionRepository interface {
+	baseDiemTransactionRepository
+	baseCeloTransactionRepository
+	FetchHashes(ctx context.Context, ids ...wallet.TransactionId) (map[wallet.TransactionId]string, error)
+}
+
+// Keeps the local transactions of the registered wallets up to date so reads never wait for the chains
+type Indexer struct {
+	SyncRepo     WalletSyncRepository
+	TxRepo       IndexedTransactionRepository
+	DiemQuery    wallet.DiemEventQuery
+	CeloQuery    wallet.CeloBlockQuery
+	// Optional, told about the transfers a wallet receives and about changed transactions. The cursor of the
+	// wallet only moves after they are told, so a failed notification is told again on the next run
+	Notifier     TransactionNotifier
+	// Time between two polls of a wallet
+	PollInterval time.Duration
+	// Wallets polled per run
+	BatchSize    int
+	// Diem events of each direction or Celo token transfers read per wallet and run, the rest is read on the
+	// next runs
+	PageSize     int
+}
+
+// Poll the wallets that are due, a failed wallet keeps its cursor and is polled again on the next run.
+// Returns the number of wallets that are indexed
+func (x *Indexer) Sync(ctx context.Context) (int, error) {
+	due, err := x.SyncRepo.FetchDue(ctx, time.Now().Add(-x.PollInterval), x.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, v := range due {
+		switch v.Chain {
+		case blockchain.DiemChain:
+			err = x.syncDiem(ctx, &v)
+		case blockchain.CeloChain:
+			err = x.syncCelo(ctx, &v)
+		default:
+			continue
+		}
+		if errors.Is(err, context.Canceled) {
+			return synced, err
+		}
+		if err != nil {
+			log.Printf("indexing %s wallet %s failed: %s", v.Chain, v.Hex, err)
+			continue
+		}
+
+		v.SyncedAt = time.Now()
+		err = x.SyncRepo.Store(ctx, v)
+		if err != nil {
+			return synced, err
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+func (x *Indexer) syncDiem(ctx context.Context, s *WalletSync) error {
+	txs, next, err := x.DiemQuery.TransactionsByEvent(ctx, s.Hex, s.Diem, x.PageSize)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]wallet.TransactionId, 0, len(txs))
+	for _, v := range txs {
+		ids = append(ids, wallet.TransactionId{Version: v.Version, Chain: v.Chain, Index: diemIndex})
+	}
+	hashes, err := x.TxRepo.FetchHashes(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	// the other wallet of a transfer between registered wallets might have stored it already,
+	// the receiving wallet is the one that tells about it
+	storeList := make([]DiemTransaction, 0)
+	receivedList := make([]DiemTransaction, 0)
+	updateList := make([]DiemTransaction, 0)
+	for _, v := range txs {
+		tx := DiemTransaction{DiemTransaction: v}
+		hash, ok := hashes[wallet.TransactionId{Version: v.Version, Chain: v.Chain, Index: diemIndex}]
+		if !ok {
+			storeList = append(storeList, tx)
+		} else if hash != v.Hash {
+			updateList = append(updateList, tx)
+		}
+		if strings.EqualFold(v.To, s.Hex) {
+			receivedList = append(receivedList, tx)
+		}
+	}
+
+	err = x.TxRepo.StoreDiem(ctx, storeList...)
+	if err != nil {
+		return err
+	}
+
+	// changed transactions are told before they are updated, a failed update finds and tells them again
+	if x.Notifier != nil {
+		err = x.Notifier.NotifyDiem(ctx, WebhookTransactionReceived, receivedList...)
+		if err != nil {
+			return err
+		}
+		err = x.Notifier.NotifyDiem(ctx, WebhookTransactionUpdated, updateList...)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = x.TxRepo.UpdateDiem(ctx, updateList...)
+	if err != nil {
+		return err
+	}
+
+	s.Diem = next
+	return nil
+}
+
+func (x *Indexer) syncCelo(ctx context.Context, s *WalletSync) error {
+	// the latest block is read first so blocks mined during the query are polled again
+	latest, err := x.CeloQuery.LatestBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if latest <= s.Block {
+		return nil
+	}
+
+	txs, block, err := x.CeloQuery.TransactionsByBlock(ctx, s.Hex, s.Block+1, latest, x.PageSize)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]wallet.TransactionId, 0)
+	for _, v0 := range txs {
+		for _, v1 := range v0 {
+			ids = append(ids, wallet.TransactionId{Version: v1.Version, Chain: v1.Chain, Index: v1.Index})
+		}
+	}
+	hashes, err := x.TxRepo.FetchHashes(ctx, ids...)
+	if err != nil {
+		return err
+	}
+
+	// transactions that are already stored are stored again since the other wallet only stored its own
+	// transfers, duplicate transfers are ignored
+	storeList := make([]CeloTransaction, 0)
+	receivedList := make([]CeloTransaction, 0)
+	updateList := make([]CeloTransaction, 0)
+	for _, v0 := range txs {
+		for _, v1 := range v0 {
+			tx := CeloTransaction{CeloTransaction: v1}
+			hash, ok := hashes[wallet.TransactionId{Version: v1.Version, Chain: v1.Chain, Index: v1.Index}]
+			if ok && hash != v1.Hash {
+				updateList = append(updateList, tx)
+			} else {
+				storeList = append(storeList, tx)
+			}
+			if received, ok := celoReceived(v1, s.Hex); ok {
+				receivedList = append(receivedList, received)
+			}
+		}
+	}
+
+	err = x.TxRepo.StoreCelo(ctx, storeList...)
+	if err != nil {
+		return err
+	}
+
+	// changed transactions are told before they are updated, a failed update finds and tells them again
+	if x.Notifier != nil {
+		err = x.Notifier.NotifyCelo(ctx, WebhookTransactionReceived, receivedList...)
+		if err != nil {
+			return err
+		}
+		err = x.Notifier.NotifyCelo(ctx, WebhookTransactionUpdated, updateList...)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = x.TxRepo.UpdateCelo(ctx, updateList...)
+	if err != nil {
+		return err
+	}
+
+	s.Block = block
+	return nil
+}
+
+// Copy of the transaction with only its transfers to the address
+func celoReceived(tx wallet.CeloTransaction, address string) (CeloTransaction, bool) {
+	transfers := make(map[int]wallet.Transfer)
+	for k, v := range tx.TransferEvents {
+		if strings.EqualFold(v.To, address) {
+			transfers[k] = v
+		}
+	}
+	tx.TransferEvents = transfers
+	return CeloTransaction{CeloTransaction: tx}, len(transfers) > 0
+}
+
+func (x *Indexer) Lag(ctx context.Context) ([]SyncLag, error) {
+	lags, err := x.SyncRepo.FetchLag(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i, v := range lags {
+		if !v.OldestSync.IsZero() {
+			lags[i].Lag = now.Sub(v.OldestSync)
+		}
+	}
+	return lags, nil
+}
+
+func (x *Indexer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := x.Sync(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("wallet indexing failed: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
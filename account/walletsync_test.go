@@ -0,0 +1,262 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type memorySyncRepo struct {
+	syncs []account.WalletSync
+}
+
+func (r *memorySyncRepo) FetchDue(ctx context.Context, before time.Time, limit int) ([]account.WalletSync, error) {
+	return append([]account.WalletSync{}, r.syncs...), nil
+}
+
+func (r *memorySyncRepo) Store(ctx context.Context, sync account.WalletSync) error {
+	for i, v := range r.syncs {
+		if v.Address == sync.Address {
+			r.syncs[i] = sync
+		}
+	}
+	return nil
+}
+
+func (r *memorySyncRepo) FetchLag(ctx context.Context) ([]account.SyncLag, error) {
+	return nil, nil
+}
+
+type memoryIndexedRepo struct {
+	hashes map[wallet.TransactionId]string
+}
+
+func (r *memoryIndexedRepo) StoreDiem(ctx context.Context, txs ...account.DiemTransaction) error {
+	for _, v := range txs {
+		r.hashes[wallet.TransactionId{Version: v.Version, Chain: v.Chain}] = v.Hash
+	}
+	return nil
+}
+
+func (r *memoryIndexedRepo) UpdateDiem(ctx context.Context, txs ...account.DiemTransaction) error {
+	return r.StoreDiem(ctx, txs...)
+}
+
+func (r *memoryIndexedRepo) StoreCelo(ctx context.Context, txs ...account.CeloTransaction) error {
+	for _, v := range txs {
+		r.hashes[wallet.TransactionId{Version: v.Version, Chain: v.Chain, Index: v.Index}] = v.Hash
+	}
+	return nil
+}
+
+func (r *memoryIndexedRepo) UpdateCelo(ctx context.Context, txs ...account.CeloTransaction) error {
+	return r.StoreCelo(ctx, txs...)
+}
+
+func (r *memoryIndexedRepo) FetchHashes(ctx context.Context, ids ...wallet.TransactionId) (map[wallet.TransactionId]string, error) {
+	hashes := make(map[wallet.TransactionId]string)
+	for _, v := range ids {
+		if hash, ok := r.hashes[v]; ok {
+			hashes[v] = hash
+		}
+	}
+	return hashes, nil
+}
+
+// Transfers numbered by the versions of their transactions, the events of a wallet are its sent and received transfers
+type transferDiemQuery struct {
+	transfers []wallet.Transfer
+}
+
+func (q *transferDiemQuery) TransactionsByEvent(ctx context.Context, address string, from wallet.DiemEventCursor, limit int) (map[uint64]wallet.DiemTransaction, wallet.DiemEventCursor, error) {
+	txs := make(map[uint64]wallet.DiemTransaction)
+	next := from
+	var sent, received uint64
+	for i, v := range q.transfers {
+		tx := wallet.DiemTransaction{
+			TransactionBlock: wallet.TransactionBlock{Version: uint64(i), Chain: blockchain.DiemChain},
+			Hash:             "diem",
+			Transfer:         v,
+		}
+		if v.From == address {
+			if sent >= from.Sent && next.Sent < from.Sent+uint64(limit) {
+				txs[tx.Version] = tx
+				next.Sent++
+			}
+			sent++
+		}
+		if v.To == address {
+			if received >= from.Received && next.Received < from.Received+uint64(limit) {
+				txs[tx.Version] = tx
+				next.Received++
+			}
+			received++
+		}
+	}
+	return txs, next, nil
+}
+
+func diemTransfer(from, to string) wallet.Transfer {
+	return wallet.Transfer{Currency: "XUS", From: from, To: to, Amount: big.NewInt(1)}
+}
+
+// Every block has a transfer to the wallet
+type blockCeloQuery struct {
+	latest uint64
+	blocks []uint64
+	starts []uint64
+}
+
+func (q *blockCeloQuery) LatestBlock(ctx context.Context) (uint64, error) {
+	return q.latest, nil
+}
+
+func (q *blockCeloQuery) TransactionsByBlock(ctx context.Context, address string, start, end uint64, limit int) (map[uint64]map[int]wallet.CeloTransaction, uint64, error) {
+	q.starts = append(q.starts, start)
+	txs := make(map[uint64]map[int]wallet.CeloTransaction)
+	for _, v := range q.blocks {
+		if v < start || v > end {
+			continue
+		}
+		if len(txs) == limit {
+			return txs, v - 1, nil
+		}
+		txs[v] = map[int]wallet.CeloTransaction{0: {
+			TransactionBlock: wallet.TransactionBlock{Version: v, Chain: blockchain.CeloChain},
+			Hash:             "celo",
+			TransferEvents:   map[int]wallet.Transfer{0: {From: "0xdef", To: address, Amount: big.NewInt(1)}},
+		}}
+	}
+	return txs, end, nil
+}
+
+type countingNotifier map[string]int
+
+func (n countingNotifier) NotifyDiem(ctx context.Context, event string, txs ...account.DiemTransaction) error {
+	n[blockchain.DiemChain+" "+event] += len(txs)
+	return nil
+}
+
+func (n countingNotifier) NotifyCelo(ctx context.Context, event string, txs ...account.CeloTransaction) error {
+	n[blockchain.CeloChain+" "+event] += len(txs)
+	return nil
+}
+
+// Fails the first Diem notification
+type failingNotifier struct {
+	countingNotifier
+	failed bool
+}
+
+func (n *failingNotifier) NotifyDiem(ctx context.Context, event string, txs ...account.DiemTransaction) error {
+	if !n.failed {
+		n.failed = true
+		return errors.New("notifier is down")
+	}
+	return n.countingNotifier.NotifyDiem(ctx, event, txs...)
+}
+
+func TestIndexerSync(t *testing.T) {
+	ctx := context.Background()
+	syncRepo := &memorySyncRepo{syncs: []account.WalletSync{
+		{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "a"}},
+		{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "b"}},
+		{Address: wallet.Address{Chain: blockchain.CeloChain, Hex: "0xb"}},
+	}}
+	// the transfer from a to b is stored by a and told by b
+	diemQuery := &transferDiemQuery{transfers: []wallet.Transfer{
+		diemTransfer("a", "x"),
+		diemTransfer("x", "a"),
+		diemTransfer("a", "b"),
+		diemTransfer("x", "b"),
+	}}
+	celoQuery := &blockCeloQuery{latest: 20, blocks: []uint64{7, 12}}
+	notifier := countingNotifier{}
+	indexer := account.Indexer{
+		SyncRepo:  syncRepo,
+		TxRepo:    &memoryIndexedRepo{hashes: make(map[wallet.TransactionId]string)},
+		DiemQuery: diemQuery,
+		CeloQuery: celoQuery,
+		Notifier:  notifier,
+		BatchSize: 10,
+		PageSize:  10,
+	}
+
+	synced, err := indexer.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced != 3 {
+		t.Errorf("expected 3 indexed wallets, got %d", synced)
+	}
+	if notifier["Diem transaction.received"] != 3 || notifier["Celo transaction.received"] != 2 {
+		t.Errorf("unexpected notifications %v", notifier)
+	}
+	if syncRepo.syncs[0].Diem != (wallet.DiemEventCursor{Sent: 2, Received: 1}) || syncRepo.syncs[1].Diem != (wallet.DiemEventCursor{Received: 2}) || syncRepo.syncs[2].Block != 20 {
+		t.Errorf("unexpected cursors %+v", syncRepo.syncs)
+	}
+	if syncRepo.syncs[0].SyncedAt.IsZero() {
+		t.Error("sync time is not stored")
+	}
+
+	// only events and blocks after the cursors are polled
+	diemQuery.transfers = append(diemQuery.transfers, diemTransfer("x", "a"))
+	celoQuery.latest = 25
+	celoQuery.blocks = append(celoQuery.blocks, 23)
+	_, err = indexer.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notifier["Diem transaction.received"] != 4 || notifier["Celo transaction.received"] != 3 {
+		t.Errorf("unexpected notifications %v", notifier)
+	}
+	if celoQuery.starts[1] != 21 || syncRepo.syncs[2].Block != 25 {
+		t.Errorf("celo is polled from %d up to %d", celoQuery.starts[1], syncRepo.syncs[2].Block)
+	}
+}
+
+func TestIndexerSyncPages(t *testing.T) {
+	ctx := context.Background()
+	syncRepo := &memorySyncRepo{syncs: []account.WalletSync{
+		{Address: wallet.Address{Chain: blockchain.DiemChain, Hex: "a"}},
+		{Address: wallet.Address{Chain: blockchain.CeloChain, Hex: "0xb"}},
+	}}
+	notifier := &failingNotifier{countingNotifier: countingNotifier{}}
+	indexer := account.Indexer{
+		SyncRepo:  syncRepo,
+		TxRepo:    &memoryIndexedRepo{hashes: make(map[wallet.TransactionId]string)},
+		DiemQuery: &transferDiemQuery{transfers: []wallet.Transfer{diemTransfer("x", "a"), diemTransfer("y", "a")}},
+		CeloQuery: &blockCeloQuery{latest: 20, blocks: []uint64{7, 12}},
+		Notifier:  notifier,
+		BatchSize: 10,
+		PageSize:  1,
+	}
+
+	// the stored transfer is told again after the failed notification
+	_, err := indexer.Sync(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syncRepo.syncs[0].Diem != (wallet.DiemEventCursor{}) || syncRepo.syncs[1].Block != 11 {
+		t.Errorf("unexpected cursors %+v", syncRepo.syncs)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = indexer.Sync(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if notifier.countingNotifier["Diem transaction.received"] != 2 || notifier.countingNotifier["Celo transaction.received"] != 2 {
+		t.Errorf("unexpected notifications %v", notifier.countingNotifier)
+	}
+	if syncRepo.syncs[0].Diem != (wallet.DiemEventCursor{Received: 2}) || syncRepo.syncs[1].Block != 20 {
+		t.Errorf("unexpected cursors %+v", syncRepo.syncs)
+	}
+}
@@ -0,0 +1,80 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type WalletSyncRepo struct {
+	DB *sql.DB
+}
+
+// Wallets without a sync row were never indexed and come first
+func (r *WalletSyncRepo) FetchDue(ctx context.Context, before time.Time, limit int) ([]WalletSync, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT w.Chain, w.Address, COALESCE(s.SentSequence, 0), COALESCE(s.ReceivedSequence, 0), COALESCE(s.Block, 0), s.SyncedAt "+
+			"FROM wallet AS w LEFT JOIN wallet_sync AS s ON s.Chain = w.Chain AND s.Address = w.Address "+
+			"WHERE s.SyncedAt IS NULL OR s.SyncedAt < ? ORDER BY s.SyncedAt LIMIT ?;",
+		before, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	syncs := make([]WalletSync, 0)
+	for rows.Next() {
+		var v WalletSync
+		var syncedAt sql.NullTime
+		err = rows.Scan(&v.Chain, &v.Hex, &v.Diem.Sent, &v.Diem.Received, &v.Block, &syncedAt)
+		if err != nil {
+			return nil, err
+		}
+		if syncedAt.Valid {
+			v.SyncedAt = syncedAt.Time
+		}
+		syncs = append(syncs, v)
+	}
+	return syncs, rows.Err()
+}
+
+func (r *WalletSyncRepo) Store(ctx context.Context, sync WalletSync) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		"INSERT INTO wallet_sync VALUES(?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE SentSequence = VALUES(SentSequence), ReceivedSequence = VALUES(ReceivedSequence), "+
+			"Block = VALUES(Block), SyncedAt = VALUES(SyncedAt);",
+		sync.Chain, sync.Hex, sync.Diem.Sent, sync.Diem.Received, sync.Block, sync.SyncedAt,
+	)
+	return err
+}
+
+func (r *WalletSyncRepo) FetchLag(ctx context.Context) ([]SyncLag, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT w.Chain, COUNT(*), COALESCE(SUM(s.SyncedAt IS NULL), 0), MIN(s.SyncedAt) "+
+			"FROM wallet AS w LEFT JOIN wallet_sync AS s ON s.Chain = w.Chain AND s.Address = w.Address "+
+			"GROUP BY w.Chain ORDER BY w.Chain;",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lags := make([]SyncLag, 0)
+	for rows.Next() {
+		var v SyncLag
+		var oldest sql.NullTime
+		err = rows.Scan(&v.Chain, &v.Wallets, &v.Pending, &oldest)
+		if err != nil {
+			return nil, err
+		}
+		if oldest.Valid {
+			v.OldestSync = oldest.Time
+		}
+		lags = append(lags, v)
+	}
+	return lags, rows.Err()
+}
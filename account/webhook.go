@@ -0,0 +1,518 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/random"
+)
+
+// Events sent to webhook endpoints
+const (
+	WebhookTransactionReceived = "transaction.received"
+	WebhookTransactionUpdated  = "transaction.updated"
+)
+
+var webhookEvents = []string{WebhookTransactionReceived, WebhookTransactionUpdated}
+
+// Status of webhook deliveries
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Headers of webhook requests, the signature is
+// t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" keyed by the endpoint secret>
+const (
+	WebhookSignatureHeader = "Libra-Signature"
+	WebhookEventHeader     = "Libra-Event"
+	WebhookDeliveryHeader  = "Libra-Delivery"
+)
+
+const (
+	// Response body that is read so the connection can be reused, the body is not kept
+	maxWebhookResponse = 1024
+	// Deliveries in one page of the history
+	maxWebhookPage     = 100
+)
+
+type WebhookEndpoint struct {
+	Id         int
+	BusinessId int
+	URL        string
+	// Key of the signature, shown to the business when the endpoint is created
+	Secret     string
+	Events     []string
+	CreatedAt  time.Time
+}
+
+func (e *WebhookEndpoint) Subscribed(event string) bool {
+	for _, v := range e.Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+// An event sent to an endpoint, redelivery creates a new delivery with the same payload
+type WebhookDelivery struct {
+	Id            int64
+	EndpointId    int
+	BusinessId    int
+	Event         string
+	// Id of the published event, empty for redeliveries
+	EventId       string
+	Payload       []byte
+	Status        string
+	Attempts      int
+	// Status code of the last response, 0 if no response is received
+	ResponseCode  int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+}
+
+// Body of webhook requests, id is the same for redeliveries so receivers can deduplicate
+type WebhookPayload struct {
+	Id      string      `json:"id"`
+	Type    string      `json:"type"`
+	Created int64       `json:"created"`
+	Data    interface{} `json:"data"`
+}
+
+type WebhookTransfer struct {
+	Chain    string    `json:"chain"`
+	Version  uint64    `json:"version"`
+	Index    int       `json:"index"`
+	// Position of the transfer event in Celo transactions
+	Transfer int       `json:"transfer"`
+	Hash     string    `json:"hash"`
+	Status   string    `json:"status"`
+	Time     time.Time `json:"time"`
+	Currency string    `json:"currency"`
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Amount   string    `json:"amount"`
+}
+
+type WebhookRepository interface {
+	// Id of the endpoint is set after it is stored
+	StoreEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error
+	FetchEndpoints(ctx context.Context, businessId int) ([]WebhookEndpoint, error)
+	FetchEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id int) error
+	// Id of the delivery is set after it is stored
+	StoreDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	FetchDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+	// Latest first, before is the id of the last delivery of the previous page
+	FetchDeliveries(ctx context.Context, endpointId int, before int64, limit int) ([]WebhookDelivery, error)
+	// Pending deliveries that should be attempted at the time
+	FetchDue(ctx context.Context, at time.Time, limit int) ([]WebhookDelivery, error)
+}
+
+type webhookWalletRepository interface {
+	FetchOwner(ctx context.Context, chain, address string) (int, error)
+}
+
+// Addresses that are not public in addition to the ones the net package knows,
+// the shared address space of carrier-grade NAT and the "this network" block
+var webhookBlockedNets = []*net.IPNet{
+	{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)},
+	{IP: net.IPv4(0, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, v := range webhookBlockedNets {
+		if v.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Refuse connections to addresses that are not public, such as loopback, private networks and
+// the cloud metadata service. The check runs on the resolved address of every connection,
+// so redirects and hostnames that resolve to internal addresses are refused too
+func webhookDialControl(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !publicIP(ip) {
+		return fmt.Errorf("webhook address %s is not public", host)
+	}
+	return nil
+}
+
+// Client for webhook deliveries that only connects to public addresses, without a proxy
+// since the proxy would connect on behalf of the client
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: webhookDialControl,
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		Timeout: timeout,
+	}
+}
+
+// Signed events to the endpoints of businesses, failed deliveries are retried with exponential backoff
+type Webhooks struct {
+	WebhookRepo  WebhookRepository
+	WalletRepo   webhookWalletRepository
+	// Use NewWebhookClient, the endpoints are given by businesses
+	Client       *http.Client
+	MaxEndpoints int
+	MaxAttempts  int
+	// Delay after the first failure, doubled after each failure
+	BaseDelay    time.Duration
+	// Number of deliveries sent in one run
+	BatchSize    int
+}
+
+func checkWebhookEvents(ctx context.Context, events []string) error {
+	if len(events) == 0 {
+		return ErrWebhookEvent(ctx)
+	}
+
+	for _, v := range events {
+		valid := false
+		for _, e := range webhookEvents {
+			if v == e {
+				valid = true
+			}
+		}
+		if !valid {
+			return ErrWebhookEvent(ctx)
+		}
+	}
+	return nil
+}
+
+func (w *Webhooks) CreateEndpoint(ctx context.Context, businessId int, endpointURL string, events []string) (*WebhookEndpoint, error) {
+	u, err := url.Parse(endpointURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, ErrWebhookURL(ctx)
+	}
+
+	err = checkWebhookEvents(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := w.WebhookRepo.FetchEndpoints(ctx, businessId)
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) >= w.MaxEndpoints {
+		return nil, ErrWebhookLimit(ctx, w.MaxEndpoints)
+	}
+
+	secret, err := random.Token20Byte()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &WebhookEndpoint{
+		BusinessId: businessId,
+		URL:        u.String(),
+		Secret:     secret,
+		Events:     events,
+		CreatedAt:  time.Now(),
+	}
+	return endpoint, w.WebhookRepo.StoreEndpoint(ctx, endpoint)
+}
+
+func (w *Webhooks) Endpoints(ctx context.Context, businessId int) ([]WebhookEndpoint, error) {
+	return w.WebhookRepo.FetchEndpoints(ctx, businessId)
+}
+
+// Only the business that owns the endpoint can manage it
+func (w *Webhooks) fetchOwned(ctx context.Context, businessId, id int) (*WebhookEndpoint, error) {
+	endpoint, err := w.WebhookRepo.FetchEndpoint(ctx, id)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrWebhookNotExist(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+
+	if endpoint.BusinessId != businessId {
+		return nil, ErrWebhookNotExist(ctx)
+	}
+	return endpoint, nil
+}
+
+// The delivery history of the endpoint is deleted too
+func (w *Webhooks) DeleteEndpoint(ctx context.Context, businessId, id int) error {
+	_, err := w.fetchOwned(ctx, businessId, id)
+	if err != nil {
+		return err
+	}
+	return w.WebhookRepo.DeleteEndpoint(ctx, id)
+}
+
+func (w *Webhooks) Deliveries(ctx context.Context, businessId, endpointId int, before int64, limit int) ([]WebhookDelivery, error) {
+	_, err := w.fetchOwned(ctx, businessId, endpointId)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxWebhookPage {
+		limit = maxWebhookPage
+	}
+	return w.WebhookRepo.FetchDeliveries(ctx, endpointId, before, limit)
+}
+
+// Queue the payload of the delivery again, it is sent on the next run
+func (w *Webhooks) Redeliver(ctx context.Context, businessId int, deliveryId int64) (*WebhookDelivery, error) {
+	old, err := w.WebhookRepo.FetchDelivery(ctx, deliveryId)
+	if errors.Is(err, errDoesNotExist) {
+		return nil, ErrWebhookNotExist(ctx)
+	} else if err != nil {
+		return nil, err
+	}
+	if old.BusinessId != businessId {
+		return nil, ErrWebhookNotExist(ctx)
+	}
+
+	now := time.Now()
+	delivery := &WebhookDelivery{
+		EndpointId:    old.EndpointId,
+		BusinessId:    old.BusinessId,
+		Event:         old.Event,
+		Payload:       old.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	return delivery, w.WebhookRepo.StoreDelivery(ctx, delivery)
+}
+
+// Queue the event for the endpoints of the business that subscribe to it.
+// The same event published again is not queued twice, the id must identify the occurrence of the event
+func (w *Webhooks) Publish(ctx context.Context, businessId int, id, event string, data interface{}) error {
+	endpoints, err := w.WebhookRepo.FetchEndpoints(ctx, businessId)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, v := range endpoints {
+		if !v.Subscribed(event) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(WebhookPayload{Id: id, Type: event, Created: now.Unix(), Data: data})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = w.WebhookRepo.StoreDelivery(ctx, &WebhookDelivery{
+			EndpointId:    v.Id,
+			BusinessId:    businessId,
+			Event:         event,
+			EventId:       id,
+			Payload:       payload,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The transfer is identified by its position on the chain, the indexer and the refresher publish the same id for it
+func webhookEventId(event string, transfer WebhookTransfer) string {
+	key := fmt.Sprintf("%s:%s:%d:%d:%d", event, transfer.Chain, transfer.Version, transfer.Index, transfer.Transfer)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Publish the transfer to the account that owns the receiving wallet
+func (w *Webhooks) publishTransfer(ctx context.Context, event string, transfer WebhookTransfer) error {
+	owner, err := w.WalletRepo.FetchOwner(ctx, transfer.Chain, transfer.To)
+	if errors.Is(err, errDoesNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return w.Publish(ctx, owner, webhookEventId(event, transfer), event, transfer)
+}
+
+func (w *Webhooks) NotifyDiem(ctx context.Context, event string, txs ...DiemTransaction) error {
+	for _, v := range txs {
+		err := w.publishTransfer(ctx, event, WebhookTransfer{
+			Chain:    v.Chain,
+			Version:  v.Version,
+			Hash:     v.Hash,
+			Status:   v.Status,
+			Time:     v.Time,
+			Currency: v.Currency,
+			From:     v.From,
+			To:       v.To,
+			Amount:   v.Amount.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Webhooks) NotifyCelo(ctx context.Context, event string, txs ...CeloTransaction) error {
+	for _, v := range txs {
+		for i, t := range v.TransferEvents {
+			err := w.publishTransfer(ctx, event, WebhookTransfer{
+				Chain:    v.Chain,
+				Version:  v.Version,
+				Index:    v.Index,
+				Transfer: i,
+				Hash:     v.Hash,
+				Status:   v.Status,
+				Time:     v.Time,
+				Currency: t.Currency,
+				From:     t.From,
+				To:       t.To,
+				Amount:   t.Amount.String(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Send the delivery once and schedule the next attempt if it fails
+func (w *Webhooks) attempt(ctx context.Context, delivery *WebhookDelivery) error {
+	endpoint, err := w.WebhookRepo.FetchEndpoint(ctx, delivery.EndpointId)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, now.Unix(), delivery.Payload))
+
+	delivery.Attempts++
+	res, err := w.Client.Do(req)
+	if err != nil {
+		delivery.ResponseCode = 0
+	} else {
+		io.Copy(io.Discard, io.LimitReader(res.Body, maxWebhookResponse))
+		res.Body.Close()
+		delivery.ResponseCode = res.StatusCode
+	}
+
+	if err == nil && res.StatusCode >= 200 && res.StatusCode < 300 {
+		delivery.Status = DeliverySucceeded
+		delivery.DeliveredAt = &now
+	} else if delivery.Attempts >= w.MaxAttempts {
+		delivery.Status = DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = now.Add(w.BaseDelay << (delivery.Attempts - 1))
+	}
+	return w.WebhookRepo.UpdateDelivery(ctx, delivery)
+}
+
+// A delivery that could not be attempted is tried again after the base delay
+// so it does not hold up the other deliveries
+func (w *Webhooks) reschedule(ctx context.Context, delivery *WebhookDelivery) {
+	delivery.NextAttemptAt = time.Now().Add(w.BaseDelay)
+	err := w.WebhookRepo.UpdateDelivery(ctx, delivery)
+	if err != nil {
+		log.Printf("fail to reschedule webhook delivery %d: %s", delivery.Id, err)
+	}
+}
+
+// Attempt the deliveries that are due, a failed delivery is logged and rescheduled.
+// Returns the number of attempts
+func (w *Webhooks) DeliverDue(ctx context.Context) (int, error) {
+	due, err := w.WebhookRepo.FetchDue(ctx, time.Now(), w.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for i := range due {
+		err = w.attempt(ctx, &due[i])
+		if errors.Is(err, context.Canceled) {
+			return attempted, err
+		}
+		if err != nil {
+			log.Printf("webhook delivery %d failed: %s", due[i].Id, err)
+			w.reschedule(ctx, &due[i])
+			continue
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// Deliver on every interval until the context is cancelled
+func (w *Webhooks) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.DeliverDue(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("webhook delivery failed: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
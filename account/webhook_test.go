@@ -0,0 +1,261 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/account"
+	"github.com/stevealexrs/Go-Libra/wallet"
+)
+
+type memoryWebhookRepo struct {
+	account.WebhookRepository
+	endpoints  []account.WebhookEndpoint
+	deliveries []account.WebhookDelivery
+}
+
+func (r *memoryWebhookRepo) StoreEndpoint(ctx context.Context, endpoint *account.WebhookEndpoint) error {
+	endpoint.Id = len(r.endpoints) + 1
+	r.endpoints = append(r.endpoints, *endpoint)
+	return nil
+}
+
+func (r *memoryWebhookRepo) FetchEndpoints(ctx context.Context, businessId int) ([]account.WebhookEndpoint, error) {
+	endpoints := make([]account.WebhookEndpoint, 0)
+	for _, v := range r.endpoints {
+		if v.BusinessId == businessId {
+			endpoints = append(endpoints, v)
+		}
+	}
+	return endpoints, nil
+}
+
+func (r *memoryWebhookRepo) FetchEndpoint(ctx context.Context, id int) (*account.WebhookEndpoint, error) {
+	if id > len(r.endpoints) {
+		return nil, errors.New("endpoint does not exist")
+	}
+	v := r.endpoints[id-1]
+	return &v, nil
+}
+
+func (r *memoryWebhookRepo) StoreDelivery(ctx context.Context, delivery *account.WebhookDelivery) error {
+	for _, v := range r.deliveries {
+		if delivery.EventId != "" && v.EndpointId == delivery.EndpointId && v.EventId == delivery.EventId {
+			return nil
+		}
+	}
+	delivery.Id = int64(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, *delivery)
+	return nil
+}
+
+func (r *memoryWebhookRepo) UpdateDelivery(ctx context.Context, delivery *account.WebhookDelivery) error {
+	r.deliveries[delivery.Id-1] = *delivery
+	return nil
+}
+
+func (r *memoryWebhookRepo) FetchDelivery(ctx context.Context, id int64) (*account.WebhookDelivery, error) {
+	v := r.deliveries[id-1]
+	return &v, nil
+}
+
+func (r *memoryWebhookRepo) FetchDue(ctx context.Context, at time.Time, limit int) ([]account.WebhookDelivery, error) {
+	due := make([]account.WebhookDelivery, 0)
+	for _, v := range r.deliveries {
+		if v.Status == account.DeliveryPending && !v.NextAttemptAt.After(at) {
+			due = append(due, v)
+		}
+	}
+	return due, nil
+}
+
+type ownerWalletRepo map[string]int
+
+func (r ownerWalletRepo) FetchOwner(ctx context.Context, chain, address string) (int, error) {
+	return r[address], nil
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	fail := true
+	var received []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := r.Header.Get(account.WebhookSignatureHeader)
+		received = append(received, signature)
+
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !strings.Contains(string(body), account.WebhookTransactionReceived) {
+			t.Errorf("unexpected body %s", body)
+		}
+	}))
+	defer server.Close()
+
+	repo := &memoryWebhookRepo{}
+	webhooks := account.Webhooks{
+		WebhookRepo:  repo,
+		WalletRepo:   ownerWalletRepo{"shop": 1},
+		Client:       server.Client(),
+		MaxEndpoints: 1,
+		MaxAttempts:  2,
+		BaseDelay:    time.Hour,
+		BatchSize:    10,
+	}
+
+	_, err := webhooks.CreateEndpoint(ctx, 1, "http://example.com", []string{account.WebhookTransactionReceived})
+	if err == nil {
+		t.Error("plain http endpoint is accepted")
+	}
+
+	endpoint, err := webhooks.CreateEndpoint(ctx, 1, server.URL, []string{account.WebhookTransactionReceived})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := account.DiemTransaction{}
+	tx.Chain = "Diem"
+	tx.To = "shop"
+	tx.Amount = big.NewInt(100)
+	err = webhooks.NotifyDiem(ctx, account.WebhookTransactionReceived, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = webhooks.NotifyDiem(ctx, account.WebhookTransactionUpdated, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(repo.deliveries))
+	}
+
+	_, err = webhooks.DeliverDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivery := repo.deliveries[0]
+	if delivery.Status != account.DeliveryPending || delivery.ResponseCode != 500 || time.Until(delivery.NextAttemptAt) < 59*time.Minute {
+		t.Errorf("failed delivery is not rescheduled: %+v", delivery)
+	}
+
+	unix, _ := strconv.ParseInt(strings.TrimPrefix(strings.Split(received[0], ",")[0], "t="), 10, 64)
+	if received[0] != account.SignWebhook(endpoint.Secret, unix, delivery.Payload) {
+		t.Errorf("unexpected signature %s", received[0])
+	}
+
+	fail = false
+	redelivery, err := webhooks.Redeliver(ctx, 1, delivery.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = webhooks.DeliverDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.deliveries[redelivery.Id-1].Status != account.DeliverySucceeded {
+		t.Errorf("redelivery is not sent: %+v", repo.deliveries[redelivery.Id-1])
+	}
+
+	_, err = webhooks.Redeliver(ctx, 2, delivery.Id)
+	if err == nil {
+		t.Error("delivery of another business is redelivered")
+	}
+}
+
+func TestWebhookInternalAddress(t *testing.T) {
+	ctx := context.Background()
+	received := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer server.Close()
+
+	repo := &memoryWebhookRepo{}
+	webhooks := account.Webhooks{
+		WebhookRepo:  repo,
+		WalletRepo:   ownerWalletRepo{"shop": 1},
+		Client:       account.NewWebhookClient(time.Second),
+		MaxEndpoints: 1,
+		MaxAttempts:  2,
+		BaseDelay:    time.Hour,
+		BatchSize:    10,
+	}
+
+	// a delivery that cannot be attempted does not hold up the others
+	repo.deliveries = append(repo.deliveries, account.WebhookDelivery{Id: 1, EndpointId: 99, Status: account.DeliveryPending})
+
+	_, err := webhooks.CreateEndpoint(ctx, 1, server.URL, []string{account.WebhookTransactionReceived})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = webhooks.Publish(ctx, 1, "event", account.WebhookTransactionReceived, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempted, err := webhooks.DeliverDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempted != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempted)
+	}
+	if received {
+		t.Error("delivery is sent to a loopback address")
+	}
+	for _, v := range repo.deliveries {
+		if v.Status != account.DeliveryPending || v.ResponseCode != 0 || time.Until(v.NextAttemptAt) < 59*time.Minute {
+			t.Errorf("failed delivery is not rescheduled: %+v", v)
+		}
+	}
+}
+
+func TestWebhookPublishOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryWebhookRepo{}
+	webhooks := account.Webhooks{
+		WebhookRepo:  repo,
+		WalletRepo:   ownerWalletRepo{"shop": 1},
+		MaxEndpoints: 1,
+	}
+
+	_, err := webhooks.CreateEndpoint(ctx, 1, "https://example.com/hook", []string{account.WebhookTransactionReceived})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := account.CeloTransaction{CeloTransaction: wallet.CeloTransaction{
+		TransactionBlock: wallet.TransactionBlock{Version: 10, Chain: "celo"},
+		Index:            2,
+		Hash:             "0x01",
+		TransferEvents:   map[int]wallet.Transfer{0: {Currency: "cUSD", From: "payer", To: "shop", Amount: big.NewInt(1)}},
+	}}
+	// the indexer and the refresher can both report a transfer
+	for i := 0; i < 2; i++ {
+		err = webhooks.NotifyCelo(ctx, account.WebhookTransactionReceived, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("expected the transfer to be queued once, got %d deliveries", len(repo.deliveries))
+	}
+
+	redelivery, err := webhooks.Redeliver(ctx, 1, repo.deliveries[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivery.Id == 0 || string(redelivery.Payload) != string(repo.deliveries[0].Payload) {
+		t.Errorf("redelivery is not queued with the same payload: %+v", redelivery)
+	}
+}
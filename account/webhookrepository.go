@@ -0,0 +1,218 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+type WebhookRepo struct {
+	DB *sql.DB
+}
+
+const (
+	webhookEndpointColumns = "Id, BusinessId, URL, Secret, Events, CreatedAt"
+	webhookDeliveryColumns = "Id, EndpointId, BusinessId, Event, EventId, Payload, Status, Attempts, ResponseCode, NextAttemptAt, CreatedAt, DeliveredAt"
+)
+
+func scanWebhookEndpoint(scan func(dest ...interface{}) error) (*WebhookEndpoint, error) {
+	var v WebhookEndpoint
+	var events string
+
+	err := scan(&v.Id, &v.BusinessId, &v.URL, &v.Secret, &events, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Events = strings.Split(events, ",")
+	return &v, nil
+}
+
+func scanWebhookDelivery(scan func(dest ...interface{}) error) (*WebhookDelivery, error) {
+	var v WebhookDelivery
+	var eventId sql.NullString
+	var deliveredAt sql.NullTime
+
+	err := scan(
+		&v.Id,
+		&v.EndpointId,
+		&v.BusinessId,
+		&v.Event,
+		&eventId,
+		&v.Payload,
+		&v.Status,
+		&v.Attempts,
+		&v.ResponseCode,
+		&v.NextAttemptAt,
+		&v.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v.EventId = eventId.String
+	if deliveredAt.Valid {
+		v.DeliveredAt = &deliveredAt.Time
+	}
+	return &v, nil
+}
+
+func (r *WebhookRepo) StoreEndpoint(ctx context.Context, endpoint *WebhookEndpoint) error {
+	res, err := r.DB.ExecContext(
+		ctx,
+		"INSERT INTO webhook_endpoint (BusinessId, URL, Secret, Events, CreatedAt) VALUES(?, ?, ?, ?, ?);",
+		endpoint.BusinessId,
+		endpoint.URL,
+		endpoint.Secret,
+		strings.Join(endpoint.Events, ","),
+		endpoint.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return nil
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	endpoint.Id = int(lastId)
+	return nil
+}
+
+func (r *WebhookRepo) FetchEndpoints(ctx context.Context, businessId int) ([]WebhookEndpoint, error) {
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoint WHERE BusinessId = ? ORDER BY Id DESC;"
+
+	rows, err := r.DB.QueryContext(ctx, query, businessId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := make([]WebhookEndpoint, 0)
+	for rows.Next() {
+		v, err := scanWebhookEndpoint(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, *v)
+	}
+	return endpoints, rows.Err()
+}
+
+func (r *WebhookRepo) FetchEndpoint(ctx context.Context, id int) (*WebhookEndpoint, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+webhookEndpointColumns+" FROM webhook_endpoint WHERE Id = ? LIMIT 1;", id)
+
+	endpoint, err := scanWebhookEndpoint(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return endpoint, err
+}
+
+func (r *WebhookRepo) DeleteEndpoint(ctx context.Context, id int) error {
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM webhook_endpoint WHERE Id = ?;", id)
+	return err
+}
+
+// A delivery of an event that is already queued for the endpoint is not stored again and its id is left 0
+func (r *WebhookRepo) StoreDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	var eventId sql.NullString
+	if delivery.EventId != "" {
+		eventId = sql.NullString{String: delivery.EventId, Valid: true}
+	}
+
+	query := "INSERT INTO webhook_delivery (EndpointId, BusinessId, Event, EventId, Payload, Status, NextAttemptAt, CreatedAt) VALUES(?, ?, ?, ?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE Id = Id;"
+	res, err := r.DB.ExecContext(
+		ctx,
+		query,
+		delivery.EndpointId,
+		delivery.BusinessId,
+		delivery.Event,
+		eventId,
+		delivery.Payload,
+		delivery.Status,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	lastId, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	delivery.Id = lastId
+	return nil
+}
+
+func (r *WebhookRepo) UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	query := "UPDATE webhook_delivery SET Status = ?, Attempts = ?, ResponseCode = ?, NextAttemptAt = ?, DeliveredAt = ? WHERE Id = ?;"
+
+	_, err := r.DB.ExecContext(
+		ctx,
+		query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.NextAttemptAt,
+		delivery.DeliveredAt,
+		delivery.Id,
+	)
+	return err
+}
+
+func (r *WebhookRepo) FetchDelivery(ctx context.Context, id int64) (*WebhookDelivery, error) {
+	row := r.DB.QueryRowContext(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_delivery WHERE Id = ? LIMIT 1;", id)
+
+	delivery, err := scanWebhookDelivery(row.Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errDoesNotExist
+	}
+	return delivery, err
+}
+
+func (r *WebhookRepo) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]WebhookDelivery, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		v, err := scanWebhookDelivery(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *v)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *WebhookRepo) FetchDeliveries(ctx context.Context, endpointId int, before int64, limit int) ([]WebhookDelivery, error) {
+	if before > 0 {
+		query := "SELECT " + webhookDeliveryColumns + " FROM webhook_delivery WHERE EndpointId = ? AND Id < ? ORDER BY Id DESC LIMIT ?;"
+		return r.queryDeliveries(ctx, query, endpointId, before, limit)
+	}
+
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_delivery WHERE EndpointId = ? ORDER BY Id DESC LIMIT ?;"
+	return r.queryDeliveries(ctx, query, endpointId, limit)
+}
+
+func (r *WebhookRepo) FetchDue(ctx context.Context, at time.Time, limit int) ([]WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_delivery WHERE Status = ? AND NextAttemptAt <= ? ORDER BY NextAttemptAt LIMIT ?;"
+	return r.queryDeliveries(ctx, query, DeliveryPending, at, limit)
+}
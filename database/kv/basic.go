@@ -21,6 +21,12 @@ type Expirer interface {
 	Expire(ctx context.Context, key string, expiration time.Duration) (bool, error)
 }
 
+type Incrementer interface {
+	// Increase the number stored in key by one and reset its expiration in one transaction,
+	// missing key starts from 0
+	IncrWithExpiration(ctx context.Context, key string, expiration time.Duration) (int, error)
+}
+
 type Store interface {
 	Set(ctx context.Context, key string, value interface{}) error
 	Getter
@@ -33,4 +39,9 @@ type ExpiringStore interface {
 	Getter
 	Deleter
 	Exister
-}
\ No newline at end of file
+}
+
+type ExpiringCounter interface {
+	ExpiringStore
+	Incrementer
+}
@@ -0,0 +1,271 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Migrations are named as <version>_<name>.up.sql and <version>_<name>.down.sql.
+// An up migration can contain a backfill marker line where the backfill registered for its version runs
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const versionTable = "schema_migration"
+
+const backfillMarker = "-- +backfill"
+
+// Fill data that can only be computed in Go
+type BackfillFunc func(ctx context.Context, db *sql.DB) error
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	// Number of Up statements run before the backfill, -1 if the migration has no backfill
+	BackfillAt int
+	Down       []string
+}
+
+type Migrator struct {
+	DB         *sql.DB
+	migrations []Migration
+	backfills  map[int]BackfillFunc
+}
+
+// Load the migrations embedded in the binary
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, migrations: migrations, backfills: make(map[int]BackfillFunc)}, nil
+}
+
+// Run fn at the backfill marker of the migration with the version
+func (m *Migrator) RegisterBackfill(version int, fn BackfillFunc) {
+	m.backfills[version] = fn
+}
+
+// Load and sort the migrations in the sql directory of fsys,
+// every version must be consecutive and have both up and down migration
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, v := range entries {
+		name := v.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %v", name, err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		} else if m.Name != parts[1] {
+			return nil, fmt.Errorf("migration %d has different names %s and %s", version, m.Name, parts[1])
+		}
+
+		if direction == "up" {
+			parts := strings.SplitN(string(content), backfillMarker, 2)
+			m.Up = splitStatements(parts[0])
+			m.BackfillAt = -1
+			if len(parts) == 2 {
+				m.BackfillAt = len(m.Up)
+				m.Up = append(m.Up, splitStatements(parts[1])...)
+			}
+		} else {
+			m.Down = splitStatements(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, v := range byVersion {
+		migrations = append(migrations, *v)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, v := range migrations {
+		if v.Version != i+1 {
+			return nil, fmt.Errorf("migration version %d is missing", i+1)
+		}
+		if v.Up == nil || v.Down == nil {
+			return nil, fmt.Errorf("migration %d must have both up and down file", v.Version)
+		}
+	}
+	return migrations, nil
+}
+
+// The driver does not run multiple statements in one call,
+// comments are removed and the statements are separated by semicolon
+func splitStatements(content string) []string {
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, v := range lines {
+		if strings.HasPrefix(strings.TrimSpace(v), "--") {
+			continue
+		}
+		kept = append(kept, v)
+	}
+
+	statements := make([]string, 0)
+	for _, v := range strings.Split(strings.Join(kept, "\n"), ";") {
+		if stmt := strings.TrimSpace(v); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Latest version known by the binary
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	_, err := m.DB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+versionTable+" ("+
+		"Version INT NOT NULL, "+
+		"AppliedAt DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, "+
+		"PRIMARY KEY (Version)"+
+		") ENGINE=InnoDB;")
+	return err
+}
+
+// Current version of the database, 0 if nothing is applied
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	err := m.ensureVersionTable(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = m.DB.QueryRowContext(ctx, "SELECT COALESCE(MAX(Version), 0) FROM "+versionTable+";").Scan(&version)
+	return version, err
+}
+
+// Apply every pending migration.
+// DDL in MySQL commits implicitly, a failed migration has to be cleaned up by hand
+func (m *Migrator) Up(ctx context.Context) error {
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current > m.Latest() {
+		return fmt.Errorf("database version %d is newer than the latest migration %d", current, m.Latest())
+	}
+
+	for _, v := range m.migrations[current:] {
+		err = m.up(ctx, v)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %v", v.Version, v.Name, err)
+		}
+
+		_, err = m.DB.ExecContext(ctx, "INSERT INTO "+versionTable+" (Version) VALUES(?);", v.Version)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) up(ctx context.Context, v Migration) error {
+	for i, stmt := range v.Up {
+		if i == v.BackfillAt {
+			err := m.backfill(ctx, v)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := m.DB.ExecContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if v.BackfillAt == len(v.Up) {
+		return m.backfill(ctx, v)
+	}
+	return nil
+}
+
+func (m *Migrator) backfill(ctx context.Context, v Migration) error {
+	fn, ok := m.backfills[v.Version]
+	if !ok {
+		return errors.New("backfill is not registered")
+	}
+	return fn(ctx, m.DB)
+}
+
+// Revert the last n applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps < 0 {
+		return errors.New("steps cannot be negative")
+	}
+
+	current, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current > m.Latest() {
+		return fmt.Errorf("database version %d is newer than the latest migration %d", current, m.Latest())
+	}
+
+	for i := 0; i < steps && current > 0; i++ {
+		v := m.migrations[current-1]
+		for _, stmt := range v.Down {
+			_, err = m.DB.ExecContext(ctx, stmt)
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %v", v.Version, v.Name, err)
+			}
+		}
+
+		_, err = m.DB.ExecContext(ctx, "DELETE FROM "+versionTable+" WHERE Version = ?;", v.Version)
+		if err != nil {
+			return err
+		}
+		current--
+	}
+	return nil
+}
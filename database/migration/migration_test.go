@@ -0,0 +1,97 @@
+package migration_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stevealexrs/Go-Libra/database/migration"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := migration.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Latest() == 0 {
+		t.Fatal("expect at least one migration")
+	}
+
+	for _, v := range m.Migrations() {
+		for _, stmt := range append(v.Up, v.Down...) {
+			if strings.HasPrefix(stmt, "--") || strings.HasSuffix(stmt, ";") {
+				t.Errorf("migration %d has an unsplit statement: %s", v.Version, stmt)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_first.up.sql":    {Data: []byte("-- comment\nCREATE TABLE a (Id INT);\nCREATE TABLE b (Id INT);\n")},
+		"sql/0001_first.down.sql":  {Data: []byte("DROP TABLE b;\nDROP TABLE a;")},
+		"sql/0002_second.up.sql":   {Data: []byte("CREATE TABLE c (Id INT);")},
+		"sql/0002_second.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+
+	migrations, err := migration.Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("expect 2 migrations, got %v", len(migrations))
+	}
+
+	if res := migrations[0].Up; len(res) != 2 || res[0] != "CREATE TABLE a (Id INT)" {
+		t.Errorf("unexpected statements %q", res)
+	}
+
+	if migrations[1].Name != "second" {
+		t.Errorf("expect name second, got %v", migrations[1].Name)
+	}
+}
+
+func TestLoadBackfill(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_first.up.sql":    {Data: []byte("ALTER TABLE a ADD COLUMN B INT NULL;\n-- +backfill\nALTER TABLE a MODIFY COLUMN B INT NOT NULL;")},
+		"sql/0001_first.down.sql":  {Data: []byte("ALTER TABLE a DROP COLUMN B;")},
+		"sql/0002_second.up.sql":   {Data: []byte("CREATE TABLE c (Id INT);")},
+		"sql/0002_second.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+
+	migrations, err := migration.Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res := migrations[0]; len(res.Up) != 2 || res.BackfillAt != 1 {
+		t.Errorf("expect the backfill after the first statement, got %d of %q", res.BackfillAt, res.Up)
+	}
+
+	if res := migrations[1].BackfillAt; res != -1 {
+		t.Errorf("expect no backfill, got %d", res)
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0001_first.up.sql":   {Data: []byte("CREATE TABLE a (Id INT);")},
+		"sql/0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+		"sql/0003_third.up.sql":   {Data: []byte("CREATE TABLE c (Id INT);")},
+		"sql/0003_third.down.sql": {Data: []byte("DROP TABLE c;")},
+	}
+
+	if _, err := migration.Load(fsys); err == nil {
+		t.Error("expect error for a missing version")
+	}
+
+	fsys = fstest.MapFS{
+		"sql/0001_first.up.sql": {Data: []byte("CREATE TABLE a (Id INT);")},
+	}
+
+	if _, err := migration.Load(fsys); err == nil {
+		t.Error("expect error for a missing down migration")
+	}
+}
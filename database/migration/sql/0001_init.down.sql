@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS transaction_context;
+DROP TABLE IF EXISTS transaction_sender;
+DROP TABLE IF EXISTS transaction_celo_transfer;
+DROP TABLE IF EXISTS transaction_celo;
+DROP TABLE IF EXISTS transaction_diem;
+DROP TABLE IF EXISTS transaction;
+DROP TABLE IF EXISTS wallet;
+DROP TABLE IF EXISTS business_parent_child;
+DROP TABLE IF EXISTS business_identity;
+DROP TABLE IF EXISTS business;
+DROP TABLE IF EXISTS user;
+DROP TABLE IF EXISTS account;
@@ -0,0 +1,146 @@
+-- Tables are in snake_case while columns are in PascalCase.
+-- Column order matters, the repositories insert with positional VALUES(...).
+
+CREATE TABLE account (
+    Id INT NOT NULL AUTO_INCREMENT,
+    Username VARCHAR(64) NOT NULL,
+    PasswordHash VARBINARY(255) NOT NULL,
+    RecoveryEmail VARCHAR(254) NOT NULL DEFAULT '',
+    UnverifiedRecoveryEmail VARCHAR(254) NOT NULL DEFAULT '',
+    Deleted BIT(1) NOT NULL DEFAULT b'0',
+    PRIMARY KEY (Id),
+    UNIQUE KEY UsernameUnique (Username),
+    KEY RecoveryEmailIndex (RecoveryEmail)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE user (
+    Id INT NOT NULL,
+    DisplayName VARCHAR(64) NOT NULL DEFAULT '',
+    InvitationEmail VARCHAR(254) NOT NULL DEFAULT '',
+    PRIMARY KEY (Id),
+    KEY InvitationEmailIndex (InvitationEmail),
+    CONSTRAINT UserAccount FOREIGN KEY (Id) REFERENCES account (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE business (
+    Id INT NOT NULL,
+    DisplayName VARCHAR(64) NOT NULL DEFAULT '',
+    DisplayNameVerified BIT(1) NOT NULL DEFAULT b'0',
+    PRIMARY KEY (Id),
+    CONSTRAINT BusinessAccount FOREIGN KEY (Id) REFERENCES account (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE business_identity (
+    Id INT NOT NULL,
+    BusinessOfficialName VARCHAR(255) NOT NULL DEFAULT '',
+    BusinessRegistrationNumber VARCHAR(64) NOT NULL DEFAULT '',
+    BusinessAddress VARCHAR(512) NOT NULL DEFAULT '',
+    -- comma separated file id of the object store
+    Documents TEXT NOT NULL,
+    Verified BIT(1) NOT NULL DEFAULT b'0',
+    PRIMARY KEY (Id),
+    CONSTRAINT BusinessIdentityBusiness FOREIGN KEY (Id) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- A business has at most one parent
+CREATE TABLE business_parent_child (
+    ParentId INT NOT NULL,
+    ChildId INT NOT NULL,
+    PRIMARY KEY (ChildId),
+    KEY ParentIndex (ParentId),
+    CONSTRAINT ParentBusiness FOREIGN KEY (ParentId) REFERENCES business (Id) ON DELETE CASCADE,
+    CONSTRAINT ChildBusiness FOREIGN KEY (ChildId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE wallet (
+    Chain VARCHAR(16) NOT NULL,
+    Address VARCHAR(64) NOT NULL,
+    AccountId INT NOT NULL,
+    PublicKey VARCHAR(132) NOT NULL DEFAULT '',
+    PRIMARY KEY (Chain, Address),
+    KEY AccountIndex (AccountId, Chain),
+    CONSTRAINT WalletAccount FOREIGN KEY (AccountId) REFERENCES account (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Diem transactions always have an index of 0,
+-- Celo transactions are identified by block number and transaction index
+CREATE TABLE transaction (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    GasPrice DECIMAL(65, 0),
+    GasUsed BIGINT NOT NULL,
+    MaxGas BIGINT NOT NULL,
+    Time DATETIME(6) NOT NULL,
+    Status VARCHAR(255) NOT NULL DEFAULT '',
+    Hash VARCHAR(66) NOT NULL,
+    PRIMARY KEY (Version, Chain, `Index`),
+    KEY TimeIndex (Time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE transaction_diem (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    PublicKey VARCHAR(66) NOT NULL DEFAULT '',
+    GasCurrency VARCHAR(16) NOT NULL DEFAULT '',
+    Currency VARCHAR(16) NOT NULL DEFAULT '',
+    Amount DECIMAL(65, 0),
+    `From` VARCHAR(64) NOT NULL,
+    `To` VARCHAR(64) NOT NULL,
+    PRIMARY KEY (Version, Chain, `Index`),
+    KEY FromIndex (`From`),
+    KEY ToIndex (`To`),
+    CONSTRAINT DiemTransaction FOREIGN KEY (Version, Chain, `Index`)
+        REFERENCES transaction (Version, Chain, `Index`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE transaction_celo (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    GatewayCurrency VARCHAR(42) NOT NULL DEFAULT '',
+    GatewayFee DECIMAL(65, 0),
+    GatewayRecipient VARCHAR(42) NOT NULL DEFAULT '',
+    PRIMARY KEY (Version, Chain, `Index`),
+    CONSTRAINT CeloTransaction FOREIGN KEY (Version, Chain, `Index`)
+        REFERENCES transaction (Version, Chain, `Index`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE transaction_celo_transfer (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    LogIndex INT NOT NULL,
+    Currency VARCHAR(42) NOT NULL DEFAULT '',
+    Amount DECIMAL(65, 0),
+    `From` VARCHAR(42) NOT NULL,
+    `To` VARCHAR(42) NOT NULL,
+    PRIMARY KEY (Version, Chain, `Index`, LogIndex),
+    KEY FromIndex (`From`),
+    KEY ToIndex (`To`),
+    CONSTRAINT CeloTransfer FOREIGN KEY (Version, Chain, `Index`)
+        REFERENCES transaction_celo (Version, Chain, `Index`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Remarks are not tied to the transaction table by foreign key,
+-- they must survive when a transaction is deleted and stored again after a chain mismatch
+CREATE TABLE transaction_sender (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    Message VARCHAR(1024) NOT NULL DEFAULT '',
+    Refund BIT(1) NOT NULL DEFAULT b'0',
+    PRIMARY KEY (Version, Chain, `Index`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE transaction_context (
+    Version BIGINT UNSIGNED NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    `Index` INT NOT NULL,
+    AccountId INT NOT NULL,
+    Message VARCHAR(1024) NOT NULL DEFAULT '',
+    PRIMARY KEY (Version, Chain, `Index`, AccountId),
+    KEY AccountIndex (AccountId),
+    CONSTRAINT ContextAccount FOREIGN KEY (AccountId) REFERENCES account (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS business_review;
+DROP TABLE IF EXISTS business_review_queue;
+DROP TABLE IF EXISTS staff;
@@ -0,0 +1,36 @@
+-- Staff are user accounts with access to the review queue
+CREATE TABLE staff (
+    Id INT NOT NULL,
+    PRIMARY KEY (Id),
+    CONSTRAINT StaffUser FOREIGN KEY (Id) REFERENCES user (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Item is either displayname or identity
+CREATE TABLE business_review_queue (
+    BusinessId INT NOT NULL,
+    Item VARCHAR(16) NOT NULL,
+    SubmittedAt DATETIME(6) NOT NULL,
+    PRIMARY KEY (BusinessId, Item),
+    KEY SubmittedIndex (SubmittedAt),
+    CONSTRAINT QueueBusiness FOREIGN KEY (BusinessId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE business_review (
+    Id INT NOT NULL AUTO_INCREMENT,
+    BusinessId INT NOT NULL,
+    Item VARCHAR(16) NOT NULL,
+    Approved BIT(1) NOT NULL,
+    Reason VARCHAR(1024) NOT NULL DEFAULT '',
+    ReviewerId INT NOT NULL,
+    Time DATETIME(6) NOT NULL,
+    PRIMARY KEY (Id),
+    KEY BusinessIndex (BusinessId, Time),
+    CONSTRAINT ReviewBusiness FOREIGN KEY (BusinessId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Queue every business that was registered before the review workflow
+INSERT INTO business_review_queue
+    SELECT Id, 'displayname', NOW(6) FROM business WHERE DisplayNameVerified = b'0';
+
+INSERT INTO business_review_queue
+    SELECT Id, 'identity', NOW(6) FROM business_identity WHERE Verified = b'0';
@@ -0,0 +1,2 @@
+ALTER TABLE business_parent_child
+    DROP COLUMN Active;
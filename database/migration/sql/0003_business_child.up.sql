@@ -0,0 +1,3 @@
+-- Parent can deactivate a child business without deleting it
+ALTER TABLE business_parent_child
+    ADD COLUMN Active BIT(1) NOT NULL DEFAULT b'1';
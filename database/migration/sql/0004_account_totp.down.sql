@@ -0,0 +1,4 @@
+ALTER TABLE account
+    DROP COLUMN TotpLastStep,
+    DROP COLUMN TotpEnabled,
+    DROP COLUMN TotpSecret;
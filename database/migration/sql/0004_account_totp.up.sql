@@ -0,0 +1,5 @@
+-- Secret is encrypted by the application, last step prevents a code from being used twice
+ALTER TABLE account
+    ADD COLUMN TotpSecret VARBINARY(128) NULL,
+    ADD COLUMN TotpEnabled BIT(1) NOT NULL DEFAULT b'0',
+    ADD COLUMN TotpLastStep BIGINT UNSIGNED NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+-- WebAuthn credentials, users and businesses share the account id
+CREATE TABLE passkey (
+    Id VARBINARY(255) NOT NULL,
+    AccountId INT NOT NULL,
+    PublicKey VARBINARY(1024) NOT NULL,
+    SignCount INT UNSIGNED NOT NULL,
+    CreatedAt DATETIME NOT NULL,
+    PRIMARY KEY (Id),
+    KEY AccountId (AccountId),
+    CONSTRAINT PasskeyAccount FOREIGN KEY (AccountId) REFERENCES account (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+ALTER TABLE account
+    DROP KEY DeletedAtIndex,
+    DROP COLUMN Purged,
+    DROP COLUMN DeletedAt;
@@ -0,0 +1,6 @@
+-- Deleted accounts can be restored until the grace period after DeletedAt ends,
+-- then the personal data is anonymised and Purged is set
+ALTER TABLE account
+    ADD COLUMN DeletedAt DATETIME NULL,
+    ADD COLUMN Purged BIT(1) NOT NULL DEFAULT b'0',
+    ADD KEY DeletedAtIndex (DeletedAt);
@@ -0,0 +1,3 @@
+ALTER TABLE account
+    DROP KEY UsernameCanonicalUnique,
+    DROP COLUMN UsernameCanonical;
@@ -0,0 +1,11 @@
+-- Uniqueness is enforced on the canonical form of the username while Username keeps the form typed by the owner.
+-- The canonical forms are computed by the backfill, which renames accounts whose usernames look the same
+-- as the username of an older account
+ALTER TABLE account
+    ADD COLUMN UsernameCanonical VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL AFTER Username;
+
+-- +backfill
+
+ALTER TABLE account
+    MODIFY COLUMN UsernameCanonical VARCHAR(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    ADD UNIQUE KEY UsernameCanonicalUnique (UsernameCanonical);
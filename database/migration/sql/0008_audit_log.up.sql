@@ -0,0 +1,24 @@
+-- Security events of accounts, rows can only be inserted.
+-- There is no foreign key so the events outlive the account rows
+CREATE TABLE audit_log (
+    Id BIGINT NOT NULL AUTO_INCREMENT,
+    AccountId INT NULL,
+    ActorId INT NULL,
+    Event VARCHAR(64) NOT NULL,
+    IP VARCHAR(45) NOT NULL DEFAULT '',
+    UserAgent VARCHAR(255) NOT NULL DEFAULT '',
+    RequestId VARCHAR(64) NOT NULL DEFAULT '',
+    Metadata JSON NULL,
+    CreatedAt DATETIME(3) NOT NULL,
+    PRIMARY KEY (Id),
+    KEY AccountIndex (AccountId, Id),
+    KEY EventIndex (Event, Id),
+    KEY IPIndex (IP, Id),
+    KEY CreatedAtIndex (CreatedAt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
@@ -0,0 +1,2 @@
+DROP TABLE invitation_quota;
+DROP TABLE invitation;
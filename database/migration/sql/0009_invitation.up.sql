@@ -0,0 +1,25 @@
+-- Invitations issued by users, InviteeId is set after the invitee registers.
+-- Revoked invitations do not count toward the quota of the inviter
+CREATE TABLE invitation (
+    Id INT NOT NULL AUTO_INCREMENT,
+    InviterId INT NOT NULL,
+    Email VARCHAR(254) NOT NULL,
+    InviteeId INT NULL,
+    Revoked BIT(1) NOT NULL DEFAULT b'0',
+    CreatedAt DATETIME NOT NULL,
+    SentAt DATETIME NOT NULL,
+    PRIMARY KEY (Id),
+    KEY InviterIndex (InviterId, Id),
+    KEY EmailIndex (Email),
+    UNIQUE KEY InviteeUnique (InviteeId),
+    CONSTRAINT InvitationInviter FOREIGN KEY (InviterId) REFERENCES user (Id) ON DELETE CASCADE,
+    CONSTRAINT InvitationInvitee FOREIGN KEY (InviteeId) REFERENCES user (Id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Users without a row have the default quota
+CREATE TABLE invitation_quota (
+    UserId INT NOT NULL,
+    Quota INT NOT NULL,
+    PRIMARY KEY (UserId),
+    CONSTRAINT InvitationQuotaUser FOREIGN KEY (UserId) REFERENCES user (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
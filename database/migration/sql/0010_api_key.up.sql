@@ -0,0 +1,17 @@
+-- Bearer keys of businesses, only the SHA-256 of the secret part is stored.
+-- Scopes are comma separated
+CREATE TABLE api_key (
+    Id INT NOT NULL AUTO_INCREMENT,
+    BusinessId INT NOT NULL,
+    Name VARCHAR(64) NOT NULL DEFAULT '',
+    LookupId CHAR(32) NOT NULL,
+    SecretHash BINARY(32) NOT NULL,
+    Scopes VARCHAR(255) NOT NULL DEFAULT '',
+    CreatedAt DATETIME NOT NULL,
+    LastUsedAt DATETIME NULL,
+    RevokedAt DATETIME NULL,
+    PRIMARY KEY (Id),
+    UNIQUE KEY LookupIdUnique (LookupId),
+    KEY BusinessIndex (BusinessId),
+    CONSTRAINT ApiKeyBusiness FOREIGN KEY (BusinessId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
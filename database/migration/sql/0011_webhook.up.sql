@@ -0,0 +1,32 @@
+-- Endpoints of businesses, events are comma separated
+CREATE TABLE webhook_endpoint (
+    Id INT NOT NULL AUTO_INCREMENT,
+    BusinessId INT NOT NULL,
+    URL VARCHAR(2048) NOT NULL,
+    Secret VARCHAR(64) NOT NULL,
+    Events VARCHAR(255) NOT NULL DEFAULT '',
+    CreatedAt DATETIME NOT NULL,
+    PRIMARY KEY (Id),
+    KEY BusinessIndex (BusinessId),
+    CONSTRAINT WebhookEndpointBusiness FOREIGN KEY (BusinessId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Delivery history, pending deliveries are picked up by the dispatcher when NextAttemptAt is reached.
+-- Only the status code of the response is kept, 0 if no response is received
+CREATE TABLE webhook_delivery (
+    Id BIGINT NOT NULL AUTO_INCREMENT,
+    EndpointId INT NOT NULL,
+    BusinessId INT NOT NULL,
+    Event VARCHAR(64) NOT NULL,
+    Payload MEDIUMBLOB NOT NULL,
+    Status VARCHAR(16) NOT NULL,
+    Attempts INT NOT NULL DEFAULT 0,
+    ResponseCode INT NOT NULL DEFAULT 0,
+    NextAttemptAt DATETIME NOT NULL,
+    CreatedAt DATETIME NOT NULL,
+    DeliveredAt DATETIME NULL,
+    PRIMARY KEY (Id),
+    KEY DueIndex (Status, NextAttemptAt),
+    KEY EndpointIndex (EndpointId, Id),
+    CONSTRAINT WebhookDeliveryEndpoint FOREIGN KEY (EndpointId) REFERENCES webhook_endpoint (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
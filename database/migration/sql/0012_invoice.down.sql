@@ -0,0 +1,2 @@
+DROP TABLE invoice_payment;
+DROP TABLE invoice;
@@ -0,0 +1,36 @@
+-- Amounts are in the smallest unit of the currency
+CREATE TABLE invoice (
+    Id INT NOT NULL AUTO_INCREMENT,
+    BusinessId INT NOT NULL,
+    Reference CHAR(32) NOT NULL,
+    Chain VARCHAR(16) NOT NULL,
+    Address VARCHAR(64) NOT NULL,
+    Currency VARCHAR(42) NOT NULL,
+    Amount DECIMAL(65, 0) NOT NULL,
+    Received DECIMAL(65, 0) NOT NULL DEFAULT 0,
+    Memo VARCHAR(255) NOT NULL DEFAULT '',
+    Status VARCHAR(16) NOT NULL,
+    ExpiresAt DATETIME NOT NULL,
+    CreatedAt DATETIME NOT NULL,
+    PaidAt DATETIME NULL,
+    PRIMARY KEY (Id),
+    UNIQUE KEY ReferenceUnique (Reference),
+    KEY BusinessIndex (BusinessId, Id),
+    KEY OpenIndex (Chain, Address, Currency, Status),
+    CONSTRAINT InvoiceBusiness FOREIGN KEY (BusinessId) REFERENCES business (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- A transfer can only pay one invoice once
+CREATE TABLE invoice_payment (
+    Chain VARCHAR(16) NOT NULL,
+    Version BIGINT UNSIGNED NOT NULL,
+    `Index` INT NOT NULL,
+    Transfer INT NOT NULL,
+    InvoiceId INT NOT NULL,
+    `From` VARCHAR(64) NOT NULL,
+    Amount DECIMAL(65, 0) NOT NULL,
+    Time DATETIME(6) NOT NULL,
+    PRIMARY KEY (Chain, Version, `Index`, Transfer),
+    KEY InvoiceIndex (InvoiceId),
+    CONSTRAINT PaymentInvoice FOREIGN KEY (InvoiceId) REFERENCES invoice (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
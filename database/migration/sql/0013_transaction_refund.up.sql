@@ -0,0 +1,20 @@
+-- Links an outgoing transfer of a business to the incoming transfer it returns.
+-- Diem transfers have a Transfer of 0, Celo transfers use the log index
+CREATE TABLE transaction_refund (
+    Chain VARCHAR(16) NOT NULL,
+    Version BIGINT UNSIGNED NOT NULL,
+    `Index` INT NOT NULL,
+    Transfer INT NOT NULL,
+    OriginalVersion BIGINT UNSIGNED NOT NULL,
+    OriginalIndex INT NOT NULL,
+    OriginalTransfer INT NOT NULL,
+    BusinessId INT NOT NULL,
+    Recipient VARCHAR(64) NOT NULL,
+    Currency VARCHAR(42) NOT NULL,
+    Amount DECIMAL(65, 0) NOT NULL,
+    CreatedAt DATETIME NOT NULL,
+    PRIMARY KEY (Chain, Version, `Index`, Transfer),
+    KEY OriginalIndex (Chain, OriginalVersion, OriginalIndex, OriginalTransfer),
+    KEY BusinessIndex (BusinessId, CreatedAt),
+    KEY RecipientIndex (Chain, Recipient)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
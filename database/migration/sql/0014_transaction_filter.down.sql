@@ -0,0 +1,6 @@
+ALTER TABLE transaction_context DROP INDEX MessageText;
+ALTER TABLE transaction_sender DROP INDEX MessageText;
+ALTER TABLE transaction_sender DROP INDEX RefundIndex;
+ALTER TABLE transaction_celo_transfer DROP INDEX CurrencyAmountIndex;
+ALTER TABLE transaction_diem DROP INDEX CurrencyAmountIndex;
+ALTER TABLE transaction DROP INDEX StatusTimeIndex;
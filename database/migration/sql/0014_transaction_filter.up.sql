@@ -0,0 +1,7 @@
+-- Indexes of the transaction history filters
+ALTER TABLE transaction ADD KEY StatusTimeIndex (Status, Time);
+ALTER TABLE transaction_diem ADD KEY CurrencyAmountIndex (Currency, Amount);
+ALTER TABLE transaction_celo_transfer ADD KEY CurrencyAmountIndex (Currency, Amount);
+ALTER TABLE transaction_sender ADD KEY RefundIndex (Refund);
+ALTER TABLE transaction_sender ADD FULLTEXT KEY MessageText (Message);
+ALTER TABLE transaction_context ADD FULLTEXT KEY MessageText (Message);
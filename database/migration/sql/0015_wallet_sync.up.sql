@@ -0,0 +1,13 @@
+-- Position of the indexer in the history of a wallet. Diem wallets are indexed by the
+-- sequence numbers of their next sent and received events, Celo wallets by the last indexed block
+CREATE TABLE wallet_sync (
+    Chain VARCHAR(16) NOT NULL,
+    Address VARCHAR(64) NOT NULL,
+    SentSequence BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    ReceivedSequence BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    Block BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    SyncedAt DATETIME NOT NULL,
+    PRIMARY KEY (Chain, Address),
+    KEY SyncedIndex (SyncedAt),
+    CONSTRAINT WalletSyncWallet FOREIGN KEY (Chain, Address) REFERENCES wallet (Chain, Address) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
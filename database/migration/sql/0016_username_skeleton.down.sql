@@ -0,0 +1,2 @@
+-- The skeletons are kept since the previous canonical forms of renamed accounts cannot be restored,
+-- they need the wider column
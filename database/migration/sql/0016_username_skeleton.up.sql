@@ -0,0 +1,11 @@
+-- Canonical usernames are also mapped to their confusable skeleton, which can be longer than the username.
+-- The backfill recomputes the canonical forms and renames accounts whose usernames now look the same
+-- as the username of an older account
+ALTER TABLE account
+    DROP KEY UsernameCanonicalUnique,
+    MODIFY COLUMN UsernameCanonical VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
+
+-- +backfill
+
+ALTER TABLE account
+    ADD UNIQUE KEY UsernameCanonicalUnique (UsernameCanonical);
@@ -0,0 +1,3 @@
+ALTER TABLE webhook_delivery
+    DROP INDEX EndpointEventIndex,
+    DROP COLUMN EventId;
@@ -0,0 +1,5 @@
+-- Id of the published event, an event is queued once per endpoint however many times it is published.
+-- Redeliveries leave it NULL so they are not held to the key
+ALTER TABLE webhook_delivery
+    ADD COLUMN EventId VARCHAR(64) NULL AFTER Event,
+    ADD UNIQUE KEY EndpointEventIndex (EndpointId, EventId);
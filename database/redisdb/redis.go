@@ -115,4 +115,17 @@ func (handler *Handler) HDel(ctx context.Context, key string, fields ...string)
 
 func (handler *Handler) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
 	return handler.Client.Expire(ctx, key, expiration).Result()
-}
\ No newline at end of file
+}
+
+func (handler *Handler) IncrWithExpiration(ctx context.Context, key string, expiration time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := handler.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, expiration)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
@@ -153,3 +153,137 @@ func (s *Client) ResetPassword(ctx context.Context, to, username, token string)
 
 	return s.Send([]string{to}, msg)
 }
+
+func (s *Client) NotifyReviewDecision(ctx context.Context, to, username, item string, approved bool, reason string) error {
+	p := message.NewPrinter(reqscope.Language(ctx))
+	var defHF = EmailHF{
+		Header: p.Sprintf("An Accessible Payment System"),
+		Footer: p.Sprintf("Never log into your account through any links provided in an email."),
+	}
+
+	type reviewMessage struct {
+		Message string
+		Reason  string
+		EmailHF
+	}
+
+	itemName := p.Sprintf("business identity")
+	if item == "displayname" {
+		itemName = p.Sprintf("display name")
+	}
+
+	msg := p.Sprintf("Hi %s, your %s has been rejected.", username, itemName)
+	if approved {
+		msg = p.Sprintf("Hi %s, your %s has been verified.", username, itemName)
+	}
+
+	b := new(bytes.Buffer)
+	err := t.ExecuteTemplate(b, "reviewdecision.html", reviewMessage{
+		Message: msg,
+		Reason: reason,
+		EmailHF: defHF,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := "Subject: " + p.Sprintf("Business Verification Result") + "\n" +
+			  "MIME-version: 1.0\n" +
+			  "Content-Type: text/html; charset=\"UTF-8\"\n\n"
+
+	return s.Send([]string{to}, []byte(header + b.String()))
+}
+
+func (s *Client) NotifyEmailChanged(ctx context.Context, to, username, newEmail, revertLink string) error {
+	p := message.NewPrinter(reqscope.Language(ctx))
+	var defHF = EmailHF{
+		Header: p.Sprintf("An Accessible Payment System"),
+		Footer: p.Sprintf("Never log into your account through any links provided in an email."),
+	}
+
+	type changedMessage struct {
+		Message string
+		Revert  string
+		Link    string
+		Action  string
+		EmailHF
+	}
+
+	b := new(bytes.Buffer)
+	err := t.ExecuteTemplate(b, "emailchanged.html", changedMessage{
+		Message: p.Sprintf("Hi %s, the email of your account has been changed to %s.", username, newEmail),
+		Revert: p.Sprintf("If you did not make this change, open the link below to undo it. All devices will be signed out."),
+		Link: revertLink,
+		Action: p.Sprintf("Undo the change"),
+		EmailHF: defHF,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := "Subject: " + p.Sprintf("Your Email Was Changed") + "\n" +
+			  "MIME-version: 1.0\n" +
+			  "Content-Type: text/html; charset=\"UTF-8\"\n\n"
+
+	return s.Send([]string{to}, []byte(header + b.String()))
+}
+
+func (s *Client) NotifyPasswordChanged(ctx context.Context, to, username string) error {
+	p := message.NewPrinter(reqscope.Language(ctx))
+	var defHF = EmailHF{
+		Header: p.Sprintf("An Accessible Payment System"),
+		Footer: p.Sprintf("Never log into your account through any links provided in an email."),
+	}
+
+	type noticeMessage struct {
+		Message string
+		Detail  string
+		EmailHF
+	}
+
+	b := new(bytes.Buffer)
+	err := t.ExecuteTemplate(b, "notice.html", noticeMessage{
+		Message: p.Sprintf("Hi %s, the password of your account has been changed.", username),
+		Detail: p.Sprintf("If you did not make this change, reset your password immediately."),
+		EmailHF: defHF,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := "Subject: " + p.Sprintf("Your Password Was Changed") + "\n" +
+			  "MIME-version: 1.0\n" +
+			  "Content-Type: text/html; charset=\"UTF-8\"\n\n"
+
+	return s.Send([]string{to}, []byte(header + b.String()))
+}
+
+func (s *Client) NotifyAccountLocked(ctx context.Context, to, username, unlockToken string) error {
+	p := message.NewPrinter(reqscope.Language(ctx))
+	var defHF = EmailHF{
+		Header: p.Sprintf("An Accessible Payment System"),
+		Footer: p.Sprintf("Never log into your account through any links provided in an email."),
+	}
+
+	type lockedMessage struct {
+		Message string
+		Token   string
+		EmailHF
+	}
+
+	b := new(bytes.Buffer)
+	err := t.ExecuteTemplate(b, "resetpassword.html", lockedMessage{
+		Message: p.Sprintf("Hi %s, your account has been locked after too many failed login attempts. If it was you, unlock it using the token below, otherwise consider changing your password.", username),
+		Token: unlockToken,
+		EmailHF: defHF,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := "Subject: " + p.Sprintf("Your Account Was Locked") + "\n" +
+			  "MIME-version: 1.0\n" +
+			  "Content-Type: text/html; charset=\"UTF-8\"\n\n"
+
+	return s.Send([]string{to}, []byte(header + b.String()))
+}
@@ -50,3 +50,27 @@ func TestClient_ResetPassword(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestClient_NotifyReviewDecision(t *testing.T) {
+	if err := testSMTP.NotifyReviewDecision(context.Background(), "yourinvitation@random.com", "the_business", "identity", false, "The document is blurry"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_NotifyEmailChanged(t *testing.T) {
+	if err := testSMTP.NotifyEmailChanged(context.Background(), "yourinvitation@random.com", "the_changer", "new@random.com", "https://api.localhost:1337/users/email/revert?token=1~REVERT"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_NotifyPasswordChanged(t *testing.T) {
+	if err := testSMTP.NotifyPasswordChanged(context.Background(), "yourinvitation@random.com", "the_changer"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_NotifyAccountLocked(t *testing.T) {
+	if err := testSMTP.NotifyAccountLocked(context.Background(), "yourinvitation@random.com", "the_locked", "1~UNLOCK TOKEN"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,34 @@
+package encryption_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/encryption"
+)
+
+func TestAESGCM(t *testing.T) {
+	c, err := encryption.NewAESGCM(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte("JBSWY3DPEHPK3PXP")
+	ciphertext, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(res, plaintext) {
+		t.Errorf("expect %s, got %s", plaintext, res)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 1
+	if _, err := c.Decrypt(ciphertext); err == nil {
+		t.Error("expect tampered ciphertext to fail")
+	}
+}
@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/hex"
 	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
 	"strings"
 	"time"
 
+	scrypt "github.com/elithrar/simple-scrypt"
 	"github.com/go-redis/redis/v8"
 	_ "github.com/go-sql-driver/mysql"
 
@@ -16,10 +24,22 @@ import (
 	"github.com/go-chi/hostrouter"
 	"github.com/stevealexrs/Go-Libra/account"
 	"github.com/stevealexrs/Go-Libra/account/accountrouter"
+	"github.com/stevealexrs/Go-Libra/blockchain"
+	"github.com/stevealexrs/Go-Libra/database/migration"
+	"github.com/stevealexrs/Go-Libra/database/object"
 	"github.com/stevealexrs/Go-Libra/database/redisdb"
+	"github.com/stevealexrs/Go-Libra/database/seaweed"
 	"github.com/stevealexrs/Go-Libra/email"
+	"github.com/stevealexrs/Go-Libra/encryption"
+	"github.com/stevealexrs/Go-Libra/mware"
 	"github.com/stevealexrs/Go-Libra/namespace/redisns"
+	"github.com/stevealexrs/Go-Libra/password"
+	"github.com/stevealexrs/Go-Libra/random"
 	"github.com/stevealexrs/Go-Libra/session"
+	"github.com/stevealexrs/Go-Libra/wallet"
+	"github.com/stevealexrs/Go-Libra/wallet/blockchain/celo"
+	"github.com/stevealexrs/Go-Libra/wallet/blockchain/diem"
+	"github.com/stevealexrs/Go-Libra/webauthn"
 )
 
 func main() {
@@ -29,9 +49,30 @@ func main() {
 	rsNodes := flag.String("rs-nodes", "", "A list of space-separated host:port addresses of redis sentinel nodes")
 	rsPassword := flag.String("rs-p", "", "Password of redis sentinel")
 	smtpPlain := flag.String("smtp", "", "A list of space-separated values that consists of email, password, hostname, and server name for plain auth")
+	seaweedURL := flag.String("seaweed", "", "URL of the seaweed master server that stores business documents")
+	rpOrigin := flag.String("rp-origin", "https://localhost:1337", "Origin of the web app that uses passkeys, the host is the relying party id")
+	apiOrigin := flag.String("api-origin", "https://api.localhost:1337", "Origin of the api, used by the links in emails")
+	deletionGrace := flag.Duration("deletion-grace", 30*24*time.Hour, "Period a deleted account can be restored before its personal data is purged")
+	passwordHash := flag.String("password-hash", "argon2id", "Algorithm of new password hashes, either argon2id or scrypt, old hashes are upgraded on login")
+	totpKey := flag.String("totp-key", "", "Hex encoded 32 bytes key that encrypts the two-factor secrets")
+	breachedPasswords := flag.String("breached-passwords", "", "Directory of the SHA-1 prefix files of breached passwords, the check is skipped if empty")
+	diemURL := flag.String("diem", "", "URL of the Diem JSON-RPC server")
+	diemChainId := flag.Uint("diem-chain-id", 1, "Chain id of the Diem network")
+	celoURL := flag.String("celo", "", "URL of the Celo node that also serves the explorer API, balances and wallet indexing are disabled unless both celo and diem are set")
+	trustedProxies := flag.String("trusted-proxies", "", "A list of space-separated CIDRs of the proxies whose X-Forwarded-For and X-Real-IP headers are trusted, the headers are ignored if empty")
+	migrate := flag.String("migrate", "", "Migrate the sql schema, \"up\" applies pending migrations before serving, \"down\" reverts the last migration and exits")
 
 	flag.Parse()
 
+	proxies := make([]*net.IPNet, 0)
+	for _, v := range strings.Fields(*trustedProxies) {
+		_, cidr, err := net.ParseCIDR(v)
+		if err != nil {
+			panic("invalid trusted-proxies flag")
+		}
+		proxies = append(proxies, cidr)
+	}
+
 	// master router
 	r := chi.NewRouter()
 	hr := hostrouter.New()
@@ -39,8 +80,10 @@ func main() {
 	// A good base middleware stack
 	r.Use(
 		middleware.RequestID,
+		// the address of the client behind the proxy is used by the rate limits and the audit log
+		mware.RealIP(proxies),
 		middleware.Logger,
-		middleware.Recoverer,
+		recoverer,
 
 		// Set a timeout value on the request context (ctx), that will signal
 		// through ctx.Done() that the request has timed out and further
@@ -59,6 +102,16 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
+
+	if *migrate != "" {
+		err = migrateSQL(sqlDB, *migrate)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *migrate == "down" {
+			return
+		}
+	}
 	
 	redisDB := redisdb.NewRedisHandlerWithClient(redisSentinelClient)
 
@@ -69,14 +122,124 @@ func main() {
 	plainAuth := email.NewSMTPService(smtpArgs[0], smtpArgs[1], smtpArgs[2], smtpArgs[3])
 
 	hr.Map("localhost:1337", defaultRouter())
-	hr.Map("api.localhost:1337", apiRouter(sqlDB, redisDB, plainAuth))
+	objStore := seaweed.New(*seaweedURL, http.DefaultClient)
+
+	key, err := hex.DecodeString(*totpKey)
+	if err != nil || len(key) != 32 {
+		panic("invalid totp-key flag")
+	}
+	totpCipher, err := encryption.NewAESGCM(key)
+	if err != nil {
+		panic(err)
+	}
+
+	origin, err := url.Parse(*rpOrigin)
+	if err != nil || origin.Hostname() == "" {
+		panic("invalid rp-origin flag")
+	}
+	relyingParty := webauthn.RelyingParty{
+		ID: origin.Hostname(),
+		Name: "Libra",
+		Origin: *rpOrigin,
+		RequireUserVerification: true,
+	}
+
+	switch *passwordHash {
+	case "argon2id":
+		random.DefaultHashRegistry = random.NewHashRegistry(random.Argon2id{Params: random.DefaultArgon2Params}, random.Scrypt{Params: scrypt.DefaultParams})
+	case "scrypt":
+		random.DefaultHashRegistry = random.NewHashRegistry(random.Scrypt{Params: scrypt.DefaultParams}, random.Argon2id{Params: random.DefaultArgon2Params})
+	default:
+		panic("invalid password-hash flag")
+	}
+
+	var breached password.BreachChecker
+	if *breachedPasswords != "" {
+		breached = &password.PrefixDataset{FS: os.DirFS(*breachedPasswords)}
+	}
+	passwordPolicy := account.DefaultPasswordPolicy(breached)
+
+	purger := account.AccountPurger{
+		DeletionRepo: &account.DeletionRepo{DB: sqlDB},
+		ObjStore: objStore,
+		GracePeriod: *deletionGrace,
+		BatchSize: 100,
+	}
+	go purger.Run(context.Background(), time.Hour)
+
+	webhooks := account.Webhooks{
+		WebhookRepo: &account.WebhookRepo{DB: sqlDB},
+		WalletRepo: &account.WalletRepo{DB: sqlDB},
+		Client: account.NewWebhookClient(10*time.Second),
+		MaxEndpoints: 10,
+		MaxAttempts: 8,
+		BaseDelay: time.Minute,
+		BatchSize: 100,
+	}
+	go webhooks.Run(context.Background(), 10*time.Second)
+
+	invoices := account.Invoices{
+		InvoiceRepo: &account.InvoiceRepo{DB: sqlDB},
+		WalletRepo: &account.WalletRepo{DB: sqlDB},
+		MaxExpiry: 30*24*time.Hour,
+		MaxLimit: 100,
+	}
+	go invoices.Run(context.Background(), time.Minute)
+
+	// balances and indexing query both chains
+	var balances *account.Balances
+	var indexer *account.Indexer
+	if *diemURL != "" && *celoURL != "" {
+		diemQuery := diem.NewQuery(byte(*diemChainId), *diemURL)
+		celoQuery, err := celo.NewQuery(*celoURL)
+		if err != nil {
+			panic("invalid celo flag")
+		}
+
+		indexer = &account.Indexer{
+			SyncRepo: &account.WalletSyncRepo{DB: sqlDB},
+			TxRepo: account.NewLocalTransactionRepo(sqlDB),
+			DiemQuery: diemQuery,
+			CeloQuery: celoQuery,
+			Notifier: account.TransactionNotifiers{&invoices, &webhooks},
+			PollInterval: time.Minute,
+			BatchSize: 100,
+			PageSize: 1000,
+		}
+		go indexer.Run(context.Background(), 10*time.Second)
+		balances = &account.Balances{
+			WalletRepo: &account.WalletRepo{DB: sqlDB},
+			DiemQuery: diemQuery,
+			CeloQuery: celoQuery,
+			CeloTokens: celoTokens,
+			Cache: redisdb.NewRedisCacheHandler(redisDB),
+			Namespace: redisns.Balance,
+			TTL: 30*time.Second,
+		}
+	}
+
+	hr.Map("api.localhost:1337", apiRouter(sqlDB, redisDB, plainAuth, objStore, totpCipher, relyingParty, *apiOrigin, *deletionGrace, passwordPolicy, webhooks, invoices, balances, indexer))
 
 	r.Mount("/", hr)
 
 	log.Fatal(http.ListenAndServe(":1337", r))
 }
 
-func apiRouter(sqlDB *sql.DB, redisDB *redisdb.Handler, mailService email.Service) chi.Router {
+// Celo mainnet tokens, the native token is also an ERC-20 token
+var celoTokens = []string{
+	"0x471ece3750da237f93b8e339c536989b8978a438",
+	"0x765de816845861e75a25fca122bb6898b8b1282a",
+	"0xd8763cba276a3738e6de85b4b3bf5fded6d6ca73",
+}
+
+// Symbols of the Celo mainnet tokens in exported statements
+var exportCurrencies = map[string]account.ExportCurrency{
+	"0x471ece3750da237f93b8e339c536989b8978a438": {Symbol: "CELO", Decimals: 18},
+	"0x765de816845861e75a25fca122bb6898b8b1282a": {Symbol: "cUSD", Decimals: 18},
+	"0xd8763cba276a3738e6de85b4b3bf5fded6d6ca73": {Symbol: "cEUR", Decimals: 18},
+}
+
+func apiRouter(sqlDB *sql.DB, redisDB *redisdb.Handler, mailService email.Service, objStore object.Store, totpCipher encryption.Cipher, relyingParty webauthn.RelyingParty, apiOrigin string, deletionGrace time.Duration, passwordPolicy account.PasswordPolicy, webhooks account.Webhooks, invoices account.Invoices, balances *account.Balances, indexer *account.Indexer) chi.Router {
 	r := chi.NewRouter()
 
 	userRepo := account.UserRepo{
@@ -85,43 +248,204 @@ func apiRouter(sqlDB *sql.DB, redisDB *redisdb.Handler, mailService email.Servic
 
 	businessRepo := account.BusinessRepo{
 		DB: sqlDB,
+		ObjStore: objStore,
+	}
+
+	reviewRepo := account.ReviewRepo{
+		DB: sqlDB,
+	}
+
+	childRepo := account.BusinessChildRepo{
+		DB: sqlDB,
+	}
+
+	walletRepo := account.WalletRepo{
+		DB: sqlDB,
+	}
+
+	deletion := account.AccountDeletion{
+		DeletionRepo: &account.DeletionRepo{DB: sqlDB},
+		GracePeriod: deletionGrace,
 	}
 
 	emailClient := email.Client{
 		Service: mailService,
 	}
 
+	throttle := account.LoginThrottle{
+		AttemptRepo: account.NewLoginAttemptRepo(redisDB, redisns.LoginAttempt),
+		Ext: &emailClient,
+		FreeAttempts: 3,
+		BaseDelay: 30*time.Second,
+		LockAttempts: 10,
+		LockDuration: time.Hour,
+		Window: 24*time.Hour,
+	}
+
+	userEmailChange := account.EmailChange{
+		RevertRepo: account.NewEmailRevertRepo(redisDB, redisns.EmailRevert),
+		RevertURL: apiOrigin + "/users/email/revert",
+		Ext: &emailClient,
+	}
+	businessEmailChange := userEmailChange
+	businessEmailChange.RevertURL = apiOrigin + "/businesses/email/revert"
+
 	accRouter := accountrouter.New(
 		account.UserCreator{
 			UserRepo:       &userRepo,
 			InvitationRepo: account.NewInvitationEmailVerificationRepo(redisDB, redisns.UserInvEmailVer),
 			EmailRepo:      account.NewRecoveryEmailVerificationRepo(redisDB, redisns.UserRecEmailVer),
+			Deletion:       deletion,
+			EmailChange:    userEmailChange,
+			Throttle:       throttle,
+			Policy:         passwordPolicy,
+			UsernamePolicy: account.DefaultUsernamePolicy(),
+			Invitations:    account.Invitations{
+				InvitationRepo: &account.InvitationRepo{DB: sqlDB},
+				DefaultQuota: 5,
+				ResendCooldown: 10*time.Minute,
+			},
 			Ext:            &emailClient,
 		},
-		session.NewDefSharedProvider(redisDB, ""),
+		session.NewDefSharedProvider(redisDB, redisns.UserSession),
 		account.UserAccountRecoveryHelper{
 			UserRepo: &userRepo,
 			RecoveryRepo: account.NewAccountRecoveryRepo(redisDB, redisns.UserAccReset),
+			Policy: passwordPolicy,
 			Ext: &emailClient,
 		},
 		account.BusinessCreator{
 			BusinessRepo: &businessRepo,
 			EmailRepo: account.NewRecoveryEmailVerificationRepo(redisDB, redisns.BusinessRecEmailVer),
+			ReviewRepo: &reviewRepo,
+			ChildRepo: &childRepo,
+			Deletion: deletion,
+			EmailChange: businessEmailChange,
+			Throttle: throttle,
+			Policy: passwordPolicy,
+			UsernamePolicy: account.DefaultUsernamePolicy(),
 			Ext: &emailClient,
 		},
-		session.NewDefSharedProvider(redisDB, ""),
+		session.NewDefSharedProvider(redisDB, redisns.BusinessSession),
 		account.BusinessAccountRecoveryHelper{
 			BusinessRepo: &businessRepo,
 			RecoveryRepo: account.NewAccountRecoveryRepo(redisDB, redisns.BusinessAccReset),
+			Policy: passwordPolicy,
 			Ext: &emailClient,
 		},
-	)
+	).WithWallets(account.WalletLinker{
+		WalletRepo: &walletRepo,
+		ChallengeRepo: account.NewWalletChallengeRepo(redisDB, redisns.WalletChallenge),
+		Verifiers: map[string]wallet.OwnershipVerifier{
+			blockchain.DiemChain: diem.OwnershipVerifier{},
+			blockchain.CeloChain: celo.OwnershipVerifier{},
+		},
+	}).WithBusinessReview(&account.StaffRepo{DB: sqlDB}, account.BusinessReviewer{
+		BusinessRepo: &businessRepo,
+		ReviewRepo: &reviewRepo,
+		ObjStore: objStore,
+		Ext: &emailClient,
+	}).WithBusinessHierarchy(account.BusinessHierarchy{
+		BusinessRepo: &businessRepo,
+		ChildRepo: &childRepo,
+		WalletRepo: &walletRepo,
+		TxRepo: account.NewLocalTransactionRepo(sqlDB),
+	}).WithTwoFactor(account.TwoFactor{
+		TOTPRepo: &account.TOTPRepo{DB: sqlDB},
+		Cipher: totpCipher,
+		Issuer: "Libra",
+		Throttle: throttle,
+	}, session.NewDefUniqueProvider(redisDB, redisns.PendingLogin)).WithPasskeys(account.PasskeyAuthenticator{
+		PasskeyRepo: &account.PasskeyRepo{DB: sqlDB},
+		ChallengeRepo: account.NewPasskeyChallengeRepo(redisDB, redisns.PasskeyChallenge),
+		RelyingParty: relyingParty,
+	}).WithAPIKeys(account.APIKeys{
+		APIKeyRepo: &account.APIKeyRepo{DB: sqlDB},
+		MaxKeys: 20,
+	}).WithWebhooks(webhooks).WithInvoices(invoices).WithRefunds(account.Refunds{
+		RefundRepo: &account.RefundRepo{DB: sqlDB},
+		WalletRepo: &walletRepo,
+	}).WithTransactionHistory(account.TransactionHistory{
+		HistoryRepo: account.NewLocalTransactionRepo(sqlDB),
+		MaxLimit: 100,
+	}).WithTransactionExport(account.TransactionExport{
+		HistoryRepo: account.NewLocalTransactionRepo(sqlDB),
+		WalletRepo: &walletRepo,
+		Currencies: exportCurrencies,
+		BatchSize: 500,
+		MaxRange: 366 * 24 * time.Hour,
+	}).WithAudit(account.AuditLog{
+		AuditRepo: &account.AuditRepo{DB: sqlDB},
+		MaxLimit: 100,
+	})
+	if balances != nil {
+		accRouter.WithBalances(*balances)
+	}
+	if indexer != nil {
+		accRouter.WithIndexer(*indexer)
+	}
 
 	r.Mount("/users", accRouter.UserHandler())
 	r.Mount("/businesses", accRouter.BusinessHandler())
+	r.Mount("/staff", accRouter.StaffHandler())
 	return r
 }
 
+func migrateSQL(sqlDB *sql.DB, direction string) error {
+	migrator, err := migration.New(sqlDB)
+	if err != nil {
+		return err
+	}
+	migrator.RegisterBackfill(7, account.BackfillCanonicalUsername)
+	migrator.RegisterBackfill(16, account.BackfillCanonicalUsername)
+
+	ctx := context.Background()
+	switch direction {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx, 1)
+	default:
+		return fmt.Errorf("unknown migration direction %s", direction)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("sql schema is at version %d of %d", version, migrator.Latest())
+	return nil
+}
+
+// Same as middleware.Recoverer except http.ErrAbortHandler is passed on to the server, which then aborts the
+// response instead of ending it as if it was complete
+func recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				panic(rvr)
+			}
+
+			logEntry := middleware.GetLogEntry(r)
+			if logEntry != nil {
+				logEntry.Panic(rvr, debug.Stack())
+			} else {
+				middleware.PrintPrettyStack(rvr)
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func defaultRouter() chi.Router {
 	r := chi.NewRouter()
 
@@ -0,0 +1,50 @@
+package mware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, v := range nets {
+		if v.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client address forwarded by the proxies, the nearest address that is not a trusted proxy
+func forwardedIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !containsIP(trusted, ip) {
+			return ip
+		}
+	}
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+// Replace the remote address with the client address when the request comes from a trusted proxy.
+// The forwarding headers are always removed so later handlers never see an address picked by the client
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, port, err := net.SplitHostPort(r.RemoteAddr)
+			if err == nil && containsIP(trusted, net.ParseIP(host)) {
+				if ip := forwardedIP(r, trusted); ip != nil {
+					r.RemoteAddr = net.JoinHostPort(ip.String(), port)
+				}
+			}
+
+			r.Header.Del("X-Forwarded-For")
+			r.Header.Del("X-Real-IP")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
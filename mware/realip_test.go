@@ -0,0 +1,51 @@
+package mware_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/mware"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{"10.0.0.1:1000", "203.0.113.9", "203.0.113.9:1000"},
+		// the client can prepend any address, only the hop added by the proxy counts
+		{"10.0.0.1:1000", "198.51.100.1, 203.0.113.9, 10.0.0.2", "203.0.113.9:1000"},
+		{"203.0.113.9:1000", "198.51.100.1", "203.0.113.9:1000"},
+		{"10.0.0.1:1000", "", "10.0.0.1:1000"},
+	}
+
+	for _, v := range tests {
+		var remote, header string
+		handler := mware.RealIP([]*net.IPNet{proxies})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			remote = r.RemoteAddr
+			header = r.Header.Get("X-Forwarded-For")
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = v.remote
+		if v.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", v.forwarded)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if remote != v.want {
+			t.Errorf("%s forwarding %q: expect %s, got %s", v.remote, v.forwarded, v.want, remote)
+		}
+		if header != "" {
+			t.Errorf("forwarding header is passed on: %s", header)
+		}
+	}
+}
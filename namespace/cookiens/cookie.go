@@ -4,5 +4,7 @@ const (
 	Language = "lang"
 	UserSession = "userses"
 	BusinessSession = "businessses"
+	UserPendingLogin = "userpending"
+	BusinessPendingLogin = "businesspending"
 )
 
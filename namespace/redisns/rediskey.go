@@ -7,5 +7,13 @@ const (
 	UserAccReset	 	 = "useraccreset"
 	BusinessAccReset 	 = "businessaccreset"
 	AccSharedSession 	 = "accsharedsession"
+	WalletChallenge		 = "walletchallenge"
+	PendingLogin		 = "pendinglogin"
+	PasskeyChallenge	 = "passkeychallenge"
+	EmailRevert			 = "emailrevert"
+	LoginAttempt		 = "loginattempt"
+	Balance				 = "balance"
+	UserSession			 = "usersession"
+	BusinessSession		 = "businesssession"
 
 )
\ No newline at end of file
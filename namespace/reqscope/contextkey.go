@@ -10,6 +10,7 @@ type contextKey int
 
 const (
 	keyLanguage contextKey = iota
+	keyAccountId
 )
 
 func Language(ctx context.Context) language.Tag {
@@ -22,3 +23,13 @@ func Language(ctx context.Context) language.Tag {
 func SetLanguage(ctx context.Context, lang language.Tag) context.Context {
 	return context.WithValue(ctx, keyLanguage, lang)
 }
+
+// Id of the account that is authenticated for the request
+func AccountId(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(keyAccountId).(int)
+	return id, ok
+}
+
+func SetAccountId(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, keyAccountId, id)
+}
@@ -0,0 +1,58 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+type BreachChecker interface {
+	Breached(password string) (bool, error)
+}
+
+// Breached passwords split by the first 5 hex characters of their SHA-1 hash,
+// each file is named by the uppercase prefix and has a SUFFIX:COUNT line per hash,
+// the same format as the range files of Have I Been Pwned
+type PrefixDataset struct {
+	FS fs.FS
+	// Hashes seen fewer times are ignored
+	MinCount int
+}
+
+func (d *PrefixDataset) Breached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := d.FS.Open(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		parts := strings.SplitN(line, ":", 2)
+		if !strings.EqualFold(parts[0], suffix) {
+			continue
+		}
+
+		if len(parts) == 1 || d.MinCount <= 1 {
+			return true, nil
+		}
+
+		count, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return false, err
+		}
+		return count >= d.MinCount, nil
+	}
+	return false, scanner.Err()
+}
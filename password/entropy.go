@@ -0,0 +1,161 @@
+// Package password estimates the strength of passwords and checks them
+// against breached password datasets without network access
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Passwords and words that are guessed first, ordered by popularity
+var commonWords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "admin", "iloveyou",
+	"monkey", "dragon", "football", "baseball", "master", "sunshine", "princess",
+	"shadow", "superman", "trustno1", "login", "starwars", "passw0rd", "hello",
+	"freedom", "whatever", "charlie", "secret", "summer", "winter", "spring",
+	"autumn", "love", "money", "bitcoin", "wallet", "libra", "diem", "celo",
+}
+
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+}
+
+var leet = strings.NewReplacer("0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// Size of the character set used by the password
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, v := range password {
+		switch {
+		case v >= 'a' && v <= 'z':
+			lower = true
+		case v >= 'A' && v <= 'Z':
+			upper = true
+		case v >= '0' && v <= '9':
+			digit = true
+		case v < unicode.MaxASCII && unicode.IsPrint(v):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+	return pool
+}
+
+// Rank of the longest common word at the start of s, 0 if none
+func matchWord(s []rune) (length int, rank int) {
+	raw := string(s)
+	normalized := leet.Replace(raw)
+	for i, v := range commonWords {
+		if len(v) > length && (strings.HasPrefix(raw, v) || strings.HasPrefix(normalized, v)) {
+			length, rank = len([]rune(v)), i+1
+		}
+	}
+	return length, rank
+}
+
+// Length of the same character repeated at the start of s
+func matchRepeat(s []rune) int {
+	n := 1
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	return n
+}
+
+// Length of the ascending or descending run at the start of s, e.g. abc, 321
+func matchSequence(s []rune) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+
+	step := s[1] - s[0]
+	if step != 1 && step != -1 {
+		return 1
+	}
+
+	n := 2
+	for n < len(s) && s[n]-s[n-1] == step {
+		n++
+	}
+	return n
+}
+
+// Length of adjacent keys in a keyboard row at the start of s
+func matchKeyboard(s []rune) int {
+	best := 1
+	for _, row := range keyboardRows {
+		r := []rune(row)
+		for i := range r {
+			n := 0
+			for n < len(s) && i+n < len(r) && s[n] == r[i+n] {
+				n++
+			}
+			if n > best {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+// Estimate the entropy in bits, common words, repeats, sequences and
+// keyboard patterns are counted as a single guess instead of random characters
+func Entropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+
+	charBits := math.Log2(float64(poolSize(password)))
+	s := []rune(strings.ToLower(password))
+
+	var bits float64
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		if n, rank := matchWord(rest); n >= 3 {
+			bits += math.Log2(float64(rank) + 1)
+			i += n
+			continue
+		}
+
+		n := matchRepeat(rest)
+		if m := matchSequence(rest); m > n {
+			n = m
+		}
+		if m := matchKeyboard(rest); m > n {
+			n = m
+		}
+
+		if n >= 3 {
+			bits += charBits + math.Log2(float64(n))
+			i += n
+			continue
+		}
+
+		bits += charBits
+		i++
+	}
+	return bits
+}
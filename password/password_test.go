@@ -0,0 +1,58 @@
+package password_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stevealexrs/Go-Libra/password"
+)
+
+func TestEntropy(t *testing.T) {
+	weak := []string{"", "password", "P@ssw0rd", "aaaaaaaaaaaa", "abcdefghijkl", "qwertyuiop", "123456789"}
+	for _, v := range weak {
+		if bits := password.Entropy(v); bits >= 40 {
+			t.Errorf("expect %q to be weak, got %v bits", v, bits)
+		}
+	}
+
+	strong := []string{"correct horse battery staple", "x7#Kp2!vQm9z", "Tr0ub4dor&3-marble-cactus"}
+	for _, v := range strong {
+		if bits := password.Entropy(v); bits < 40 {
+			t.Errorf("expect %q to be strong, got %v bits", v, bits)
+		}
+	}
+}
+
+func TestPrefixDataset(t *testing.T) {
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	dataset := &password.PrefixDataset{
+		FS: fstest.MapFS{
+			"5BAA6": {Data: []byte("003D68EB55068C33ACE09247EE4C639306B:3\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n")},
+		},
+	}
+
+	breached, err := dataset.Breached("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !breached {
+		t.Error("expect password to be breached")
+	}
+
+	breached, err = dataset.Breached("not in the dataset")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breached {
+		t.Error("expect a password without prefix file to be safe")
+	}
+
+	dataset.MinCount = 5000000
+	breached, err = dataset.Breached("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if breached {
+		t.Error("expect a hash below the minimum count to be ignored")
+	}
+}
@@ -1,11 +1,217 @@
 package random
 
-import scrypt "github.com/elithrar/simple-scrypt"
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	scrypt "github.com/elithrar/simple-scrypt"
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrMismatchedHashAndPassword = errors.New("the hashed password does not match the given password")
+var ErrUnknownHash = errors.New("the hash algorithm is not registered")
+
+// Hashes are prefixed by $<id>$ and keep the parameters used to generate them
+type Hasher interface {
+	ID() string
+	Hash(password string) ([]byte, error)
+	Compare(hash []byte, password string) error
+	// The hash was generated with different parameters
+	Outdated(hash []byte) bool
+}
+
+// Generate hashes with the default hasher and compare them with the hasher in the prefix
+type HashRegistry struct {
+	Default Hasher
+	hashers map[string]Hasher
+}
+
+func NewHashRegistry(def Hasher, others ...Hasher) *HashRegistry {
+	r := &HashRegistry{Default: def, hashers: make(map[string]Hasher)}
+	for _, v := range append(others, def) {
+		r.hashers[v.ID()] = v
+	}
+	return r
+}
+
+// Hashes created before the registry have no prefix and are scrypt
+func (r *HashRegistry) hasher(hash []byte) (Hasher, error) {
+	id := Scrypt{}.ID()
+	if bytes.HasPrefix(hash, []byte("$")) {
+		parts := bytes.SplitN(hash[1:], []byte("$"), 2)
+		id = string(parts[0])
+	}
+
+	h, ok := r.hashers[id]
+	if !ok {
+		return nil, ErrUnknownHash
+	}
+	return h, nil
+}
+
+func (r *HashRegistry) Generate(password string) ([]byte, error) {
+	return r.Default.Hash(password)
+}
+
+func (r *HashRegistry) Compare(hash []byte, password string) error {
+	h, err := r.hasher(hash)
+	if err != nil {
+		return err
+	}
+	return h.Compare(hash, password)
+}
+
+// The hash should be regenerated with the default hasher
+func (r *HashRegistry) NeedsRehash(hash []byte) bool {
+	h, err := r.hasher(hash)
+	if err != nil || h.ID() != r.Default.ID() {
+		return true
+	}
+	return h.Outdated(hash)
+}
+
+// Replace it to change the algorithm or cost of new hashes
+var DefaultHashRegistry = NewHashRegistry(Scrypt{Params: scrypt.DefaultParams}, Argon2id{Params: DefaultArgon2Params})
 
 func GenerateHash(password string) ([]byte, error) {
-	return scrypt.GenerateFromPassword([]byte(password), scrypt.DefaultParams)
+	return DefaultHashRegistry.Generate(password)
 }
 
 func CompareHashAndPassword(hash []byte, password string) error {
-	return scrypt.CompareHashAndPassword(hash, []byte(password))
-}
\ No newline at end of file
+	return DefaultHashRegistry.Compare(hash, password)
+}
+
+func NeedsRehash(hash []byte) bool {
+	return DefaultHashRegistry.NeedsRehash(hash)
+}
+
+type Scrypt struct {
+	Params scrypt.Params
+}
+
+func (s Scrypt) ID() string {
+	return "scrypt"
+}
+
+func (s Scrypt) prefix() []byte {
+	return []byte("$" + s.ID() + "$")
+}
+
+func (s Scrypt) Hash(password string) ([]byte, error) {
+	hash, err := scrypt.GenerateFromPassword([]byte(password), s.Params)
+	if err != nil {
+		return nil, err
+	}
+	return append(s.prefix(), hash...), nil
+}
+
+func (s Scrypt) Compare(hash []byte, password string) error {
+	err := scrypt.CompareHashAndPassword(bytes.TrimPrefix(hash, s.prefix()), []byte(password))
+	if errors.Is(err, scrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatchedHashAndPassword
+	}
+	return err
+}
+
+func (s Scrypt) Outdated(hash []byte) bool {
+	params, err := scrypt.Cost(bytes.TrimPrefix(hash, s.prefix()))
+	return err != nil || params != s.Params
+}
+
+type Argon2Params struct {
+	Time    uint32
+	// KiB
+	Memory  uint32
+	Threads uint8
+	SaltLen int
+	KeyLen  uint32
+}
+
+// Recommended by RFC 9106 for memory constrained environments
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4, SaltLen: 16, KeyLen: 32}
+
+// Hashes are in the PHC string format, e.g. $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>
+type Argon2id struct {
+	Params Argon2Params
+}
+
+func (a Argon2id) ID() string {
+	return "argon2id"
+}
+
+func (a Argon2id) Hash(password string) ([]byte, error) {
+	salt, err := scrypt.GenerateRandomBytes(a.Params.SaltLen)
+	if err != nil {
+		return nil, err
+	}
+
+	p := a.Params
+	key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+	hash := fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		a.ID(),
+		argon2.Version,
+		p.Memory,
+		p.Time,
+		p.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+	return []byte(hash), nil
+}
+
+func (a Argon2id) decode(hash []byte) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 6 || parts[1] != a.ID() {
+		return Argon2Params{}, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	var p Argon2Params
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads)
+	if err != nil {
+		return Argon2Params{}, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, err
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, err
+	}
+
+	p.SaltLen = len(salt)
+	p.KeyLen = uint32(len(key))
+	return p, salt, key, nil
+}
+
+func (a Argon2id) Compare(hash []byte, password string) error {
+	p, salt, key, err := a.decode(hash)
+	if err != nil {
+		return err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+func (a Argon2id) Outdated(hash []byte) bool {
+	p, _, _, err := a.decode(hash)
+	return err != nil || p != a.Params
+}
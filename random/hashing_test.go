@@ -1,8 +1,10 @@
 package random_test
 
 import (
+	"errors"
 	"testing"
 
+	scrypt "github.com/elithrar/simple-scrypt"
 	"github.com/stevealexrs/Go-Libra/random"
 )
 
@@ -12,4 +14,53 @@ func TestHash(t *testing.T) {
 		t.Error(err)
 	}
 	t.Log(hash)
-}
\ No newline at end of file
+}
+
+var testArgon2 = random.Argon2id{Params: random.Argon2Params{Time: 1, Memory: 1024, Threads: 1, SaltLen: 16, KeyLen: 32}}
+
+func TestHashRegistry(t *testing.T) {
+	registry := random.NewHashRegistry(testArgon2, random.Scrypt{Params: scrypt.DefaultParams})
+
+	hash, err := registry.Generate("Password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.Compare(hash, "Password"); err != nil {
+		t.Errorf("expect password to match, got %v", err)
+	}
+
+	if err := registry.Compare(hash, "password"); !errors.Is(err, random.ErrMismatchedHashAndPassword) {
+		t.Errorf("expect mismatched password, got %v", err)
+	}
+
+	if registry.NeedsRehash(hash) {
+		t.Error("expect hash with the default parameters to be current")
+	}
+
+	stronger := testArgon2
+	stronger.Params.Time = 2
+	if !random.NewHashRegistry(stronger).NeedsRehash(hash) {
+		t.Error("expect hash with old parameters to need rehash")
+	}
+}
+
+func TestHashRegistry_LegacyScrypt(t *testing.T) {
+	legacy, err := scrypt.GenerateFromPassword([]byte("Password"), scrypt.DefaultParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registry := random.NewHashRegistry(testArgon2, random.Scrypt{Params: scrypt.DefaultParams})
+	if err := registry.Compare(legacy, "Password"); err != nil {
+		t.Errorf("expect legacy scrypt hash to match, got %v", err)
+	}
+
+	if !registry.NeedsRehash(legacy) {
+		t.Error("expect scrypt hash to need rehash when argon2id is the default")
+	}
+
+	if random.NewHashRegistry(random.Scrypt{Params: scrypt.DefaultParams}).NeedsRehash(legacy) {
+		t.Error("expect legacy scrypt hash with the default parameters to be current")
+	}
+}
@@ -177,6 +177,16 @@ func (sp *DefSharedProvider) DestroyOther(ctx context.Context, ssid string) erro
 	return sp.deleteSession(ctx, session.Id, tokens...)
 }
 
+func (sp *DefSharedProvider) DestroyAll(ctx context.Context, id int) error {
+	err := sp.deleteStore(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = sp.session.Delete(ctx, sp.idToTokenKey(id))
+	return err
+}
+
 // Objects are stored as hash in redis
 func (sp *DefSharedProvider) Get(ctx context.Context, id, key string) (string, error) {
 	idString, err := strconv.Atoi(id)
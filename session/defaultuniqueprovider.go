@@ -2,6 +2,7 @@ package session
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -62,14 +63,14 @@ func (sp *DefUniqueProvider) Read(ctx context.Context, ssid string, expiration t
 	}
 
 	if res != hashSetKey {
-		return nil, err
+		return nil, errors.New("session does not exist")
 	}
 	err = sp.refreshExpiration(ctx, sess.Token, expiration)
 	return sess, err
 }
 
 func (sp *DefUniqueProvider) Destroy(ctx context.Context, ssid string) error {
-	_, err := sp.Read(ctx, ssid, -1)
+	_, err := sp.store.Delete(ctx, sp.makeKey(ssid))
 	return err
 }
 
@@ -15,6 +15,8 @@ type SharedProvider interface {
 	FetchAll(ctx context.Context, ssid string) ([]Shared, error)
 	Destroy(ctx context.Context, ssid string) error
 	DestroyOther(ctx context.Context, ssid string) error
+	// Remove every session of the id without a session id, e.g. when the account is disabled
+	DestroyAll(ctx context.Context, id int) error
 	Storage
 }
 
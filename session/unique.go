@@ -1,11 +1,14 @@
 package session
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type UniqueProvider interface {
-	Init(expiration time.Duration) (*Unique, error)
-	Read(string, time.Duration) (*Unique, error)
-	Destroy(string) error
+	Init(ctx context.Context, expiration time.Duration) (*Unique, error)
+	Read(ctx context.Context, ssid string, expiration time.Duration) (*Unique, error)
+	Destroy(ctx context.Context, ssid string) error
 	Storage
 }
 
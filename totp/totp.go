@@ -0,0 +1,111 @@
+// Time-based one-time password from RFC 6238 with the defaults of authenticator apps,
+// HMAC-SHA1, 6 digits and 30 seconds period
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30
+	// Accept the codes of previous and next period for clock drift
+	Skew = 1
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Base32 encoded secret without padding
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	return encoding.DecodeString(secret)
+}
+
+// Step of the time, a code is valid for one step
+func Step(t time.Time) uint64 {
+	return uint64(t.Unix()) / Period
+}
+
+// HOTP value of the step from RFC 4226
+func CodeAt(secret string, step uint64) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, step)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+func Code(secret string, t time.Time) (string, error) {
+	return CodeAt(secret, Step(t))
+}
+
+// Returns the step that matches the code, ok is false if nothing matches
+func Validate(secret, code string, t time.Time) (step uint64, ok bool, err error) {
+	if len(code) != Digits {
+		return 0, false, nil
+	}
+
+	current := Step(t)
+	for i := -Skew; i <= Skew; i++ {
+		s := uint64(int64(current) + int64(i))
+
+		expected, err := CodeAt(secret, s)
+		if err != nil {
+			return 0, false, err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return s, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Key URI understood by authenticator apps, usually shown as QR code
+func ProvisioningURI(issuer, accountName, secret string) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(Period))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
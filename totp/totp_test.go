@@ -0,0 +1,69 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevealexrs/Go-Libra/totp"
+)
+
+// Test vectors of RFC 6238 for SHA1, truncated to 6 digits
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, v := range tests {
+		code, err := totp.Code(secret, time.Unix(v.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.want {
+			t.Errorf("at %v expect %v, got %v", v.unix, v.want, code)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code, err := totp.Code(secret, now.Add(-totp.Period*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok, err := totp.Validate(secret, code, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || step != totp.Step(now)-1 {
+		t.Errorf("expect code of previous step to be valid")
+	}
+
+	_, ok, _ = totp.Validate(secret, code, now.Add(5*totp.Period*time.Second))
+	if ok {
+		t.Errorf("expect old code to be invalid")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := totp.ProvisioningURI("Libra", "jane doe", "JBSWY3DPEHPK3PXP")
+	if !strings.HasPrefix(uri, "otpauth://totp/Libra:jane%20doe?") || !strings.Contains(uri, "secret=JBSWY3DPEHPK3PXP") {
+		t.Errorf("unexpected uri %v", uri)
+	}
+}
@@ -0,0 +1,65 @@
+package celo
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/common"
+	"github.com/celo-org/celo-blockchain/common/hexutil"
+	"github.com/celo-org/celo-blockchain/crypto"
+)
+
+var ErrInvalidAddress = errors.New("invalid celo address")
+var ErrInvalidSignature = errors.New("invalid personal_sign signature")
+var ErrAddressMismatch = errors.New("signature is not signed by the address")
+
+// Verify ownership with a secp256k1 signature created by personal_sign
+type OwnershipVerifier struct{}
+
+func (OwnershipVerifier) NormalizeAddress(address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", ErrInvalidAddress
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex()), nil
+}
+
+// The public key is recovered from the signature, it is only compared when given
+func (v OwnershipVerifier) VerifyOwnership(address, publicKey string, message, signature []byte) error {
+	normalized, err := v.NormalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != crypto.SignatureLength {
+		return ErrInvalidSignature
+	}
+
+	// personal_sign returns the recovery id as 27 or 28
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash(message), sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if publicKey != "" {
+		given, err := hexutil.Decode(publicKey)
+		if err != nil {
+			return err
+		}
+		if hexutil.Encode(crypto.FromECDSAPub(pub)) != hexutil.Encode(given) &&
+			hexutil.Encode(crypto.CompressPubkey(pub)) != hexutil.Encode(given) {
+			return ErrAddressMismatch
+		}
+	}
+
+	if strings.ToLower(crypto.PubkeyToAddress(*pub).Hex()) != normalized {
+		return ErrAddressMismatch
+	}
+	return nil
+}
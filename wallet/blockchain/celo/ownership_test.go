@@ -0,0 +1,42 @@
+package celo_test
+
+import (
+	"testing"
+
+	"github.com/celo-org/celo-blockchain/accounts"
+	"github.com/celo-org/celo-blockchain/crypto"
+	"github.com/stevealexrs/Go-Libra/wallet/blockchain/celo"
+)
+
+func personalSign(t *testing.T, message []byte) (string, []byte) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := crypto.Sign(accounts.TextHash(message), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return crypto.PubkeyToAddress(key.PublicKey).Hex(), sig
+}
+
+func TestOwnershipVerifier(t *testing.T) {
+	message := []byte("challenge")
+	address, sig := personalSign(t, message)
+
+	verifier := celo.OwnershipVerifier{}
+	if err := verifier.VerifyOwnership(address, "", message, sig); err != nil {
+		t.Error(err)
+	}
+
+	if err := verifier.VerifyOwnership(address, "", []byte("other"), sig); err == nil {
+		t.Error("expect error for a different message")
+	}
+
+	other, _ := personalSign(t, message)
+	if err := verifier.VerifyOwnership(other, "", message, sig); err != celo.ErrAddressMismatch {
+		t.Errorf("expect address mismatch, got %v", err)
+	}
+}
@@ -2,9 +2,13 @@ package celo
 
 import (
 	"context"
+	"encoding/hex"
 	"math/big"
+	"strings"
 	"sync"
 
+	"github.com/celo-org/celo-blockchain/accounts/abi"
+	"github.com/celo-org/celo-blockchain/crypto"
 	"github.com/celo-org/celo-blockchain/ethclient"
 	"github.com/celo-org/celo-blockchain/rpc"
 	"github.com/stevealexrs/Go-Libra/blockchain"
@@ -84,7 +88,74 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 	if err != nil {
 		return nil, err
 	}
-	
+	return q.transactions(ctx, txs)
+}
+
+func (q *Query) TransactionsByBlock(ctx context.Context, address string, start, end uint64, limit int) (map[uint64]map[int]wallet.CeloTransaction, uint64, error) {
+	asc := celoexplorer.SortDirection.Asc
+	blocks := &celoexplorer.BlockRange{StartBlock: new(big.Int).SetUint64(start), EndBlock: new(big.Int).SetUint64(end)}
+	txs, err := q.Explorer.TokenTx(address, nil, &asc, blocks, &celoexplorer.PageRange{Page: 1, Offset: limit})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	last := end
+	if len(txs) >= limit {
+		// the transfers of the last block on a full page might continue on the next page
+		last = txs[len(txs)-1].BlockNumber.Uint64()
+		if txs[0].BlockNumber.Uint64() == last {
+			// a block that fills the page by itself is read whole
+			blocks = &celoexplorer.BlockRange{StartBlock: new(big.Int).SetUint64(last), EndBlock: new(big.Int).SetUint64(last)}
+			txs, err = q.Explorer.TokenTx(address, nil, &asc, blocks, nil)
+			if err != nil {
+				return nil, 0, err
+			}
+		} else {
+			last--
+			for txs[len(txs)-1].BlockNumber.Uint64() > last {
+				txs = txs[:len(txs)-1]
+			}
+		}
+	}
+
+	txMap, err := q.transactions(ctx, txs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return txMap, last, nil
+}
+
+// Stable tokens emit TransferComment(string) right after the Transfer event of transferWithComment
+var transferCommentTopic = hex.EncodeToString(crypto.Keccak256([]byte("TransferComment(string)")))
+
+// Comments of the transaction keyed by the log index of the transfer they belong to
+func transferComments(logs []celoexplorer.TxLog) map[int]string {
+	stringType, _ := abi.NewType("string", "", nil)
+	args := abi.Arguments{{Type: stringType}}
+
+	comments := make(map[int]string)
+	for _, v := range logs {
+		if len(v.Topics) == 0 || !strings.EqualFold(strings.TrimPrefix(v.Topics[0], "0x"), transferCommentTopic) {
+			continue
+		}
+
+		data, err := hex.DecodeString(strings.TrimPrefix(string(v.Data), "0x"))
+		if err != nil {
+			continue
+		}
+		values, err := args.UnpackValues(data)
+		if err != nil || len(values) != 1 {
+			continue
+		}
+		if comment, ok := values[0].(string); ok {
+			comments[v.Index-1] = comment
+		}
+	}
+	return comments
+}
+
+// Group the token transfers by block and transaction, the details of every transaction are fetched at the same time
+func (q *Query) transactions(ctx context.Context, txs []celoexplorer.TokenTransfer) (map[uint64]map[int]wallet.CeloTransaction, error) {
 	// ensure transaction hash is not duplicated
 	hashListMap := make(map[string]struct{})
 	for _, v := range txs {
@@ -96,6 +167,7 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 		gatewayFee		 *big.Int
 		gatewayRecipient string
 		gatewayCurrency  string
+		comments		 map[int]string
 	}
 
 	lock := sync.Mutex{}
@@ -116,6 +188,7 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 				gatewayFee: txLog.GatewayFee,
 				gatewayRecipient: txLog.GatewayFeeRecipient,
 				gatewayCurrency: txLog.Feecurrency,
+				comments: transferComments(txLog.Logs),
 			}
 			return nil
 		})
@@ -131,7 +204,13 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 			v.LogIndex = 0
 		}
 
+		if _, ok := txMap[v.BlockNumber.Uint64()]; !ok {
+			txMap[v.BlockNumber.Uint64()] = make(map[int]wallet.CeloTransaction)
+		}
 		tEvent := txMap[v.BlockNumber.Uint64()][v.TransactionIndex].TransferEvents
+		if tEvent == nil {
+			tEvent = make(map[int]wallet.Transfer)
+		}
 		tEvent[v.LogIndex] = wallet.Transfer{
 			Currency: v.ContractAddress,
 			From: v.From,
@@ -157,6 +236,7 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 			GatewayRecipient: txHashMap[v.Hash].gatewayRecipient,
 			GatewayCurrency: txHashMap[v.Hash].gatewayCurrency,
 			TransferEvents: tEvent,
+			TransferComments: txHashMap[v.Hash].comments,
 		}
 	}
 	return txMap, nil
@@ -164,3 +244,11 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 
 
 
+
+func (q *Query) LatestBlock(ctx context.Context) (uint64, error) {
+	header, err := q.Eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	return header.Number.Uint64(), nil
+}
@@ -0,0 +1,51 @@
+package diem
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/diem/client-sdk-go/diemtypes"
+)
+
+var ErrInvalidSignature = errors.New("invalid ed25519 signature")
+var ErrAddressMismatch = errors.New("public key does not derive the address")
+
+// Verify ownership with the Ed25519 key that derives the account authentication key
+type OwnershipVerifier struct{}
+
+func (OwnershipVerifier) NormalizeAddress(address string) (string, error) {
+	addr, err := diemtypes.MakeAccountAddress(strings.TrimPrefix(strings.ToLower(address), "0x"))
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(addr[:]), nil
+}
+
+// Only single signature account is supported
+func (v OwnershipVerifier) VerifyOwnership(address, publicKey string, message, signature []byte) error {
+	normalized, err := v.NormalizeAddress(address)
+	if err != nil {
+		return err
+	}
+
+	key, err := diemkeys.NewEd25519PublicKeyFromString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return err
+	}
+	if len(key.Bytes()) != ed25519.PublicKeySize {
+		return ErrInvalidSignature
+	}
+
+	derived := diemkeys.NewAuthKey(key).AccountAddress()
+	if hex.EncodeToString(derived[:]) != normalized {
+		return ErrAddressMismatch
+	}
+
+	if !ed25519.Verify(ed25519.PublicKey(key.Bytes()), message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
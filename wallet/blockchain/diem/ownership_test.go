@@ -0,0 +1,32 @@
+package diem_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/diem/client-sdk-go/diemkeys"
+	"github.com/stevealexrs/Go-Libra/wallet/blockchain/diem"
+)
+
+func TestOwnershipVerifier(t *testing.T) {
+	keys := diemkeys.MustGenKeys()
+	address := keys.AccountAddress()
+	message := []byte("challenge")
+
+	verifier := diem.OwnershipVerifier{}
+	err := verifier.VerifyOwnership(hex.EncodeToString(address[:]), keys.PublicKey.Hex(), message, keys.PrivateKey.Sign(message))
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = verifier.VerifyOwnership(hex.EncodeToString(address[:]), keys.PublicKey.Hex(), []byte("other"), keys.PrivateKey.Sign(message))
+	if err != diem.ErrInvalidSignature {
+		t.Errorf("expect invalid signature, got %v", err)
+	}
+
+	other := diemkeys.MustGenKeys().AccountAddress()
+	err = verifier.VerifyOwnership(hex.EncodeToString(other[:]), keys.PublicKey.Hex(), message, keys.PrivateKey.Sign(message))
+	if err != diem.ErrAddressMismatch {
+		t.Errorf("expect address mismatch, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"math/big"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/diem/client-sdk-go/diemclient"
@@ -51,6 +52,10 @@ func (q *Query) Balance(ctx context.Context, address string) (map[string]*big.In
 	}
 
 	balMap := make(map[string]*big.Int)
+	// the account is nil until the address receives its first transfer
+	if acc == nil {
+		return balMap, nil
+	}
 	for _, v := range acc.Balances {
 		balMap[v.Currency] = new(big.Int).SetUint64(v.Amount)
 	}
@@ -98,11 +103,31 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 	}
 	versions := sortVersions[index:]
 
-	errs, ctx := errgroup.WithContext(ctx)
-	txChannel := make(chan wallet.DiemTransaction)
+	return q.transactions(ctx, versions)
+}
+
+// Transactions fetched at the same time
+const transactionRequests = 16
+
+// Fetch the transactions of the versions, at most transactionRequests at the same time
+func (q *Query) transactions(ctx context.Context, versions []uint64) (map[uint64]wallet.DiemTransaction, error) {
+	lock := sync.Mutex{}
+	txRes := make(map[uint64]wallet.DiemTransaction)
+	requests := make(chan struct{}, transactionRequests)
+	errs, errCtx := errgroup.WithContext(ctx)
 	for _, v := range versions {
+		select {
+		case requests <- struct{}{}:
+		case <-errCtx.Done():
+		}
+		if errCtx.Err() != nil {
+			break
+		}
+
 		version := v
 		errs.Go(func() error {
+			defer func() { <-requests }()
+
 			diemTxs, err := q.Client.GetTransactions(version, 1, false)
 			if err != nil {
 				return err
@@ -124,6 +149,7 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 				Time:   	 time.Unix(int64(diemTx.Transaction.TimestampUsecs), 0),
 				PublicKey: 	 diemTx.Transaction.PublicKey,
 				GasCurrency: diemTx.Transaction.GasCurrency,
+				Metadata:    diemTx.Transaction.Script.Metadata,
 				Transfer: wallet.Transfer{
 					Currency: diemTx.Transaction.Script.Currency,
 					From:     diemTx.Transaction.Sender,
@@ -132,23 +158,89 @@ func (q *Query) TransactionsByVersion(ctx context.Context, address string, start
 				},
 			}
 
-			select {
-			case txChannel <- tx:
-				return nil
-			case <- ctx.Done():
-                return ctx.Err()
-			}
+			lock.Lock()
+			defer lock.Unlock()
+			txRes[tx.Version] = tx
+			return nil
 		})
 	}
 
-	go func ()  {
-		errs.Wait()
-		close(txChannel)
-	}()
+	if err := errs.Wait(); err != nil {
+		return nil, err
+	}
+	// the parent context is cancelled if no request failed
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return txRes, nil
+}
+
+// Diem serves at most 1000 events per request
+const eventPageSize = 1000
 
-	txRes := make(map[uint64]wallet.DiemTransaction)
-	for v := range txChannel {
-		txRes[v.Version] = v
+// At most limit events of the stream from the sequence number
+func (q *Query) eventsFrom(key string, start uint64, limit int) ([]*diemjsonrpctypes.Event, error) {
+	events := make([]*diemjsonrpctypes.Event, 0)
+	for len(events) < limit {
+		size := limit - len(events)
+		if size > eventPageSize {
+			size = eventPageSize
+		}
+		page, err := q.Client.GetEvents(key, start, uint64(size))
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, page...)
+		start += uint64(len(page))
+		if len(page) < size {
+			break
+		}
 	}
-	return txRes, errs.Wait()
-}
\ No newline at end of file
+	return events, nil
+}
+
+func (q *Query) TransactionsByEvent(ctx context.Context, address string, from wallet.DiemEventCursor, limit int) (map[uint64]wallet.DiemTransaction, wallet.DiemEventCursor, error) {
+	acc, err := q.AccountInfo(address)
+	if err != nil {
+		return nil, from, err
+	}
+	// the account has no events until the address receives its first transfer
+	if acc == nil {
+		return make(map[uint64]wallet.DiemTransaction), from, nil
+	}
+
+	sentEvents, err := q.eventsFrom(acc.SentEventsKey, from.Sent, limit)
+	if err != nil {
+		return nil, from, err
+	}
+
+	receivedEvents, err := q.eventsFrom(acc.ReceivedEventsKey, from.Received, limit)
+	if err != nil {
+		return nil, from, err
+	}
+
+	// a transaction can have both a sent and a received event of the account
+	versionsMap := make(map[uint64]struct{})
+	for _, v := range sentEvents {
+		versionsMap[v.TransactionVersion] = struct{}{}
+	}
+	for _, v := range receivedEvents {
+		versionsMap[v.TransactionVersion] = struct{}{}
+	}
+	versions := make([]uint64, 0, len(versionsMap))
+	for k := range versionsMap {
+		versions = append(versions, k)
+	}
+
+	txs, err := q.transactions(ctx, versions)
+	if err != nil {
+		return nil, from, err
+	}
+
+	next := wallet.DiemEventCursor{
+		Sent:     from.Sent + uint64(len(sentEvents)),
+		Received: from.Received + uint64(len(receivedEvents)),
+	}
+	return txs, next, nil
+}
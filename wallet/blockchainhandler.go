@@ -14,6 +14,25 @@ type CeloQuery interface {
 	Balance(ctx context.Context, address string, tokenAddresses ...string) (map[string]*big.Int, error)
 }
 
+// Sequence numbers of the next sent and received events of a Diem account
+type DiemEventCursor struct {
+	Sent     uint64
+	Received uint64
+}
+
+type DiemEventQuery interface {
+	// Transactions of at most limit sent and limit received events from the cursor, returns the cursor after the
+	// last event that is read
+	TransactionsByEvent(ctx context.Context, address string, from DiemEventCursor, limit int) (map[uint64]DiemTransaction, DiemEventCursor, error)
+}
+
+type CeloBlockQuery interface {
+	LatestBlock(ctx context.Context) (uint64, error)
+	// Transactions of at most limit token transfers from the start block up to the end block, returns the last
+	// block whose transfers are all read
+	TransactionsByBlock(ctx context.Context, address string, start, end uint64, limit int) (map[uint64]map[int]CeloTransaction, uint64, error)
+}
+
 type DiemTxQuery interface {
 	TransactionsByVersion(ctx context.Context, address string, start uint64) (map[uint64]DiemTransaction, error)
 }
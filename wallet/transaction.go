@@ -48,6 +48,8 @@ type CeloTransaction struct {
 	GatewayRecipient string
 	GatewayCurrency  string
 	TransferEvents   map[int]Transfer
+	// Comments of the transfers that are made with transferWithComment, keyed the same as the transfer events
+	TransferComments map[int]string
 }
 
 // In diem, a transaction is identified by version number
@@ -59,6 +61,8 @@ type DiemTransaction struct {
 	Time 		time.Time
 	PublicKey   string
 	GasCurrency string
+	// Hex encoded metadata of the payment that is set by the sender, such as the reference of an invoice
+	Metadata    string
 	Transfer
 }
 
@@ -103,8 +103,9 @@ func (r *RefreshingTransactionRepo) FetchCeloByWallet(ctx context.Context, start
 	lock := sync.Mutex{}
 	txsRemote := make(map[uint64]map[int]CeloTransaction)
 	for _, v := range addresses {
+		address := v
 		errs.Go(func() error {
-			txsTemp, err := r.celoBC.TransactionsByVersion(ctx, v, start)
+			txsTemp, err := r.celoBC.TransactionsByVersion(ctx, address, start)
 			if err != nil {
 				return err
 			}
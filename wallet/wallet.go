@@ -8,4 +8,11 @@ type Wallet struct {
 type Address struct {
 	Chain 	  string
 	Hex   string
-}
\ No newline at end of file
+}
+
+// Proves that the owner of an address signed a message
+type OwnershipVerifier interface {
+	// returns the address in the form stored by the transaction repositories
+	NormalizeAddress(address string) (string, error)
+	VerifyOwnership(address, publicKey string, message, signature []byte) error
+}
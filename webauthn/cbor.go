@@ -0,0 +1,174 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Minimal CBOR decoder for attestation objects and COSE keys.
+// Integers are decoded as int64, maps as map[interface{}]interface{}
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+const maxCBORDepth = 16
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+// Decode the first item of data and return the number of bytes read
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.pos, nil
+}
+
+func (d *cborDecoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.data) {
+		return nil, errCBORTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.read(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(b[0]), nil
+	case info == 25:
+		b, err := d.read(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := d.read(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := d.read(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	}
+	return 0, fmt.Errorf("cbor: unsupported additional information %d", info)
+}
+
+func (d *cborDecoder) length(info byte) (int, error) {
+	n, err := d.argument(info)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)) {
+		return 0, errCBORTruncated
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errors.New("cbor: nested too deep")
+	}
+
+	b, err := d.read(1)
+	if err != nil {
+		return nil, err
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+
+	switch major {
+	case 0:
+		n, err := d.argument(info)
+		if err != nil {
+			return nil, err
+		}
+		if n > 1<<63-1 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return int64(n), nil
+	case 1:
+		n, err := d.argument(info)
+		if err != nil {
+			return nil, err
+		}
+		if n > 1<<63-1 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(n), nil
+	case 2, 3:
+		n, err := d.length(info)
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.read(n)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(s), nil
+		}
+		return append([]byte(nil), s...), nil
+	case 4:
+		n, err := d.length(info)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	case 5:
+		n, err := d.length(info)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := 0; i < n; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("cbor: unsupported map key")
+			}
+
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("cbor: unsupported item %#x", b[0])
+}
@@ -0,0 +1,299 @@
+// Server side of the WebAuthn registration and assertion ceremonies for passkeys.
+// Only "none" attestation is accepted since the authenticator model is not checked
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+)
+
+// COSE algorithm identifiers
+const (
+	AlgES256 = -7
+	AlgRS256 = -257
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagUserVerified = 0x04
+	flagAttested     = 0x40
+	challengeSize    = 32
+)
+
+var (
+	ErrClientData  = errors.New("webauthn: invalid client data")
+	ErrAuthData    = errors.New("webauthn: invalid authenticator data")
+	ErrAttestation = errors.New("webauthn: unsupported attestation")
+	ErrPublicKey   = errors.New("webauthn: unsupported public key")
+	ErrSignature   = errors.New("webauthn: invalid signature")
+	ErrSignCount   = errors.New("webauthn: sign count did not increase, the authenticator may be cloned")
+)
+
+var Encoding = base64.RawURLEncoding
+
+type RelyingParty struct {
+	// Domain of the site, e.g. example.com
+	ID   string
+	Name string
+	// Full origin of the page, e.g. https://example.com
+	Origin string
+	// Require biometrics or PIN on top of user presence
+	RequireUserVerification bool
+}
+
+type Credential struct {
+	ID []byte
+	// COSE encoded public key
+	PublicKey []byte
+	SignCount uint32
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIdHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialId []byte
+	publicKey    []byte
+}
+
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, challengeSize)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var c clientData
+	err := json.Unmarshal(raw, &c)
+	if err != nil {
+		return ErrClientData
+	}
+
+	received, err := Encoding.DecodeString(c.Challenge)
+	if err != nil {
+		return ErrClientData
+	}
+
+	if c.Type != ceremony ||
+		subtle.ConstantTimeCompare(received, challenge) != 1 ||
+		c.Origin != rp.Origin {
+		return ErrClientData
+	}
+	return nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrAuthData
+	}
+
+	a := &authenticatorData{
+		rpIdHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if a.flags&flagAttested == 0 {
+		return a, nil
+	}
+
+	// aaguid(16) and credential id length(2)
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, ErrAuthData
+	}
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return nil, ErrAuthData
+	}
+	a.credentialId = rest[:idLen]
+	rest = rest[idLen:]
+
+	_, n, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, ErrAuthData
+	}
+	a.publicKey = rest[:n]
+	return a, nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(a *authenticatorData) error {
+	hash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(a.rpIdHash, hash[:]) {
+		return ErrAuthData
+	}
+
+	if a.flags&flagUserPresent == 0 {
+		return ErrAuthData
+	}
+
+	if rp.RequireUserVerification && a.flags&flagUserVerified == 0 {
+		return ErrAuthData
+	}
+	return nil
+}
+
+// Verify the response of navigator.credentials.create
+func (rp *RelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	err := rp.verifyClientData(clientDataJSON, "webauthn.create", challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, ErrAttestation
+	}
+
+	obj, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrAttestation
+	}
+
+	if format, _ := obj["fmt"].(string); format != "none" {
+		return nil, ErrAttestation
+	}
+
+	rawAuthData, ok := obj["authData"].([]byte)
+	if !ok {
+		return nil, ErrAttestation
+	}
+
+	a, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rp.verifyAuthenticatorData(a)
+	if err != nil {
+		return nil, err
+	}
+
+	if a.credentialId == nil {
+		return nil, ErrAuthData
+	}
+
+	_, err = parsePublicKey(a.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:        append([]byte(nil), a.credentialId...),
+		PublicKey: append([]byte(nil), a.publicKey...),
+		SignCount: a.signCount,
+	}, nil
+}
+
+// Verify the response of navigator.credentials.get, returns the new sign count
+func (rp *RelyingParty) VerifyAssertion(challenge []byte, cred Credential, clientDataJSON, authData, signature []byte) (uint32, error) {
+	err := rp.verifyClientData(clientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	a, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return 0, err
+	}
+
+	err = rp.verifyAuthenticatorData(a)
+	if err != nil {
+		return 0, err
+	}
+
+	key, err := parsePublicKey(cred.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientHash[:]...))
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], signature) {
+			return 0, ErrSignature
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) != nil {
+			return 0, ErrSignature
+		}
+	}
+
+	// authenticators without counter always return 0
+	if (a.signCount != 0 || cred.SignCount != 0) && a.signCount <= cred.SignCount {
+		return 0, ErrSignCount
+	}
+	return a.signCount, nil
+}
+
+func parsePublicKey(cose []byte) (crypto.PublicKey, error) {
+	decoded, _, err := decodeCBOR(cose)
+	if err != nil {
+		return nil, ErrPublicKey
+	}
+
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrPublicKey
+	}
+
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+
+	switch {
+	case kty == 2 && alg == AlgES256:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrPublicKey
+		}
+
+		key := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, ErrPublicKey
+		}
+		return key, nil
+	case kty == 3 && alg == AlgRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrPublicKey
+		}
+
+		exponent := 0
+		for _, v := range e {
+			exponent = exponent<<8 | int(v)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+	}
+	return nil, ErrPublicKey
+}
@@ -0,0 +1,210 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stevealexrs/Go-Libra/webauthn"
+)
+
+// Software authenticator with an ES256 key
+type authenticator struct {
+	key       *ecdsa.PrivateKey
+	id        []byte
+	signCount uint32
+}
+
+func cborHead(major byte, n int) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 256:
+		return []byte{major<<5 | 24, byte(n)}
+	default:
+		return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+	}
+}
+
+func cborInt(n int) []byte {
+	if n < 0 {
+		return cborHead(1, -1-n)
+	}
+	return cborHead(0, n)
+}
+
+func cborBytes(b []byte) []byte {
+	return append(cborHead(2, len(b)), b...)
+}
+
+func cborText(s string) []byte {
+	return append(cborHead(3, len(s)), s...)
+}
+
+func (a *authenticator) coseKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+
+	b := cborHead(5, 5)
+	b = append(b, cborInt(1)...)
+	b = append(b, cborInt(2)...)
+	b = append(b, cborInt(3)...)
+	b = append(b, cborInt(webauthn.AlgES256)...)
+	b = append(b, cborInt(-1)...)
+	b = append(b, cborInt(1)...)
+	b = append(b, cborInt(-2)...)
+	b = append(b, cborBytes(x)...)
+	b = append(b, cborInt(-3)...)
+	b = append(b, cborBytes(y)...)
+	return b
+}
+
+func (a *authenticator) authData(rpId string, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpId))
+	data := append([]byte(nil), hash[:]...)
+
+	flags := byte(0x01 | 0x04)
+	if attested {
+		flags |= 0x40
+	}
+	data = append(data, flags)
+
+	count := make([]byte, 4)
+	binary.BigEndian.PutUint32(count, a.signCount)
+	data = append(data, count...)
+
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = append(data, byte(len(a.id)>>8), byte(len(a.id)))
+		data = append(data, a.id...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func clientDataJSON(ceremony string, challenge []byte, origin string) []byte {
+	b, _ := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": webauthn.Encoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	return b
+}
+
+func (a *authenticator) create(rp *webauthn.RelyingParty, challenge []byte) ([]byte, []byte) {
+	obj := cborHead(5, 3)
+	obj = append(obj, cborText("fmt")...)
+	obj = append(obj, cborText("none")...)
+	obj = append(obj, cborText("attStmt")...)
+	obj = append(obj, cborHead(5, 0)...)
+	obj = append(obj, cborText("authData")...)
+	obj = append(obj, cborBytes(a.authData(rp.ID, true))...)
+
+	return clientDataJSON("webauthn.create", challenge, rp.Origin), obj
+}
+
+func (a *authenticator) get(rp *webauthn.RelyingParty, challenge []byte) ([]byte, []byte, []byte, error) {
+	a.signCount++
+	client := clientDataJSON("webauthn.get", challenge, rp.Origin)
+	authData := a.authData(rp.ID, false)
+
+	clientHash := sha256.Sum256(client)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	return client, authData, signature, err
+}
+
+func newAuthenticator(t *testing.T) *authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &authenticator{key: key, id: []byte("credential-id")}
+}
+
+var rp = &webauthn.RelyingParty{
+	ID:                      "example.com",
+	Name:                    "Example",
+	Origin:                  "https://example.com",
+	RequireUserVerification: true,
+}
+
+func TestCeremonies(t *testing.T) {
+	a := newAuthenticator(t)
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, obj := a.create(rp, challenge)
+	cred, err := rp.VerifyRegistration(challenge, client, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(cred.ID) != string(a.id) {
+		t.Errorf("expect credential id %s, got %s", a.id, cred.ID)
+	}
+
+	challenge, _ = webauthn.NewChallenge()
+	client, authData, signature, err := a.get(rp, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := rp.VerifyAssertion(challenge, *cred, client, authData, signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expect sign count 1, got %v", count)
+	}
+	cred.SignCount = count
+
+	// replay of the same assertion does not increase the counter
+	_, err = rp.VerifyAssertion(challenge, *cred, client, authData, signature)
+	if !errors.Is(err, webauthn.ErrSignCount) {
+		t.Errorf("expect sign count error, got %v", err)
+	}
+}
+
+func TestAssertionRejected(t *testing.T) {
+	a := newAuthenticator(t)
+
+	challenge, _ := webauthn.NewChallenge()
+	client, obj := a.create(rp, challenge)
+	cred, err := rp.VerifyRegistration(challenge, client, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := webauthn.NewChallenge()
+	client, authData, signature, err := a.get(rp, challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rp.VerifyAssertion(other, *cred, client, authData, signature); !errors.Is(err, webauthn.ErrClientData) {
+		t.Errorf("expect challenge mismatch, got %v", err)
+	}
+
+	signature[len(signature)-1] ^= 1
+	if _, err := rp.VerifyAssertion(challenge, *cred, client, authData, signature); !errors.Is(err, webauthn.ErrSignature) {
+		t.Errorf("expect signature error, got %v", err)
+	}
+
+	evil := &webauthn.RelyingParty{ID: "evil.com", Origin: "https://example.com"}
+	client, authData, signature, _ = a.get(evil, challenge)
+	if _, err := rp.VerifyAssertion(challenge, *cred, client, authData, signature); !errors.Is(err, webauthn.ErrAuthData) {
+		t.Errorf("expect rp id mismatch, got %v", err)
+	}
+}